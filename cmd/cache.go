@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/tabwriter"
 
 	"npm-console/internal/services"
+	"npm-console/pkg/config"
 	"npm-console/pkg/logger"
 
 	"github.com/spf13/cobra"
@@ -21,6 +23,11 @@ var cacheCmd = &cobra.Command{
 This command provides functionality to:
 - List cache information for all package managers
 - Clean caches for specific or all package managers
+- Prune caches down to a size and age budget
+- Browse and evict individual npm cache entries
+- Verify cached content against its recorded hashes
+- Find and remove package versions cached by several managers
+- Export and import offline cache bundles for air-gapped machines
 - Show cache statistics and summaries`,
 }
 
@@ -62,17 +69,183 @@ var cacheSizeCmd = &cobra.Command{
 	RunE:  runCacheSize,
 }
 
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune [manager]",
+	Short: "Evict old cache entries until caches fit a size and age budget",
+	Long: `Evict cache entries older than a max age, then the least recently written
+ones until each manager's cache fits a max size. Unlike clean, entries
+within the budget are kept.
+
+--policy takes the limits from the cache section of the config file
+(max_size, max_age); --max-size and --max-age set or override them. With
+cache.auto_clean enabled the web server also prunes every scan_interval.
+
+Examples:
+  npm-console cache prune --policy                # Enforce the configured policy
+  npm-console cache prune npm --max-size 5GB      # Keep the npm cache under 5GB
+  npm-console cache prune --max-age 30d --dry-run # Show what would be evicted`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCachePrune,
+}
+
+var cacheEntriesCmd = &cobra.Command{
+	Use:   "entries [manager]",
+	Short: "List the entries of a manager's cache index",
+	Long: `List the packuments and tarballs in a manager's cache index with their
+key, size, integrity and time. Only npm's cacache is supported; the manager
+defaults to npm.
+
+Examples:
+  npm-console cache entries                 # List every npm cache entry
+  npm-console cache entries --search lodash # Entries whose key or package matches`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCacheEntries,
+}
+
+var cacheEvictCmd = &cobra.Command{
+	Use:   "evict [manager]",
+	Short: "Remove individual entries from a manager's cache",
+	Long: `Remove one cache entry by key, or every cached packument and tarball of a
+package, without wiping the whole cache. Content no remaining entry refers
+to is deleted. The manager defaults to npm.
+
+Examples:
+  npm-console cache evict --package lodash        # Evict everything cached for lodash
+  npm-console cache evict --package @babel/core -f
+  npm-console cache evict --key "make-fetch-happen:request-cache:https://registry.npmjs.org/lodash"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCacheEvict,
+}
+
+var cacheVerifyCmd = &cobra.Command{
+	Use:   "verify [manager]",
+	Short: "Check cached content against its recorded integrity hashes",
+	Long: `Check cached content against the hashes the package manager recorded for
+it: npm's cacache SRI entries, the sha512 names of pnpm's store files and the
+CRC-32 checksums of yarn's cache zips. Corrupt content, index entries whose
+content is missing and content nothing refers to are reported.
+
+--repair deletes the bad entries so the next install fetches them again. The
+command exits with an error when issues are left unrepaired.
+
+Examples:
+  npm-console cache verify            # Verify every supported cache
+  npm-console cache verify pnpm       # Verify only the pnpm store
+  npm-console cache verify --repair   # Delete corrupt and orphaned entries`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCacheVerify,
+}
+
+var cacheDuplicatesCmd = &cobra.Command{
+	Use:   "duplicates",
+	Short: "Find package versions cached by more than one manager",
+	Long: `List the package versions cached by more than one package manager and the
+space keeping a single copy would reclaim. SAME marks copies made from the
+same tarball, by the integrity the managers recorded.
+
+Examples:
+  npm-console cache duplicates             # Reclaimable when keeping the largest copy
+  npm-console cache duplicates --keep pnpm # Reclaimable when keeping pnpm's copies`,
+	Args: cobra.NoArgs,
+	RunE: runCacheDuplicates,
+}
+
+var cacheDedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Remove other managers' copies of package versions one manager caches",
+	Long: `For every package version the --keep manager has cached, remove the copies
+in the other managers' caches. Package versions the kept manager does not
+hold are left alone, as are those whose copies do not all record the same
+tarball integrity unless --mismatched is given.
+
+Examples:
+  npm-console cache dedupe --keep pnpm --dry-run # Show what would be removed
+  npm-console cache dedupe --keep npm -f         # Keep npm's copies without asking`,
+	Args: cobra.NoArgs,
+	RunE: runCacheDedupe,
+}
+
+var cacheExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Bundle the tarballs a project's lockfile needs for offline installs",
+	Long: `Gather every registry tarball a project's lockfile needs from the local
+caches into a portable archive with a manifest. Tarballs are taken from npm's
+cache and from yarn 1 caches that keep them, and checked against the
+lockfile's integrity. Packages no cache holds are listed as missing; an
+online install with npm fills the npm cache for a complete bundle.
+
+Examples:
+  npm-console cache export --project .                  # Writes <project>-cache.tgz
+  npm-console cache export --project ./app -o deps.tgz  # Choose the archive path`,
+	Args: cobra.NoArgs,
+	RunE: runCacheExport,
+}
+
+var cacheImportCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Seed a manager's cache from an offline cache bundle",
+	Long: `Add the tarballs of a bundle written by cache export to a package manager,
+so that installing the project offline finds them:
+
+  npm    writes them to npm's cache         (npm ci --offline)
+  pnpm   adds them with pnpm store add      (pnpm install --offline)
+  yarn   copies them to the yarn 1 offline mirror, --dir or yarn-offline-mirror
+         (yarn install --offline)
+
+Examples:
+  npm-console cache import deps.tgz --manager npm
+  npm-console cache import deps.tgz --manager yarn --dir ./npm-packages-offline-cache`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCacheImport,
+}
+
 func init() {
 	rootCmd.AddCommand(cacheCmd)
 	cacheCmd.AddCommand(cacheListCmd)
 	cacheCmd.AddCommand(cacheCleanCmd)
 	cacheCmd.AddCommand(cacheInfoCmd)
 	cacheCmd.AddCommand(cacheSizeCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheEntriesCmd)
+	cacheCmd.AddCommand(cacheEvictCmd)
+	cacheCmd.AddCommand(cacheVerifyCmd)
+	cacheCmd.AddCommand(cacheDuplicatesCmd)
+	cacheCmd.AddCommand(cacheDedupeCmd)
+	cacheCmd.AddCommand(cacheExportCmd)
+	cacheCmd.AddCommand(cacheImportCmd)
 
 	// Add flags
 	cacheCleanCmd.Flags().BoolP("force", "f", false, "Force clean without confirmation")
 	cacheListCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
 	cacheInfoCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	cachePruneCmd.Flags().Bool("policy", false, "Use the cache limits from the config file")
+	cachePruneCmd.Flags().String("max-size", "", "Max size of each manager's cache, e.g. 5GB")
+	cachePruneCmd.Flags().String("max-age", "", "Max age of cache entries, e.g. 30d")
+	cachePruneCmd.Flags().Bool("dry-run", false, "Show what would be evicted without removing anything")
+	cachePruneCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	cacheEntriesCmd.Flags().StringP("search", "s", "", "Only list entries whose key or package contains this text")
+	cacheEntriesCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	cacheEvictCmd.Flags().String("key", "", "Cache key of the entry to evict")
+	cacheEvictCmd.Flags().String("package", "", "Evict every entry of this package")
+	cacheEvictCmd.Flags().BoolP("force", "f", false, "Evict without confirmation")
+	cacheEvictCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	cacheVerifyCmd.Flags().Bool("repair", false, "Delete corrupt, missing and orphaned entries")
+	cacheVerifyCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	cacheDuplicatesCmd.Flags().String("keep", "", "Manager whose copies would be kept")
+	cacheDuplicatesCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	cacheDedupeCmd.Flags().String("keep", "", "Manager whose copies to keep (required)")
+	cacheDedupeCmd.Flags().Bool("mismatched", false, "Also remove copies whose tarball integrity differs from the kept copy or is unknown")
+	cacheDedupeCmd.Flags().Bool("dry-run", false, "Show what would be removed without removing anything")
+	cacheDedupeCmd.Flags().BoolP("force", "f", false, "Dedupe without confirmation")
+	cacheDedupeCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	cacheDedupeCmd.MarkFlagRequired("keep")
+	cacheExportCmd.Flags().String("project", ".", "Project whose lockfile lists the packages")
+	cacheExportCmd.Flags().StringP("output", "o", "", "Archive to write (default <project>-cache.tgz)")
+	cacheExportCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	cacheImportCmd.Flags().StringP("manager", "m", "", "Package manager to seed (required)")
+	cacheImportCmd.Flags().String("dir", "", "Offline mirror directory for yarn 1")
+	cacheImportCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	cacheImportCmd.MarkFlagRequired("manager")
 }
 
 func runCacheList(cmd *cobra.Command, args []string) error {
@@ -173,6 +346,424 @@ func runCacheClean(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	cacheService := services.NewCacheService()
+
+	usePolicy, _ := cmd.Flags().GetBool("policy")
+	maxSize, _ := cmd.Flags().GetString("max-size")
+	maxAge, _ := cmd.Flags().GetString("max-age")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	var limits config.CacheConfig
+	if usePolicy {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		limits = cfg.Cache
+	}
+	if maxSize != "" {
+		limits.MaxSize = maxSize
+	}
+	if maxAge != "" {
+		limits.MaxAge = maxAge
+	}
+
+	policy, err := services.ParseCachePolicy(limits)
+	if err != nil {
+		return err
+	}
+	if policy.IsEmpty() {
+		return fmt.Errorf("no cache limits: use --policy, --max-size or --max-age")
+	}
+
+	managerName := ""
+	if len(args) > 0 {
+		managerName = args[0]
+	}
+
+	results, err := cacheService.PruneCache(ctx, managerName, policy, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to prune caches: %w", err)
+	}
+
+	if jsonOutput {
+		return outputJSON(results)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MANAGER\tBEFORE\tAFTER\tEVICTED\tEXPIRED\tFREED")
+	fmt.Fprintln(w, "-------\t------\t-----\t-------\t-------\t-----")
+
+	var freed int64
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n",
+			result.Manager,
+			formatSize(result.SizeBefore),
+			formatSize(result.SizeAfter),
+			result.Removed,
+			result.Expired,
+			formatSize(result.BytesFreed),
+		)
+		freed += result.BytesFreed
+	}
+	w.Flush()
+
+	if dryRun {
+		fmt.Printf("\n📋 Dry run: %s would be freed\n", formatSize(freed))
+	} else {
+		fmt.Printf("\n✅ Freed %s\n", formatSize(freed))
+	}
+	return nil
+}
+
+func runCacheEntries(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	cacheService := services.NewCacheService()
+
+	search, _ := cmd.Flags().GetString("search")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	managerName := "npm"
+	if len(args) > 0 {
+		managerName = args[0]
+	}
+
+	entries, err := cacheService.ListCacheEntries(ctx, managerName, search)
+	if err != nil {
+		return fmt.Errorf("failed to list cache entries: %w", err)
+	}
+
+	if jsonOutput {
+		return outputJSON(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No cache entries found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tVERSION\tKIND\tSIZE\tTIME\tINTEGRITY")
+	fmt.Fprintln(w, "-------\t-------\t----\t----\t----\t---------")
+
+	var total int64
+	for _, entry := range entries {
+		name := entry.Package
+		if name == "" {
+			name = entry.Key
+		}
+		integrity := entry.Integrity
+		if len(integrity) > 24 {
+			integrity = integrity[:21] + "..."
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			name,
+			orNone(entry.Version),
+			entry.Kind,
+			formatSize(entry.Size),
+			entry.Time.Format("2006-01-02 15:04"),
+			integrity,
+		)
+		total += entry.Size
+	}
+	w.Flush()
+
+	fmt.Printf("\n📋 %d entries, %s\n", len(entries), formatSize(total))
+	return nil
+}
+
+func runCacheEvict(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	cacheService := services.NewCacheService()
+
+	key, _ := cmd.Flags().GetString("key")
+	packageName, _ := cmd.Flags().GetString("package")
+	force, _ := cmd.Flags().GetBool("force")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	if (key == "") == (packageName == "") {
+		return fmt.Errorf("use either --key or --package")
+	}
+
+	managerName := "npm"
+	if len(args) > 0 {
+		managerName = args[0]
+	}
+
+	if !force {
+		target := key
+		if packageName != "" {
+			target = "every entry of " + packageName
+		}
+		fmt.Printf("This will evict %s from the %s cache. Continue? (y/N): ", target, managerName)
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Cache eviction cancelled.")
+			return nil
+		}
+	}
+
+	result, err := cacheService.EvictCacheEntries(ctx, managerName, key, packageName)
+	if err != nil {
+		return fmt.Errorf("failed to evict cache entries: %w", err)
+	}
+
+	if jsonOutput {
+		return outputJSON(result)
+	}
+
+	for _, evicted := range result.Keys {
+		fmt.Printf("  - %s\n", evicted)
+	}
+	fmt.Printf("\n✅ Evicted %d entries, removed %d content files (%s freed)\n",
+		len(result.Keys), result.ContentRemoved, formatSize(result.BytesFreed))
+	return nil
+}
+
+func runCacheVerify(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	cacheService := services.NewCacheService()
+
+	repair, _ := cmd.Flags().GetBool("repair")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	managerName := ""
+	if len(args) > 0 {
+		managerName = args[0]
+	}
+
+	results, err := cacheService.VerifyCache(ctx, managerName, repair)
+	if err != nil {
+		return fmt.Errorf("failed to verify caches: %w", err)
+	}
+
+	unrepaired := 0
+	for _, result := range results {
+		unrepaired += len(result.Issues) - result.Repaired
+	}
+
+	if jsonOutput {
+		if err := outputJSON(results); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MANAGER\tCHECKED\tISSUES\tREPAIRED\tFREED")
+		fmt.Fprintln(w, "-------\t-------\t------\t--------\t-----")
+		for _, result := range results {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n",
+				result.Manager,
+				result.Checked,
+				len(result.Issues),
+				result.Repaired,
+				formatSize(result.BytesFreed),
+			)
+		}
+		w.Flush()
+
+		for _, result := range results {
+			if len(result.Issues) == 0 {
+				continue
+			}
+			fmt.Printf("\n%s:\n", result.Manager)
+			for _, issue := range result.Issues {
+				fmt.Printf("  ❌ %-8s %s\n", issue.Kind, issue.Path)
+				if issue.Key != "" {
+					fmt.Printf("     key: %s\n", issue.Key)
+				}
+				fmt.Printf("     %s\n", issue.Detail)
+			}
+		}
+		fmt.Println()
+
+		switch {
+		case unrepaired > 0:
+			fmt.Println("⚠️  Run with --repair to delete the bad entries")
+		case repair:
+			fmt.Println("✅ Caches repaired")
+		default:
+			fmt.Println("✅ No issues found")
+		}
+	}
+
+	if unrepaired > 0 {
+		return fmt.Errorf("%d cache issues found", unrepaired)
+	}
+	return nil
+}
+
+func runCacheDuplicates(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	cacheService := services.NewCacheService()
+
+	keep, _ := cmd.Flags().GetString("keep")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	report, err := cacheService.FindCacheDuplicates(ctx, keep)
+	if err != nil {
+		return fmt.Errorf("failed to find duplicate cache entries: %w", err)
+	}
+
+	if jsonOutput {
+		return outputJSON(report)
+	}
+
+	if len(report.Duplicates) == 0 {
+		fmt.Println("No package versions are cached by more than one manager.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tVERSION\tMANAGERS\tSAME\tRECLAIMABLE")
+	fmt.Fprintln(w, "-------\t-------\t--------\t----\t-----------")
+	for _, duplicate := range report.Duplicates {
+		var held []string
+		for _, pkg := range duplicate.Copies {
+			held = append(held, fmt.Sprintf("%s (%s)", pkg.Manager, formatSize(pkg.Size)))
+		}
+		same := "no"
+		if duplicate.SameContent {
+			same = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			duplicate.Name,
+			duplicate.Version,
+			strings.Join(held, ", "),
+			same,
+			formatSize(duplicate.Reclaimable),
+		)
+	}
+	w.Flush()
+
+	fmt.Printf("\n📋 %d duplicated package versions, %s reclaimable\n", len(report.Duplicates), formatSize(report.Reclaimable))
+	if keep == "" {
+		fmt.Println("Run 'npm-console cache dedupe --keep <manager>' to keep one manager's copies")
+	}
+	return nil
+}
+
+func runCacheDedupe(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	cacheService := services.NewCacheService()
+
+	keep, _ := cmd.Flags().GetString("keep")
+	mismatched, _ := cmd.Flags().GetBool("mismatched")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	if !dryRun && !force {
+		fmt.Printf("This will remove other managers' copies of the package versions %s has cached. Continue? (y/N): ", keep)
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Cache dedupe cancelled.")
+			return nil
+		}
+	}
+
+	result, err := cacheService.DedupeCaches(ctx, keep, mismatched, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to dedupe caches: %w", err)
+	}
+
+	if jsonOutput {
+		return outputJSON(result)
+	}
+
+	for _, pkg := range result.Removed {
+		fmt.Printf("  - %s@%s from %s (%s)\n", pkg.Name, pkg.Version, pkg.Manager, formatSize(pkg.Size))
+	}
+
+	if dryRun {
+		fmt.Printf("\n📋 Dry run: %d copies, %s would be freed\n", len(result.Removed), formatSize(result.BytesFreed))
+	} else {
+		fmt.Printf("\n✅ Removed %d copies, freed %s\n", len(result.Removed), formatSize(result.BytesFreed))
+	}
+
+	if len(result.Skipped) > 0 {
+		fmt.Printf("\n⚠️  Skipped %d package versions whose copies may differ:\n", len(result.Skipped))
+		for _, duplicate := range result.Skipped {
+			fmt.Printf("  - %s@%s\n", duplicate.Name, duplicate.Version)
+		}
+		fmt.Println("Pass --mismatched to remove them as well")
+	}
+	return nil
+}
+
+func runCacheExport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	cacheService := services.NewCacheService()
+
+	project, _ := cmd.Flags().GetString("project")
+	output, _ := cmd.Flags().GetString("output")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	projectPath, err := filepath.Abs(project)
+	if err != nil {
+		return fmt.Errorf("invalid project path: %w", err)
+	}
+	if output == "" {
+		output = filepath.Base(projectPath) + "-cache.tgz"
+	}
+
+	result, err := cacheService.ExportCacheBundle(ctx, projectPath, output)
+	if err != nil {
+		return fmt.Errorf("failed to export cache bundle: %w", err)
+	}
+
+	if jsonOutput {
+		return outputJSON(result)
+	}
+
+	manifest := result.Manifest
+	fmt.Printf("✅ Bundled %d packages from %s into %s (%s)\n",
+		len(manifest.Packages), manifest.Lockfile, result.Archive, formatSize(result.Size))
+
+	if len(manifest.Missing) > 0 {
+		fmt.Printf("\n⚠️  %d packages were not found in any local cache:\n", len(manifest.Missing))
+		for _, pkg := range manifest.Missing {
+			fmt.Printf("  - %s@%s\n", pkg.Name, pkg.Version)
+		}
+		fmt.Println("\nRun an online npm install of the project to cache them, then export again.")
+	}
+	return nil
+}
+
+func runCacheImport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	cacheService := services.NewCacheService()
+
+	managerName, _ := cmd.Flags().GetString("manager")
+	dir, _ := cmd.Flags().GetString("dir")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	result, err := cacheService.ImportCacheBundle(ctx, args[0], managerName, dir)
+	if err != nil {
+		return fmt.Errorf("failed to import cache bundle: %w", err)
+	}
+
+	if jsonOutput {
+		if err := outputJSON(result); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("✅ Imported %d tarballs into %s (%d already present)\n", result.Imported, orNone(result.Target), result.Skipped)
+		for _, failure := range result.Failed {
+			fmt.Printf("  ❌ %s\n", failure)
+		}
+	}
+
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d tarballs could not be imported", len(result.Failed))
+	}
+	return nil
+}
+
 func runCacheInfo(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 	cacheService := services.NewCacheService()
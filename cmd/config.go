@@ -0,0 +1,489 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"npm-console/internal/core"
+	"npm-console/internal/services"
+	"npm-console/pkg/config"
+	"npm-console/pkg/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect package manager configuration",
+	Long: `Inspect the configuration of npm, pnpm, yarn, and bun package managers.
+
+This command provides functionality to:
+- Explain where a config value comes from and which values it overrides
+- List the config snapshots taken before every change
+- Compare snapshots and roll back to one
+- Apply a shared team config file to every package manager
+- Check that the package managers match the npm-console config`,
+	Aliases: []string{"cfg"},
+}
+
+var configExplainCmd = &cobra.Command{
+	Use:   "explain <key> [manager]",
+	Short: "Show where a config value comes from",
+	Long: `Show the effective value of a config key and the layer it comes from, along
+with every lower precedence value it shadows. Layers, from highest to lowest
+precedence, are: command line, environment variables, project, user and
+global config files, and builtin defaults.
+
+Keys use npm's names for every manager (registry, proxy, https-proxy,
+@scope:registry, ...); yarn 2+ and bun settings are mapped to them. Tokens are
+masked.
+
+Examples:
+  npm-console config explain registry                           # All managers
+  npm-console config explain registry npm                       # npm only
+  npm-console config explain registry --project ./web           # For a project
+  npm-console config explain registry npm --cli registry=https://mirror.local/`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runConfigExplain,
+}
+
+var configHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List config snapshots",
+	Long: `List the snapshots of every package manager's user config files. A snapshot
+is taken automatically before npm-console changes any config, and before a
+rollback.
+
+Examples:
+  npm-console config history             # Newest first
+  npm-console config history --json      # Output in JSON format`,
+	Args: cobra.NoArgs,
+	RunE: runConfigHistory,
+}
+
+var configDiffCmd = &cobra.Command{
+	Use:   "diff <from> [to]",
+	Short: "Compare two config snapshots",
+	Long: `Show the config keys that differ between two snapshots. Either snapshot may be
+"current" for the config files as they are now; the second defaults to it.
+Tokens are masked.
+
+Examples:
+  npm-console config diff 20260102-150405                    # Snapshot vs. now
+  npm-console config diff 20260102-150405 20260103-091500    # Two snapshots`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runConfigDiff,
+}
+
+var configRollbackCmd = &cobra.Command{
+	Use:   "rollback <id>",
+	Short: "Restore the config files of a snapshot",
+	Long: `Restore every package manager's user config files to a snapshot. Files that
+did not exist when the snapshot was taken are removed. The current files are
+snapshotted first, so a rollback can itself be rolled back.
+
+Examples:
+  npm-console config rollback 20260102-150405
+  npm-console config rollback 20260102-150405 --force    # Skip confirmation`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigRollback,
+}
+
+var configApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply a team config file to every package manager",
+	Long: `Converge every installed package manager to the registry, scoped registries,
+proxy and settings described in a team config file, usually committed to the
+repository as npm-console.team.yaml:
+
+  managers:
+    npm:
+      registry: https://npm.corp.local/
+      scopes:
+        "@corp": https://npm.corp.local/
+      settings:
+        strict-ssl: "true"
+    yarn:
+      registry: https://npm.corp.local/
+
+The changes are shown before anything is written. Fields that are left out
+are not changed, an empty setting removes the key, and auth tokens are
+rejected. The config is snapshotted first and restored if a change fails.
+
+Examples:
+  npm-console config apply                                # Preview, then confirm
+  npm-console config apply --dry-run                      # Only show the plan
+  npm-console config apply -f team.yaml --force           # Apply without confirmation`,
+	Args: cobra.NoArgs,
+	RunE: runConfigApply,
+}
+
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the package managers against the npm-console config",
+	Long: `Compare the registry, proxy, scoped registries and settings stored for each
+enabled manager in the npm-console config (the "managers" section) with what
+the package manager actually uses, and report every key that drifted. The
+command fails when drift is found, so it can be used in scripts and CI.
+
+With --fix the drifted keys are set back to the stored values; the config is
+snapshotted first.
+
+Examples:
+  npm-console config check                 # Report drift
+  npm-console config check --fix           # Report, then fix after confirmation
+  npm-console config check --fix --force   # Fix without confirmation`,
+	Args: cobra.NoArgs,
+	RunE: runConfigCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configExplainCmd)
+	configCmd.AddCommand(configHistoryCmd)
+	configCmd.AddCommand(configDiffCmd)
+	configCmd.AddCommand(configRollbackCmd)
+	configCmd.AddCommand(configApplyCmd)
+	configCmd.AddCommand(configCheckCmd)
+
+	configExplainCmd.Flags().StringP("project", "p", ".", "Project directory whose config files apply")
+	configExplainCmd.Flags().StringArray("cli", nil, "Command line value to include, as key=value")
+	configExplainCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	configHistoryCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	configDiffCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	configRollbackCmd.Flags().BoolP("force", "f", false, "Roll back without confirmation")
+	configApplyCmd.Flags().StringP("file", "f", config.DefaultTeamFile, "Team config file to apply")
+	configApplyCmd.Flags().Bool("dry-run", false, "Show the changes without applying them")
+	configApplyCmd.Flags().Bool("force", false, "Apply without confirmation")
+	configApplyCmd.Flags().BoolP("json", "j", false, "Output the plan in JSON format")
+	configCheckCmd.Flags().Bool("fix", false, "Set drifted keys back to the stored values")
+	configCheckCmd.Flags().Bool("force", false, "Fix without confirmation")
+	configCheckCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+}
+
+func runConfigExplain(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+
+	key := args[0]
+	managerName := ""
+	if len(args) > 1 {
+		managerName = args[1]
+	}
+	projectPath, _ := cmd.Flags().GetString("project")
+
+	cliValues, _ := cmd.Flags().GetStringArray("cli")
+	cli := make(map[string]string)
+	for _, value := range cliValues {
+		k, v, ok := strings.Cut(value, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("invalid --cli value %q, expected key=value", value)
+		}
+		cli[strings.TrimPrefix(k, "--")] = v
+	}
+
+	logger.GetDefault().Debug("Explaining config", "key", key, "manager", managerName, "project", projectPath)
+
+	entries, err := configService.ExplainConfig(ctx, managerName, key, projectPath, cli)
+	if err != nil {
+		return fmt.Errorf("failed to explain %s: %w", key, err)
+	}
+
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	if jsonOutput {
+		return outputJSON(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("%s is not set for any package manager.\n", key)
+		return nil
+	}
+
+	for i, entry := range entries {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("📋 %s: %s = %s\n", entry.Manager, entry.Key, entry.Value)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  LAYER\tSOURCE\tVALUE\tSTATUS")
+		fmt.Fprintln(w, "  -----\t------\t-----\t------")
+		sources := append([]core.ConfigSource{{Layer: entry.Layer, Source: entry.Source, Value: entry.Value}}, entry.Shadowed...)
+		for j, source := range sources {
+			status := "shadowed"
+			if j == 0 {
+				status = "effective"
+			}
+			origin := source.Source
+			if origin == "" {
+				origin = "-"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", source.Layer, origin, source.Value, status)
+		}
+		w.Flush()
+	}
+
+	return nil
+}
+
+func runConfigHistory(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+
+	snapshots, err := configService.ConfigHistory(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list config snapshots: %w", err)
+	}
+
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	if jsonOutput {
+		return outputJSON(snapshots)
+	}
+
+	if len(snapshots) == 0 {
+		fmt.Println("No config snapshots yet.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCREATED\tFILES\tREASON")
+	fmt.Fprintln(w, "--\t-------\t-----\t------")
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n",
+			snapshot.ID,
+			snapshot.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			len(snapshot.Files),
+			snapshot.Reason,
+		)
+	}
+	return w.Flush()
+}
+
+func runConfigDiff(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+
+	from, to := args[0], services.CurrentSnapshot
+	if len(args) > 1 {
+		to = args[1]
+	}
+
+	diff, err := configService.DiffConfig(ctx, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to compare %s with %s: %w", from, to, err)
+	}
+
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	if jsonOutput {
+		return outputJSON(diff)
+	}
+
+	if len(diff.Changes) == 0 {
+		fmt.Printf("No config changes between %s and %s.\n", diff.From, diff.To)
+		return nil
+	}
+
+	fmt.Printf("📋 Config changes from %s to %s:\n", diff.From, diff.To)
+	path := ""
+	for _, change := range diff.Changes {
+		if change.Path != path {
+			path = change.Path
+			fmt.Printf("\n%s\n", path)
+		}
+		switch {
+		case change.Before == "":
+			fmt.Printf("  + %s = %s\n", change.Key, change.After)
+		case change.After == "":
+			fmt.Printf("  - %s = %s\n", change.Key, change.Before)
+		default:
+			fmt.Printf("  ~ %s: %s -> %s\n", change.Key, change.Before, change.After)
+		}
+	}
+	return nil
+}
+
+func runConfigRollback(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+
+	id := args[0]
+	force, _ := cmd.Flags().GetBool("force")
+
+	if !force {
+		fmt.Printf("This will restore all package manager config files to snapshot %s. Continue? (y/N): ", id)
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Rollback cancelled.")
+			return nil
+		}
+	}
+
+	result, err := configService.RollbackConfig(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to roll back to %s: %w", id, err)
+	}
+
+	if len(result.Restored) == 0 {
+		fmt.Printf("✅ Config already matches snapshot %s\n", id)
+		return nil
+	}
+
+	for _, path := range result.Restored {
+		fmt.Printf("  restored %s\n", path)
+	}
+	fmt.Printf("✅ Config rolled back to %s (undo with: npm-console config rollback %s)\n", id, result.Backup)
+	return nil
+}
+
+func runConfigApply(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+
+	path, _ := cmd.Flags().GetString("file")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	team, err := config.LoadTeamConfig(path)
+	if err != nil {
+		return err
+	}
+
+	plan, err := configService.PlanConfig(ctx, &team.Managers)
+	if err != nil {
+		return fmt.Errorf("failed to plan %s: %w", path, err)
+	}
+
+	if jsonOutput && (dryRun || len(plan.Changes) == 0) {
+		return outputJSON(plan)
+	}
+	if !jsonOutput {
+		printConfigPlan(plan)
+	}
+	if dryRun || len(plan.Changes) == 0 {
+		return nil
+	}
+
+	if !force {
+		fmt.Printf("\nApply %d change(s)? (y/N): ", len(plan.Changes))
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Apply cancelled.")
+			return nil
+		}
+	}
+
+	applied, err := configService.ApplyConfig(ctx, &team.Managers)
+	if err != nil {
+		return fmt.Errorf("failed to apply %s: %w", path, err)
+	}
+
+	if jsonOutput {
+		return outputJSON(applied)
+	}
+	fmt.Printf("✅ Applied %d change(s) from %s (undo with: npm-console config rollback %s)\n", len(applied.Changes), path, applied.Snapshot)
+	return nil
+}
+
+func runConfigCheck(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+
+	fix, _ := cmd.Flags().GetBool("fix")
+	force, _ := cmd.Flags().GetBool("force")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	drift, err := configService.CheckDrift(ctx, &cfg.Managers)
+	if err != nil {
+		return fmt.Errorf("failed to check config drift: %w", err)
+	}
+
+	if jsonOutput {
+		if err := outputJSON(drift); err != nil {
+			return err
+		}
+	} else {
+		for _, name := range drift.Skipped {
+			fmt.Printf("⚠️  %s is not available, skipped\n", name)
+		}
+		if drift.InSync {
+			fmt.Println("✅ All package managers match the npm-console config.")
+			return nil
+		}
+
+		fmt.Printf("❌ %d config value(s) drifted from %s:\n", len(drift.Drift), cfg.Path())
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MANAGER\tKEY\tACTUAL\tEXPECTED")
+		fmt.Fprintln(w, "-------\t---\t------\t--------")
+		for _, change := range drift.Drift {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Manager, change.Key, orNone(change.Current), orNone(change.Desired))
+		}
+		w.Flush()
+	}
+
+	if drift.InSync {
+		return nil
+	}
+	if !fix {
+		return fmt.Errorf("%d config value(s) drifted", len(drift.Drift))
+	}
+
+	if !force {
+		fmt.Printf("\nFix %d config value(s)? (y/N): ", len(drift.Drift))
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Fix cancelled.")
+			return fmt.Errorf("%d config value(s) drifted", len(drift.Drift))
+		}
+	}
+
+	plan, err := configService.FixDrift(ctx, &cfg.Managers)
+	if err != nil {
+		return fmt.Errorf("failed to fix config drift: %w", err)
+	}
+
+	if !jsonOutput {
+		fmt.Printf("✅ Fixed %d config value(s) (undo with: npm-console config rollback %s)\n", len(plan.Changes), plan.Snapshot)
+	}
+	return nil
+}
+
+// printConfigPlan prints the changes of a config plan as a table
+func printConfigPlan(plan *core.ConfigPlan) {
+	for _, name := range plan.Skipped {
+		fmt.Printf("⚠️  %s is not available, skipped\n", name)
+	}
+
+	if len(plan.Changes) == 0 {
+		fmt.Println("✅ All package managers already match the team config.")
+		return
+	}
+
+	fmt.Printf("📋 %d change(s) to apply:\n", len(plan.Changes))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MANAGER\tKEY\tCURRENT\tDESIRED")
+	fmt.Fprintln(w, "-------\t---\t-------\t-------")
+	for _, change := range plan.Changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Manager, change.Key, orNone(change.Current), orNone(change.Desired))
+	}
+	w.Flush()
+}
+
+// orNone returns value, or "(none)" when it is empty
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
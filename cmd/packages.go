@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/tabwriter"
 
@@ -42,11 +43,17 @@ Examples:
 var packagesSearchCmd = &cobra.Command{
 	Use:   "search <query>",
 	Short: "Search for packages",
-	Long: `Search for packages by name or description.
+	Long: `Search the registry for packages by name, description or keywords.
+
+Results are ranked by the registry using quality, popularity and maintenance
+scores; the --quality, --popularity and --maintenance flags change how much each
+score weighs. Use --local to search globally installed packages instead.
 	
 Examples:
-  npm-console packages search react           # Search for packages containing "react"
-  npm-console packages search "web framework" # Search with multiple words`,
+  npm-console packages search react           # Search the registry for "react"
+  npm-console packages search "web framework" # Search with multiple words
+  npm-console packages search react --page 2  # Show the second page of results
+  npm-console packages search react --local   # Search installed global packages`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runPackagesSearch,
 }
@@ -54,11 +61,15 @@ Examples:
 var packagesInfoCmd = &cobra.Command{
 	Use:   "info <package-name>",
 	Short: "Show detailed package information",
-	Long: `Show detailed information about a specific package.
+	Long: `Show detailed information about a specific package from the registry
+configured for the package manager, including scoped registries and auth tokens
+from .npmrc or .yarnrc.yml.
 	
 Examples:
   npm-console packages info react             # Show info for react package
-  npm-console packages info @types/node       # Show info for scoped package`,
+  npm-console packages info @types/node       # Show info for scoped package
+  npm-console packages info react@17          # Show info for a version or range
+  npm-console packages info lodash -m pnpm    # Use pnpm's registry`,
 	Args: cobra.ExactArgs(1),
 	RunE: runPackagesInfo,
 }
@@ -88,7 +99,16 @@ func init() {
 	packagesListCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
 	
 	packagesSearchCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	packagesSearchCmd.Flags().BoolP("local", "l", false, "Search globally installed packages instead of the registry")
+	packagesSearchCmd.Flags().StringP("manager", "m", "npm", "Search the registry configured for this package manager")
+	packagesSearchCmd.Flags().IntP("size", "n", 20, "Number of results per page")
+	packagesSearchCmd.Flags().IntP("page", "p", 1, "Page of results to show")
+	packagesSearchCmd.Flags().Float64("quality", 0, "Weight of the quality score (0-1)")
+	packagesSearchCmd.Flags().Float64("popularity", 0, "Weight of the popularity score (0-1)")
+	packagesSearchCmd.Flags().Float64("maintenance", 0, "Weight of the maintenance score (0-1)")
+	
 	packagesInfoCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	packagesInfoCmd.Flags().StringP("manager", "m", "", "Use the registry configured for this package manager")
 	
 	packagesStatsCmd.Flags().BoolP("global", "g", false, "Show global package stats")
 	packagesStatsCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
@@ -190,9 +210,14 @@ func runPackagesSearch(cmd *cobra.Command, args []string) error {
 	
 	query := strings.Join(args, " ")
 	jsonOutput, _ := cmd.Flags().GetBool("json")
+	local, _ := cmd.Flags().GetBool("local")
 	
 	logger := logger.GetDefault()
-	logger.Debug("Searching packages", "query", query)
+	logger.Debug("Searching packages", "query", query, "local", local)
+
+	if !local {
+		return runRegistrySearch(cmd, packageService, query, jsonOutput)
+	}
 
 	packages, err := packageService.SearchPackages(ctx, query)
 	if err != nil {
@@ -233,17 +258,85 @@ func runPackagesSearch(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runRegistrySearch searches the registry and prints one page of results
+func runRegistrySearch(cmd *cobra.Command, packageService *services.PackageService, query string, jsonOutput bool) error {
+	ctx := context.Background()
+	
+	opts := services.SearchOptions{}
+	opts.Manager, _ = cmd.Flags().GetString("manager")
+	opts.Size, _ = cmd.Flags().GetInt("size")
+	opts.Quality, _ = cmd.Flags().GetFloat64("quality")
+	opts.Popularity, _ = cmd.Flags().GetFloat64("popularity")
+	opts.Maintenance, _ = cmd.Flags().GetFloat64("maintenance")
+	
+	page, _ := cmd.Flags().GetInt("page")
+	if page < 1 {
+		return fmt.Errorf("invalid page: %d", page)
+	}
+	if opts.Size < 1 {
+		return fmt.Errorf("invalid page size: %d", opts.Size)
+	}
+	opts.From = (page - 1) * opts.Size
+
+	results, err := packageService.SearchRegistry(ctx, query, opts)
+	if err != nil {
+		return fmt.Errorf("failed to search packages: %w", err)
+	}
+
+	if jsonOutput {
+		return outputJSON(results)
+	}
+
+	if len(results.Results) == 0 {
+		fmt.Printf("No packages found matching '%s'.\n", query)
+		return nil
+	}
+
+	fmt.Printf("Found %d packages matching '%s' on %s (showing %d-%d):\n\n",
+		results.Total, query, results.Registry, results.From+1, results.From+len(results.Results))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tSCORE\tQ/P/M\tDESCRIPTION")
+	fmt.Fprintln(w, "----\t-------\t-----\t-----\t-----------")
+
+	for _, result := range results.Results {
+		description := result.Description
+		if len(description) > 60 {
+			description = description[:57] + "..."
+		}
+		
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f/%.2f/%.2f\t%s\n",
+			result.Name,
+			result.Version,
+			result.Score.Final,
+			result.Score.Quality,
+			result.Score.Popularity,
+			result.Score.Maintenance,
+			description,
+		)
+	}
+
+	w.Flush()
+	
+	if next := results.From + len(results.Results); next < results.Total {
+		fmt.Printf("\nMore results available: use --page %d\n", page+1)
+	}
+	
+	return nil
+}
+
 func runPackagesInfo(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 	packageService := services.NewPackageService()
 	
 	packageName := args[0]
 	jsonOutput, _ := cmd.Flags().GetBool("json")
+	managerName, _ := cmd.Flags().GetString("manager")
 	
 	logger := logger.GetDefault()
 	logger.Debug("Getting package info", "package", packageName)
 
-	packageInfo, err := packageService.GetPackageInfo(ctx, packageName)
+	packageInfo, err := packageService.GetPackageInfoWithManager(ctx, packageName, managerName)
 	if err != nil {
 		return fmt.Errorf("failed to get package info: %w", err)
 	}
@@ -281,6 +374,18 @@ func runPackagesInfo(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Keywords: %s\n", strings.Join(packageInfo.Keywords, ", "))
 	}
 	
+	if len(packageInfo.Engines) > 0 {
+		fmt.Printf("Engines: %s\n", formatDependencyMap(packageInfo.Engines))
+	}
+	
+	if len(packageInfo.PeerDependencies) > 0 {
+		fmt.Printf("Peer Dependencies: %s\n", formatDependencyMap(packageInfo.PeerDependencies))
+	}
+	
+	if len(packageInfo.Dependencies) > 0 {
+		fmt.Printf("Dependencies: %d\n", len(packageInfo.Dependencies))
+	}
+	
 	if packageInfo.Path != "" {
 		fmt.Printf("Path: %s\n", packageInfo.Path)
 	}
@@ -292,6 +397,21 @@ func runPackagesInfo(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// formatDependencyMap formats name/range pairs as "name@range, ..." in name order
+func formatDependencyMap(deps map[string]string) string {
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s@%s", name, deps[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
 func runPackagesStats(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 	packageService := services.NewPackageService()
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"npm-console/internal/audit"
 	"npm-console/internal/core"
 	"npm-console/internal/services"
 	"npm-console/pkg/logger"
@@ -44,10 +45,13 @@ var projectsAnalyzeCmd = &cobra.Command{
 	Short: "Analyze a specific project",
 	Long: `Analyze a specific project and show detailed information about dependencies,
 size, scripts, and potential issues.
+
+Outdated packages are looked up in the registry; use --offline to skip that.
 	
 Examples:
   npm-console projects analyze                    # Analyze current directory
-  npm-console projects analyze /path/to/project   # Analyze specific project`,
+  npm-console projects analyze /path/to/project   # Analyze specific project
+  npm-console projects analyze --offline          # Skip the registry lookup`,
 	RunE: runProjectsAnalyze,
 }
 
@@ -66,19 +70,60 @@ var projectsDepsCmd = &cobra.Command{
 	Use:   "deps [project-path]",
 	Short: "Show project dependency tree",
 	Long: `Show the dependency tree for a specific project.
+
+Versions are resolved from the project's lockfile (package-lock.json,
+yarn.lock, pnpm-lock.yaml or bun.lock). Without a lockfile only the direct
+dependencies and their package.json ranges are shown.
 	
 Examples:
   npm-console projects deps                    # Show deps for current directory
-  npm-console projects deps /path/to/project   # Show deps for specific project`,
+  npm-console projects deps /path/to/project   # Show deps for specific project
+  npm-console projects deps --depth 0          # Show the full resolved tree`,
 	RunE: runProjectsDeps,
 }
 
+var projectsOutdatedCmd = &cobra.Command{
+	Use:   "outdated [project-path]",
+	Short: "Show outdated project dependencies",
+	Long: `Check the direct dependencies of a project against the registry configured
+for each package manager it uses. A project with several lockfiles is checked
+once per manager, against that manager's locked versions.
+
+CURRENT is the installed (locked) version, WANTED is the highest version that
+satisfies the range in package.json and LATEST is the "latest" dist-tag.
+	
+Examples:
+  npm-console projects outdated                    # Check current directory
+  npm-console projects outdated /path/to/project   # Check specific project
+  npm-console projects outdated --json             # Include all dist-tags`,
+	RunE: runProjectsOutdated,
+}
+
+var projectsAuditCmd = &cobra.Command{
+	Use:   "audit [project-path]",
+	Short: "Check project dependencies for known vulnerabilities",
+	Long: `Match every version resolved in the project's lockfile against an offline
+advisory database. The database can be an OSV JSON file, a directory of OSV
+records or an npm bulk advisory file, either local or on an http(s) mirror.
+
+The command exits with a non-zero status when a vulnerability at or above
+--level is found, so it can be used to gate CI builds.
+	
+Examples:
+  npm-console projects audit                              # Audit current directory
+  npm-console projects audit --db ./advisories.json       # Use a specific database
+  npm-console projects audit --level high                 # Only fail on high or critical`,
+	RunE: runProjectsAudit,
+}
+
 func init() {
 	rootCmd.AddCommand(projectsCmd)
 	projectsCmd.AddCommand(projectsScanCmd)
 	projectsCmd.AddCommand(projectsAnalyzeCmd)
 	projectsCmd.AddCommand(projectsStatsCmd)
 	projectsCmd.AddCommand(projectsDepsCmd)
+	projectsCmd.AddCommand(projectsOutdatedCmd)
+	projectsCmd.AddCommand(projectsAuditCmd)
 
 	// Add flags
 	projectsScanCmd.Flags().IntP("depth", "d", 0, "Maximum scan depth (0 = unlimited)")
@@ -86,11 +131,18 @@ func init() {
 	
 	projectsAnalyzeCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
 	projectsAnalyzeCmd.Flags().BoolP("detailed", "D", false, "Show detailed analysis")
+	projectsAnalyzeCmd.Flags().Bool("offline", false, "Don't query the registry for outdated packages")
 	
 	projectsStatsCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
 	
 	projectsDepsCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
-	projectsDepsCmd.Flags().IntP("depth", "d", 1, "Dependency tree depth")
+	projectsDepsCmd.Flags().IntP("depth", "d", 1, "Dependency tree depth (0 = unlimited)")
+	
+	projectsOutdatedCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	
+	projectsAuditCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	projectsAuditCmd.Flags().String("db", "", "Advisory database file, directory or URL (default from config)")
+	projectsAuditCmd.Flags().StringP("level", "l", "", "Minimum severity that fails the audit: info, low, moderate, high, critical (default from config)")
 }
 
 func runProjectsScan(cmd *cobra.Command, args []string) error {
@@ -184,10 +236,18 @@ func runProjectsAnalyze(cmd *cobra.Command, args []string) error {
 	
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 	detailed, _ := cmd.Flags().GetBool("detailed")
+	offline, _ := cmd.Flags().GetBool("offline")
 	
 	logger := logger.GetDefault()
 	logger.Debug("Analyzing project", "path", absPath)
 
+	projectService.SetOffline(offline)
+
+	// Vulnerabilities are filled in from the configured advisory database
+	if cfg, err := loadConfig(); err == nil {
+		projectService.SetAdvisoryDatabase(cfg.Audit.Database)
+	}
+
 	analysis, err := projectService.AnalyzeProject(ctx, absPath)
 	if err != nil {
 		return fmt.Errorf("failed to analyze project: %w", err)
@@ -232,7 +292,7 @@ func runProjectsAnalyze(cmd *cobra.Command, args []string) error {
 		fmt.Printf("\n⚠️  Outdated Packages: %d\n", len(analysis.OutdatedPackages))
 		if detailed {
 			for _, pkg := range analysis.OutdatedPackages {
-				fmt.Printf("  %s@%s\n", pkg.Name, pkg.Version)
+				fmt.Printf("  %s: %s → %s\n", pkg.Name, displayVersion(pkg.Current), pkg.Latest)
 			}
 		}
 	}
@@ -316,7 +376,7 @@ func runProjectsDeps(cmd *cobra.Command, args []string) error {
 	logger := logger.GetDefault()
 	logger.Debug("Getting project dependencies", "path", absPath)
 
-	depTree, err := projectService.GetProjectDependencies(ctx, absPath)
+	depTree, err := projectService.GetProjectDependenciesWithDepth(ctx, absPath, maxDepth)
 	if err != nil {
 		return fmt.Errorf("failed to get project dependencies: %w", err)
 	}
@@ -328,48 +388,197 @@ func runProjectsDeps(cmd *cobra.Command, args []string) error {
 	fmt.Printf("🌳 Dependency Tree: %s\n", depTree.Name)
 	fmt.Printf("==========================\n\n")
 	
-	printDependencyTree(depTree, "", maxDepth, 0)
+	fmt.Printf("%s\n", formatDependencyNode(depTree))
+	printDependencyTree(depTree, "")
 	
 	return nil
 }
 
-// printDependencyTree prints the dependency tree recursively
-func printDependencyTree(node *core.DependencyTree, prefix string, maxDepth, currentDepth int) {
-	if maxDepth > 0 && currentDepth >= maxDepth {
-		return
+func runProjectsOutdated(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	projectService := services.NewProjectService()
+	
+	// Determine project path
+	projectPath := "."
+	if len(args) > 0 {
+		projectPath = args[0]
 	}
 	
-	// Print current node
-	marker := "├── "
-	if currentDepth == 0 {
-		marker = ""
+	// Convert to absolute path
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project path: %w", err)
 	}
 	
-	devMarker := ""
-	if node.DevDependency {
-		devMarker = " (dev)"
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	
+	logger := logger.GetDefault()
+	logger.Debug("Checking outdated packages", "path", absPath)
+
+	outdated, err := projectService.GetOutdatedPackages(ctx, absPath)
+	if err != nil {
+		return fmt.Errorf("failed to check outdated packages: %w", err)
+	}
+
+	if jsonOutput {
+		return outputJSON(outdated)
+	}
+
+	if len(outdated) == 0 {
+		fmt.Println("✅ All dependencies are up to date")
+		return nil
+	}
+
+	fmt.Printf("⚠️  %d outdated packages:\n\n", len(outdated))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tCURRENT\tWANTED\tLATEST\tTYPE\tMANAGER")
+	fmt.Fprintln(w, "-------\t-------\t------\t------\t----\t-------")
+
+	for _, pkg := range outdated {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			pkg.Name,
+			displayVersion(pkg.Current),
+			displayVersion(pkg.Wanted),
+			displayVersion(pkg.Latest),
+			pkg.DependencyType,
+			pkg.Manager,
+		)
+	}
+
+	return w.Flush()
+}
+
+func runProjectsAudit(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	projectService := services.NewProjectService()
+	
+	// Determine project path
+	projectPath := "."
+	if len(args) > 0 {
+		projectPath = args[0]
 	}
 	
-	fmt.Printf("%s%s%s@%s%s\n", prefix, marker, node.Name, node.Version, devMarker)
+	// Convert to absolute path
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project path: %w", err)
+	}
 	
-	// Print children
-	if len(node.Dependencies) > 0 && (maxDepth == 0 || currentDepth < maxDepth-1) {
-		childPrefix := prefix
-		if currentDepth > 0 {
-			childPrefix += "│   "
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	dbSource, _ := cmd.Flags().GetString("db")
+	level, _ := cmd.Flags().GetString("level")
+	
+	// Fall back to the configured database and threshold
+	if dbSource == "" || level == "" {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
 		}
-		
-		for i, child := range node.Dependencies {
-			if i == len(node.Dependencies)-1 {
-				// Last child
-				fmt.Printf("%s└── %s@%s", childPrefix, child.Name, child.Version)
-				if child.DevDependency {
-					fmt.Printf(" (dev)")
-				}
-				fmt.Println()
-			} else {
-				printDependencyTree(child, childPrefix, maxDepth, currentDepth+1)
+		if dbSource == "" {
+			dbSource = cfg.Audit.Database
+		}
+		if level == "" {
+			level = cfg.Audit.Level
+		}
+	}
+	
+	if !audit.ValidSeverity(level) {
+		return fmt.Errorf("invalid severity level: %s", level)
+	}
+	
+	logger := logger.GetDefault()
+	logger.Debug("Auditing project", "path", absPath, "database", dbSource)
+
+	db, err := audit.Load(ctx, dbSource)
+	if err != nil {
+		return fmt.Errorf("failed to load advisory database: %w", err)
+	}
+
+	vulnerabilities, err := projectService.AuditProject(ctx, absPath, db)
+	if err != nil {
+		return fmt.Errorf("failed to audit project: %w", err)
+	}
+
+	failing := 0
+	for _, vuln := range vulnerabilities {
+		if audit.AtLeast(vuln.Severity, level) {
+			failing++
+		}
+	}
+
+	if jsonOutput {
+		if err := outputJSON(vulnerabilities); err != nil {
+			return err
+		}
+	} else if len(vulnerabilities) == 0 {
+		fmt.Printf("✅ No known vulnerabilities found (%d advisories checked)\n", db.Len())
+	} else {
+		fmt.Printf("🔒 %d vulnerabilities found:\n\n", len(vulnerabilities))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SEVERITY\tPACKAGE\tVERSION\tFIXED IN\tID\tTITLE")
+		fmt.Fprintln(w, "--------\t-------\t-------\t--------\t--\t-----")
+
+		for _, vuln := range vulnerabilities {
+			fixedIn := vuln.FixedIn
+			if fixedIn == "" {
+				fixedIn = "-"
 			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				vuln.Severity,
+				vuln.Package,
+				vuln.Version,
+				fixedIn,
+				vuln.ID,
+				vuln.Title,
+			)
 		}
+
+		w.Flush()
+	}
+
+	if failing > 0 {
+		// The findings are already printed, so don't repeat the usage text
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d vulnerabilities at or above %s severity", failing, level)
+	}
+
+	return nil
+}
+
+// displayVersion formats a version for tables, marking missing versions like npm does
+func displayVersion(version string) string {
+	if version == "" {
+		return "MISSING"
+	}
+	return version
+}
+
+// printDependencyTree prints the children of a dependency tree node recursively
+func printDependencyTree(node *core.DependencyTree, prefix string) {
+	for i, child := range node.Dependencies {
+		marker, childPrefix := "├── ", prefix+"│   "
+		if i == len(node.Dependencies)-1 {
+			marker, childPrefix = "└── ", prefix+"    "
+		}
+		
+		fmt.Printf("%s%s%s\n", prefix, marker, formatDependencyNode(child))
+		printDependencyTree(child, childPrefix)
+	}
+}
+
+// formatDependencyNode formats a single dependency tree node for display
+func formatDependencyNode(node *core.DependencyTree) string {
+	line := fmt.Sprintf("%s@%s", node.Name, node.Version)
+	if node.DevDependency {
+		line += " (dev)"
+	}
+	if node.Deduped {
+		line += " (deduped)"
+	}
+	if node.Missing {
+		line += " (missing)"
 	}
+	return line
 }
@@ -1,13 +1,18 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 	"text/tabwriter"
 
-	"npm-console/internal/services"
+	"npm-console/internal/core"
+	"npm-console/internal/managers"
+	"npm-console/pkg/config"
 	"npm-console/pkg/logger"
 
 	"github.com/spf13/cobra"
@@ -21,6 +26,9 @@ var registryCmd = &cobra.Command{
 This command provides functionality to:
 - List current registry configurations
 - Set registry URLs for specific or all package managers
+- Switch between named registry profiles
+- Route package scopes (@scope) to their own registries
+- Manage registry auth tokens
 - Test registry connectivity`,
 	Aliases: []string{"reg", "r"},
 }
@@ -49,6 +57,9 @@ var registryTestCmd = &cobra.Command{
 	Use:   "test [registry-url] [manager]",
 	Short: "Test registry connectivity",
 	Long: `Test connectivity to a registry URL.
+
+The registry is pinged and a known packument is fetched using the manager's
+auth tokens and proxy. HTTP status, latency and TLS details are reported.
 	
 Examples:
   npm-console registry test                                     # Test current registries
@@ -57,10 +68,157 @@ Examples:
 	RunE: runRegistryTest,
 }
 
+var registryBenchCmd = &cobra.Command{
+	Use:   "bench [registry-url | name=registry-url]...",
+	Short: "Benchmark registries and pick the fastest",
+	Long: `Probe candidate registries concurrently and rank them by success rate and
+latency. Candidates are the built-in presets, the mirrors listed under
+managers.mirrors in the configuration file, and any URLs given as arguments.
+	
+Examples:
+  npm-console registry bench                                   # Benchmark presets and configured mirrors
+  npm-console registry bench corp=https://npm.corp.local/      # Include another registry
+  npm-console registry bench --no-presets https://a/ https://b/ # Only compare the given registries
+  npm-console registry bench --apply                           # Use the fastest registry for all managers`,
+	RunE: runRegistryBench,
+}
+
+var registryUseCmd = &cobra.Command{
+	Use:   "use <profile>",
+	Short: "Switch to a named registry profile",
+	Long: `Apply a registry profile from the configuration file. A profile sets the
+registry of every manager, with optional per-manager overrides and scope
+mappings. If any manager fails to switch, the managers already switched are
+restored.
+
+Profiles are defined under registry.profiles, for example:
+
+  registry:
+    profiles:
+      corp:
+        registry: https://npm.corp.local/
+        managers:
+          yarn: https://yarn.corp.local/
+        scopes:
+          "@ourco": https://npm.corp.local/private/
+	
+Examples:
+  npm-console registry use npmjs               # Switch all managers to npmjs
+  npm-console registry use corp -m npm -m pnpm # Switch npm and pnpm only`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRegistryUse,
+}
+
+var registryProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List registry profiles",
+	Long:  `Display the registry profiles defined in the configuration file.`,
+	RunE:  runRegistryProfiles,
+}
+
+var registryScopeCmd = &cobra.Command{
+	Use:   "scope",
+	Short: "Manage scoped registries",
+	Long: `Manage scoped registries, which route packages of a scope such as @ourco
+to their own registry. They are stored as @scope:registry in .npmrc for npm,
+pnpm and yarn 1, under npmScopes in .yarnrc.yml for yarn 2+, and under
+[install.scopes] in bunfig.toml for bun.`,
+}
+
+var registryScopeListCmd = &cobra.Command{
+	Use:   "list [manager]",
+	Short: "List scoped registries",
+	Long: `Display scoped registries for a specific package manager or all managers.
+	
+Examples:
+  npm-console registry scope list          # List scoped registries of all managers
+  npm-console registry scope list yarn     # List scoped registries of yarn`,
+	RunE: runRegistryScopeList,
+}
+
+var registryScopeSetCmd = &cobra.Command{
+	Use:   "set <@scope> <registry-url> [manager]",
+	Short: "Route a scope to a registry",
+	Long: `Route a package scope to a registry for a specific package manager or all managers.
+	
+Examples:
+  npm-console registry scope set @ourco https://npm.corp.local/        # Set for all managers
+  npm-console registry scope set @ourco https://npm.corp.local/ bun    # Set for bun only`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runRegistryScopeSet,
+}
+
+var registryScopeRemoveCmd = &cobra.Command{
+	Use:   "remove <@scope> [manager]",
+	Short: "Remove a scoped registry",
+	Long: `Remove a scoped registry for a specific package manager or all managers.
+	
+Examples:
+  npm-console registry scope remove @ourco          # Remove for all managers
+  npm-console registry scope remove @ourco npm      # Remove for npm only`,
+	Aliases: []string{"rm", "unset"},
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    runRegistryScopeRemove,
+}
+
+var registryAuthCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage registry auth tokens",
+	Long: `Manage registry auth tokens in each manager's native config file:
+//host/:_authToken in .npmrc for npm, pnpm and yarn 1, npmAuthToken in
+.yarnrc.yml for yarn 2+, and the token of install.registry or
+install.scopes entries in bunfig.toml for bun. Tokens are never printed;
+only the last four characters are shown.`,
+	Aliases: []string{"token", "credentials"},
+}
+
+var registryAuthListCmd = &cobra.Command{
+	Use:   "list [manager]",
+	Short: "List registries and their masked tokens",
+	Long: `Display configured registries with masked auth tokens for a specific
+package manager or all managers.
+	
+Examples:
+  npm-console registry auth list          # List credentials of all managers
+  npm-console registry auth list npm      # List credentials of npm`,
+	RunE: runRegistryAuthList,
+}
+
+var registryAuthSetCmd = &cobra.Command{
+	Use:   "set <registry-url> [manager]",
+	Short: "Set or rotate a registry auth token",
+	Long: `Set or rotate the auth token of a registry for a specific package manager
+or all managers. The token is read from standard input unless --env is given,
+in which case the config file references the environment variable instead of
+storing the token.
+	
+Examples:
+  npm-console registry auth set https://npm.corp.local/ < token.txt     # Read the token from stdin
+  npm-console registry auth set https://npm.corp.local/ --env CORP_TOKEN # Reference $CORP_TOKEN
+  npm-console registry auth set https://registry.npmjs.org/ npm        # Prompt for the npm token`,
+	Aliases: []string{"rotate"},
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    runRegistryAuthSet,
+}
+
+var registryAuthRemoveCmd = &cobra.Command{
+	Use:   "remove <registry-url> [manager]",
+	Short: "Remove a registry auth token",
+	Long: `Remove the auth token of a registry for a specific package manager or all managers.
+	
+Examples:
+  npm-console registry auth remove https://npm.corp.local/          # Remove for all managers
+  npm-console registry auth remove https://npm.corp.local/ npm      # Remove for npm only`,
+	Aliases: []string{"rm", "unset"},
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    runRegistryAuthRemove,
+}
+
 var registryResetCmd = &cobra.Command{
 	Use:   "reset [manager]",
 	Short: "Reset registry to default",
-	Long: `Reset registry to default npm registry for specific or all managers.
+	Long: `Reset registry to the default profile (registry.default, npmjs unless
+configured) for specific or all managers.
 	
 Examples:
   npm-console registry reset          # Reset all managers to default
@@ -73,19 +231,40 @@ func init() {
 	registryCmd.AddCommand(registryListCmd)
 	registryCmd.AddCommand(registrySetCmd)
 	registryCmd.AddCommand(registryTestCmd)
+	registryCmd.AddCommand(registryBenchCmd)
+	registryCmd.AddCommand(registryUseCmd)
+	registryCmd.AddCommand(registryProfilesCmd)
+	registryCmd.AddCommand(registryScopeCmd)
+	registryCmd.AddCommand(registryAuthCmd)
 	registryCmd.AddCommand(registryResetCmd)
+	registryAuthCmd.AddCommand(registryAuthListCmd)
+	registryAuthCmd.AddCommand(registryAuthSetCmd)
+	registryAuthCmd.AddCommand(registryAuthRemoveCmd)
+	registryScopeCmd.AddCommand(registryScopeListCmd)
+	registryScopeCmd.AddCommand(registryScopeSetCmd)
+	registryScopeCmd.AddCommand(registryScopeRemoveCmd)
 
 	// Add flags
 	registryListCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
 	registrySetCmd.Flags().BoolP("all", "a", false, "Set for all available managers")
 	registryTestCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	registryBenchCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	registryBenchCmd.Flags().IntP("rounds", "n", 3, "Number of probes per registry")
+	registryBenchCmd.Flags().StringP("manager", "m", "npm", "Use this manager's proxy and credentials")
+	registryBenchCmd.Flags().Bool("no-presets", false, "Skip the built-in registry presets")
+	registryBenchCmd.Flags().Bool("apply", false, "Set the fastest registry for all managers")
+	registryUseCmd.Flags().StringSliceP("manager", "m", nil, "Apply to these managers only (default all available)")
+	registryProfilesCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	registryScopeListCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	registryAuthListCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	registryAuthSetCmd.Flags().StringP("env", "e", "", "Read the token from this environment variable at install time")
 	registryResetCmd.Flags().BoolP("all", "a", false, "Reset all managers")
 	registryResetCmd.Flags().BoolP("force", "f", false, "Force reset without confirmation")
 }
 
 func runRegistryList(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
-	configService := services.NewConfigService()
+	configService := newConfigService()
 	
 	logger := logger.GetDefault()
 	logger.Debug("Listing registry configurations")
@@ -134,7 +313,7 @@ func runRegistryList(cmd *cobra.Command, args []string) error {
 
 func runRegistrySet(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
-	configService := services.NewConfigService()
+	configService := newConfigService()
 	
 	registryURL := args[0]
 	setAll, _ := cmd.Flags().GetBool("all")
@@ -169,7 +348,7 @@ func runRegistrySet(cmd *cobra.Command, args []string) error {
 
 func runRegistryTest(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
-	configService := services.NewConfigService()
+	configService := newConfigService()
 	
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 	logger := logger.GetDefault()
@@ -183,21 +362,20 @@ func runRegistryTest(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to get registry configurations: %w", err)
 		}
 
-		var results []RegistryTestResult
+		var results []*core.RegistryTestResult
 		
 		for _, config := range configs {
 			if config.Registry == "" {
 				continue
 			}
 			
-			err := configService.TestRegistry(ctx, config.Manager, config.Registry)
-			result := RegistryTestResult{
-				Manager:  config.Manager,
-				Registry: config.Registry,
-				Success:  err == nil,
-			}
+			result, err := configService.TestRegistry(ctx, config.Manager, config.Registry)
 			if err != nil {
-				result.Error = err.Error()
+				result = &core.RegistryTestResult{
+					Manager:  config.Manager,
+					Registry: config.Registry,
+					Error:    err.Error(),
+				}
 			}
 			results = append(results, result)
 		}
@@ -221,9 +399,7 @@ func runRegistryTest(cmd *cobra.Command, args []string) error {
 			}
 			
 			fmt.Printf("%s %s: %s\n", status, result.Manager, result.Registry)
-			if result.Error != "" {
-				fmt.Printf("   Error: %s\n", result.Error)
-			}
+			printRegistryTestDetails(result, "   ")
 		}
 		
 		return nil
@@ -238,15 +414,9 @@ func runRegistryTest(cmd *cobra.Command, args []string) error {
 	
 	logger.Debug("Testing specific registry", "manager", managerName, "registry", registryURL)
 	
-	err := configService.TestRegistry(ctx, managerName, registryURL)
-	
-	result := RegistryTestResult{
-		Manager:  managerName,
-		Registry: registryURL,
-		Success:  err == nil,
-	}
+	result, err := configService.TestRegistry(ctx, managerName, registryURL)
 	if err != nil {
-		result.Error = err.Error()
+		return fmt.Errorf("failed to test registry: %w", err)
 	}
 
 	if jsonOutput {
@@ -257,25 +427,526 @@ func runRegistryTest(cmd *cobra.Command, args []string) error {
 		fmt.Printf("✅ Registry test passed: %s\n", registryURL)
 	} else {
 		fmt.Printf("❌ Registry test failed: %s\n", registryURL)
-		fmt.Printf("Error: %s\n", result.Error)
 	}
+	printRegistryTestDetails(result, "")
 	
 	return nil
 }
 
+// printRegistryTestDetails prints the measurements of a registry test
+func printRegistryTestDetails(result *core.RegistryTestResult, indent string) {
+	if result.StatusCode != 0 {
+		fmt.Printf("%sStatus: HTTP %d, latency %dms\n", indent, result.StatusCode, result.LatencyMs)
+	}
+	if result.PingStatus != 0 {
+		fmt.Printf("%sPing: HTTP %d, latency %dms\n", indent, result.PingStatus, result.PingLatencyMs)
+	}
+	if result.TLS != nil {
+		fmt.Printf("%sTLS: %s, %s, issued by %s, expires %s\n", indent,
+			result.TLS.Version, result.TLS.CipherSuite, result.TLS.Issuer, result.TLS.NotAfter.Format("2006-01-02"))
+	}
+	if result.Proxy != "" {
+		fmt.Printf("%sProxy: %s\n", indent, result.Proxy)
+	}
+	if result.AuthFailed {
+		fmt.Printf("%sAuthentication failed: check the auth token for this registry\n", indent)
+	}
+	if result.Error != "" {
+		fmt.Printf("%sError: %s\n", indent, result.Error)
+	}
+}
+
+func runRegistryBench(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+	
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	rounds, _ := cmd.Flags().GetInt("rounds")
+	managerName, _ := cmd.Flags().GetString("manager")
+	noPresets, _ := cmd.Flags().GetBool("no-presets")
+	apply, _ := cmd.Flags().GetBool("apply")
+	
+	logger := logger.GetDefault()
+
+	// Collect candidates: presets, configured mirrors, then arguments
+	var mirrors []core.RegistryMirror
+	if !noPresets {
+		mirrors = append(mirrors, configService.RegistryPresets()...)
+	}
+	if cfg, err := loadConfig(); err == nil {
+		for _, mirror := range cfg.Managers.Mirrors {
+			mirrors = append(mirrors, core.RegistryMirror{Name: mirror.Name, URL: mirror.URL})
+		}
+	} else {
+		logger.Debug("Failed to load configuration", "error", err)
+	}
+	for _, arg := range args {
+		mirror := core.RegistryMirror{URL: arg}
+		if name, url, ok := strings.Cut(arg, "="); ok {
+			mirror = core.RegistryMirror{Name: name, URL: url}
+		}
+		mirrors = append(mirrors, mirror)
+	}
+	
+	logger.Debug("Benchmarking registries", "candidates", len(mirrors), "rounds", rounds)
+	
+	if !jsonOutput {
+		fmt.Printf("⏱️  Benchmarking %d registries (%d rounds each)...\n\n", len(mirrors), rounds)
+	}
+
+	results, err := configService.BenchmarkRegistries(ctx, managerName, mirrors, rounds)
+	if err != nil {
+		return fmt.Errorf("failed to benchmark registries: %w", err)
+	}
+
+	if jsonOutput {
+		if err := outputJSON(results); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RANK\tNAME\tREGISTRY\tSUCCESS\tAVG\tMIN\tMAX")
+		fmt.Fprintln(w, "----\t----\t--------\t-------\t---\t---\t---")
+
+		for i, result := range results {
+			latency := func(ms int64) string {
+				if result.Successes == 0 {
+					return "-"
+				}
+				return fmt.Sprintf("%dms", ms)
+			}
+			
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d/%d\t%s\t%s\t%s\n",
+				i+1,
+				result.Name,
+				result.URL,
+				result.Successes,
+				result.Attempts,
+				latency(result.AvgLatencyMs),
+				latency(result.MinLatencyMs),
+				latency(result.MaxLatencyMs),
+			)
+		}
+
+		w.Flush()
+	}
+
+	if len(results) == 0 || results[0].Successes == 0 {
+		return fmt.Errorf("no registry could be reached")
+	}
+	
+	fastest := results[0]
+	if !apply {
+		if !jsonOutput {
+			fmt.Printf("\n🏆 Fastest: %s (%s)\n", fastest.Name, fastest.URL)
+			fmt.Println("Run with --apply to use it for all managers.")
+		}
+		return nil
+	}
+
+	if err := configService.SetRegistryForAll(ctx, fastest.URL); err != nil {
+		return fmt.Errorf("failed to apply registry: %w", err)
+	}
+	
+	if !jsonOutput {
+		fmt.Printf("\n✅ Registry set for all managers: %s (%s)\n", fastest.URL, fastest.Name)
+	}
+	return nil
+}
+
+func runRegistryUse(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+	
+	managerNames, _ := cmd.Flags().GetStringSlice("manager")
+	
+	logger := logger.GetDefault()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := registryProfile(cfg, args[0])
+	if err != nil {
+		return err
+	}
+	
+	logger.Debug("Applying registry profile", "profile", profile.Name, "managers", managerNames)
+
+	applied, err := configService.ApplyRegistryProfile(ctx, profile, managerNames)
+	if err != nil {
+		return err
+	}
+
+	// Remember the active profile and the registries it set
+	cfg.Registry.Active = profile.Name
+	for manager, registryURL := range applied {
+		cfg.SetManagerRegistry(manager, registryURL)
+	}
+	if err := cfg.Save(""); err != nil {
+		logger.Warn("Failed to save configuration", "error", err)
+	}
+
+	names := make([]string, 0, len(applied))
+	for manager := range applied {
+		names = append(names, manager)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("✅ Switched to registry profile %s\n", profile.Name)
+	for _, manager := range names {
+		fmt.Printf("   %s: %s\n", manager, applied[manager])
+	}
+	for _, scope := range sortedKeys(profile.Scopes) {
+		fmt.Printf("   %s: %s\n", scope, profile.Scopes[scope])
+	}
+	
+	return nil
+}
+
+func runRegistryProfiles(cmd *cobra.Command, args []string) error {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	var profiles []*core.RegistryProfile
+	for _, name := range sortedKeys(cfg.Registry.Profiles) {
+		profile, _ := registryProfile(cfg, name)
+		profiles = append(profiles, profile)
+	}
+
+	if jsonOutput {
+		return outputJSON(profiles)
+	}
+
+	if len(profiles) == 0 {
+		fmt.Println("No registry profiles configured.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROFILE\tREGISTRY\tOVERRIDES\tSCOPES\tDESCRIPTION")
+	fmt.Fprintln(w, "-------\t--------\t---------\t------\t-----------")
+
+	for _, profile := range profiles {
+		name := profile.Name
+		if name == cfg.Registry.Active {
+			name += " *"
+		}
+		
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n",
+			name,
+			profile.Registry,
+			len(profile.Managers),
+			len(profile.Scopes),
+			profile.Description,
+		)
+	}
+
+	w.Flush()
+	return nil
+}
+
+// registryProfile looks up a profile in the configuration file
+func registryProfile(cfg *config.Config, name string) (*core.RegistryProfile, error) {
+	profile, err := cfg.GetRegistryProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.RegistryProfile{
+		Name:        name,
+		Description: profile.Description,
+		Registry:    profile.Registry,
+		Managers:    profile.Managers,
+		Scopes:      profile.Scopes,
+	}, nil
+}
+
+func runRegistryScopeList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+	
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	managerName := ""
+	if len(args) > 0 {
+		managerName = args[0]
+	}
+	
+	logger := logger.GetDefault()
+	logger.Debug("Listing scoped registries", "manager", managerName)
+
+	registries, err := configService.ListScopedRegistries(ctx, managerName)
+	if err != nil {
+		return fmt.Errorf("failed to get scoped registries: %w", err)
+	}
+
+	if jsonOutput {
+		return outputJSON(registries)
+	}
+
+	if len(registries) == 0 {
+		fmt.Println("No scoped registries configured.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MANAGER\tSCOPE\tREGISTRY")
+	fmt.Fprintln(w, "-------\t-----\t--------")
+
+	for _, registry := range registries {
+		fmt.Fprintf(w, "%s\t%s\t%s\n",
+			registry.Manager,
+			registry.Scope,
+			registry.Registry,
+		)
+	}
+
+	w.Flush()
+	return nil
+}
+
+func runRegistryScopeSet(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+	
+	scope := managers.NormalizeScope(args[0])
+	registryURL := args[1]
+	
+	logger := logger.GetDefault()
+
+	if len(args) > 2 {
+		managerName := args[2]
+		logger.Debug("Setting scoped registry for specific manager", "manager", managerName, "scope", scope)
+		
+		if err := configService.SetScopedRegistry(ctx, managerName, scope, registryURL); err != nil {
+			return fmt.Errorf("failed to set scoped registry for %s: %w", managerName, err)
+		}
+		
+		fmt.Printf("✅ %s routed to %s for %s\n", scope, registryURL, managerName)
+		return nil
+	}
+
+	logger.Debug("Setting scoped registry for all managers", "scope", scope)
+	
+	if err := configService.SetScopedRegistryForAll(ctx, scope, registryURL); err != nil {
+		return fmt.Errorf("failed to set scoped registry for all managers: %w", err)
+	}
+	
+	fmt.Printf("✅ %s routed to %s for all managers\n", scope, registryURL)
+	return nil
+}
+
+func runRegistryScopeRemove(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+	
+	scope := managers.NormalizeScope(args[0])
+	
+	logger := logger.GetDefault()
+
+	if len(args) > 1 {
+		managerName := args[1]
+		logger.Debug("Removing scoped registry for specific manager", "manager", managerName, "scope", scope)
+		
+		if err := configService.SetScopedRegistry(ctx, managerName, scope, ""); err != nil {
+			return fmt.Errorf("failed to remove scoped registry for %s: %w", managerName, err)
+		}
+		
+		fmt.Printf("✅ Scoped registry removed for %s: %s\n", managerName, scope)
+		return nil
+	}
+
+	logger.Debug("Removing scoped registry for all managers", "scope", scope)
+	
+	if err := configService.SetScopedRegistryForAll(ctx, scope, ""); err != nil {
+		return fmt.Errorf("failed to remove scoped registry for all managers: %w", err)
+	}
+	
+	fmt.Printf("✅ Scoped registry removed for all managers: %s\n", scope)
+	return nil
+}
+
+func runRegistryAuthList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+	
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	managerName := ""
+	if len(args) > 0 {
+		managerName = args[0]
+	}
+	
+	logger := logger.GetDefault()
+	logger.Debug("Listing registry credentials", "manager", managerName)
+
+	credentials, err := configService.ListCredentials(ctx, managerName)
+	if err != nil {
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	if jsonOutput {
+		return outputJSON(credentials)
+	}
+
+	if len(credentials) == 0 {
+		fmt.Println("No registries configured.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MANAGER\tREGISTRY\tSCOPES\tTOKEN\tSOURCE")
+	fmt.Fprintln(w, "-------\t--------\t------\t-----\t------")
+
+	for _, credential := range credentials {
+		token := "(none)"
+		switch {
+		case credential.EnvVar != "" && credential.Token == "":
+			token = fmt.Sprintf("$%s (unset)", credential.EnvVar)
+		case credential.EnvVar != "":
+			token = fmt.Sprintf("$%s %s", credential.EnvVar, credential.Token)
+		case credential.HasToken:
+			token = credential.Token
+		}
+		
+		scopes := strings.Join(credential.Scopes, ",")
+		if scopes == "" {
+			scopes = "-"
+		}
+		
+		source := credential.Source
+		if source == "" {
+			source = "-"
+		}
+		
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			credential.Manager,
+			credential.Registry,
+			scopes,
+			token,
+			source,
+		)
+	}
+
+	w.Flush()
+	return nil
+}
+
+func runRegistryAuthSet(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+	
+	registryURL := args[0]
+	envVar, _ := cmd.Flags().GetString("env")
+	
+	logger := logger.GetDefault()
+
+	token := ""
+	if envVar == "" {
+		var err error
+		if token, err = readToken(); err != nil {
+			return err
+		}
+	}
+
+	if len(args) > 1 {
+		managerName := args[1]
+		logger.Debug("Setting auth token for specific manager", "manager", managerName, "registry", registryURL)
+		
+		if err := configService.SetAuthToken(ctx, managerName, registryURL, token, envVar); err != nil {
+			return fmt.Errorf("failed to set auth token for %s: %w", managerName, err)
+		}
+		
+		fmt.Printf("✅ Auth token set for %s: %s\n", managerName, registryURL)
+		return nil
+	}
+
+	logger.Debug("Setting auth token for all managers", "registry", registryURL)
+	
+	if err := configService.SetAuthTokenForAll(ctx, registryURL, token, envVar); err != nil {
+		return fmt.Errorf("failed to set auth token for all managers: %w", err)
+	}
+	
+	fmt.Printf("✅ Auth token set for all managers: %s\n", registryURL)
+	return nil
+}
+
+func runRegistryAuthRemove(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	configService := newConfigService()
+	
+	registryURL := args[0]
+	
+	logger := logger.GetDefault()
+
+	if len(args) > 1 {
+		managerName := args[1]
+		logger.Debug("Removing auth token for specific manager", "manager", managerName, "registry", registryURL)
+		
+		if err := configService.SetAuthToken(ctx, managerName, registryURL, "", ""); err != nil {
+			return fmt.Errorf("failed to remove auth token for %s: %w", managerName, err)
+		}
+		
+		fmt.Printf("✅ Auth token removed for %s: %s\n", managerName, registryURL)
+		return nil
+	}
+
+	logger.Debug("Removing auth token for all managers", "registry", registryURL)
+	
+	if err := configService.SetAuthTokenForAll(ctx, registryURL, "", ""); err != nil {
+		return fmt.Errorf("failed to remove auth token for all managers: %w", err)
+	}
+	
+	fmt.Printf("✅ Auth token removed for all managers: %s\n", registryURL)
+	return nil
+}
+
+// readToken reads a token from standard input so it stays out of the shell history
+func readToken() (string, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Print("Token: ")
+	}
+
+	token, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	token = strings.TrimSpace(token)
+	if token == "" {
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		return "", fmt.Errorf("no token given; pipe it on stdin or use --env")
+	}
+	return token, nil
+}
+
 func runRegistryReset(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
-	configService := services.NewConfigService()
+	configService := newConfigService()
 	
 	resetAll, _ := cmd.Flags().GetBool("all")
 	force, _ := cmd.Flags().GetBool("force")
-	defaultRegistry := "https://registry.npmjs.org/"
 	
 	logger := logger.GetDefault()
 
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	profile, err := registryProfile(cfg, cfg.Registry.Default)
+	if err != nil {
+		return fmt.Errorf("failed to resolve default registry profile: %w", err)
+	}
+	// Resetting only touches registries, not scope mappings
+	profile.Scopes = nil
+
 	if len(args) > 0 && !resetAll {
 		// Reset specific manager
 		managerName := args[0]
+		defaultRegistry := profile.RegistryFor(managerName)
 		
 		if !force {
 			fmt.Printf("This will reset %s registry to default (%s). Continue? (y/N): ", managerName, defaultRegistry)
@@ -300,7 +971,7 @@ func runRegistryReset(cmd *cobra.Command, args []string) error {
 
 	// Reset all managers
 	if !force {
-		fmt.Printf("This will reset all registries to default (%s). Continue? (y/N): ", defaultRegistry)
+		fmt.Printf("This will reset all registries to the %s profile (%s). Continue? (y/N): ", profile.Name, profile.Registry)
 		var response string
 		fmt.Scanln(&response)
 		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
@@ -311,19 +982,10 @@ func runRegistryReset(cmd *cobra.Command, args []string) error {
 	
 	logger.Debug("Resetting registry for all managers")
 	
-	err := configService.SetRegistryForAll(ctx, defaultRegistry)
-	if err != nil {
+	if _, err := configService.ApplyRegistryProfile(ctx, profile, nil); err != nil {
 		return fmt.Errorf("failed to reset registry for all managers: %w", err)
 	}
 	
-	fmt.Printf("✅ Registry reset for all managers: %s\n", defaultRegistry)
+	fmt.Printf("✅ Registry reset for all managers: %s\n", profile.Registry)
 	return nil
 }
-
-// RegistryTestResult represents the result of a registry test
-type RegistryTestResult struct {
-	Manager  string `json:"manager"`
-	Registry string `json:"registry"`
-	Success  bool   `json:"success"`
-	Error    string `json:"error,omitempty"`
-}
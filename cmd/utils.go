@@ -3,6 +3,10 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+
+	"npm-console/internal/services"
+	"npm-console/pkg/config"
 )
 
 // outputJSON outputs any data structure as formatted JSON
@@ -15,3 +19,28 @@ func outputJSON(data interface{}) error {
 	fmt.Println(string(jsonData))
 	return nil
 }
+
+// loadConfig loads the npm-console configuration, honoring --config
+func loadConfig() (*config.Config, error) {
+	return config.Load(cfgFile)
+}
+
+// newConfigService creates a config service that keeps its snapshots in the
+// configured data directory
+func newConfigService() *services.ConfigService {
+	configService := services.NewConfigService()
+	if cfg, err := loadConfig(); err == nil {
+		configService.SetDataDir(cfg.App.DataDir)
+	}
+	return configService
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
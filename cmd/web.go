@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"npm-console/internal/web"
-	"npm-console/pkg/config"
 	"npm-console/pkg/logger"
 
 	"github.com/spf13/cobra"
@@ -42,7 +41,7 @@ func init() {
 
 func runWebServer(cmd *cobra.Command, args []string) error {
 	// Load configuration
-	cfg, err := config.Load("")
+	cfg, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
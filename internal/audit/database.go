@@ -0,0 +1,147 @@
+// Package audit matches resolved package versions against an offline advisory database
+package audit
+
+import (
+	"sort"
+	"strings"
+
+	"npm-console/internal/core"
+	"npm-console/pkg/semver"
+)
+
+// Severity levels in increasing order of importance
+var severityLevels = map[string]int{
+	"info":     0,
+	"low":      1,
+	"moderate": 2,
+	"high":     3,
+	"critical": 4,
+}
+
+// Advisory describes a vulnerability affecting some versions of a package
+type Advisory struct {
+	ID          string          `json:"id"`
+	Package     string          `json:"package"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Severity    string          `json:"severity"`
+	URL         string          `json:"url,omitempty"`
+	Affected    []AffectedRange `json:"affected"`
+}
+
+// AffectedRange is a vulnerable version range and the version that fixes it
+type AffectedRange struct {
+	Range   string `json:"range"`
+	FixedIn string `json:"fixed_in,omitempty"`
+}
+
+// Database is an in-memory advisory database indexed by package name
+type Database struct {
+	Source     string
+	advisories map[string][]*Advisory
+	count      int
+}
+
+// NewDatabase creates an empty advisory database
+func NewDatabase(source string) *Database {
+	return &Database{
+		Source:     source,
+		advisories: make(map[string][]*Advisory),
+	}
+}
+
+// Add adds an advisory to the database
+func (d *Database) Add(advisory *Advisory) {
+	advisory.Severity = NormalizeSeverity(advisory.Severity)
+	d.advisories[advisory.Package] = append(d.advisories[advisory.Package], advisory)
+	d.count++
+}
+
+// Len returns the number of advisories in the database
+func (d *Database) Len() int {
+	return d.count
+}
+
+// Advisories returns the advisories recorded for a package
+func (d *Database) Advisories(name string) []*Advisory {
+	return d.advisories[name]
+}
+
+// Match returns the vulnerabilities affecting a specific package version
+func (d *Database) Match(name, version string) []core.Vulnerability {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return nil
+	}
+
+	var vulnerabilities []core.Vulnerability
+	for _, advisory := range d.advisories[name] {
+		for _, affected := range advisory.Affected {
+			rng, err := semver.ParseRange(affected.Range)
+			if err != nil || !rng.ContainsPrerelease(v) {
+				continue
+			}
+
+			vulnerabilities = append(vulnerabilities, core.Vulnerability{
+				ID:          advisory.ID,
+				Package:     name,
+				Version:     version,
+				Severity:    advisory.Severity,
+				Title:       advisory.Title,
+				Description: advisory.Description,
+				FixedIn:     affected.FixedIn,
+				URL:         advisory.URL,
+			})
+			break
+		}
+	}
+
+	return vulnerabilities
+}
+
+// NormalizeSeverity maps the severity names used by OSV, GHSA and npm onto
+// info/low/moderate/high/critical. Unrecognised values become "unknown".
+func NormalizeSeverity(severity string) string {
+	severity = strings.ToLower(strings.TrimSpace(severity))
+	if severity == "medium" {
+		return "moderate"
+	}
+	if _, ok := severityLevels[severity]; ok {
+		return severity
+	}
+	return "unknown"
+}
+
+// ValidSeverity reports whether severity is a known threshold level
+func ValidSeverity(severity string) bool {
+	_, ok := severityLevels[strings.ToLower(severity)]
+	return ok
+}
+
+// AtLeast reports whether severity is at or above threshold. Advisories without
+// a known severity are treated as moderate so they are not silently ignored.
+func AtLeast(severity, threshold string) bool {
+	return severityRank(severity) >= severityRank(threshold)
+}
+
+// severityRank returns the order of a severity level
+func severityRank(severity string) int {
+	if rank, ok := severityLevels[NormalizeSeverity(severity)]; ok {
+		return rank
+	}
+	return severityLevels["moderate"]
+}
+
+// SortVulnerabilities orders vulnerabilities by decreasing severity, then by package
+func SortVulnerabilities(vulnerabilities []core.Vulnerability) {
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		a, b := vulnerabilities[i], vulnerabilities[j]
+		if ra, rb := severityRank(a.Severity), severityRank(b.Severity); ra != rb {
+			return ra > rb
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return semver.Compare(a.Version, b.Version) < 0
+	})
+}
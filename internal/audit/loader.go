@@ -0,0 +1,306 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"npm-console/internal/core"
+	"npm-console/pkg/utils"
+)
+
+// osvEntry mirrors the parts of an OSV vulnerability record we read
+type osvEntry struct {
+	ID               string        `json:"id"`
+	Summary          string        `json:"summary"`
+	Details          string        `json:"details"`
+	Affected         []osvAffected `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+	References []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"references"`
+}
+
+// osvAffected is an entry of an OSV record's "affected" list
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string              `json:"type"`
+		Events []map[string]string `json:"events"`
+	} `json:"ranges"`
+	Versions         []string `json:"versions"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// npmAdvisory is an advisory in the npm bulk advisory format
+type npmAdvisory struct {
+	ID                 interface{} `json:"id"`
+	Title              string      `json:"title"`
+	Overview           string      `json:"overview"`
+	Severity           string      `json:"severity"`
+	URL                string      `json:"url"`
+	VulnerableVersions string      `json:"vulnerable_versions"`
+	PatchedVersions    string      `json:"patched_versions"`
+}
+
+// Load reads an advisory database from a JSON file, a directory of JSON files
+// (such as an extracted OSV export) or an http(s) mirror URL. Both OSV records
+// and the npm bulk advisory format ({"name": [advisory, ...]}) are understood.
+func Load(ctx context.Context, source string) (*Database, error) {
+	if source == "" {
+		return nil, core.NewValidationError("source", source, "advisory database source cannot be empty")
+	}
+
+	db := NewDatabase(source)
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		data, err := fetch(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.parse(data); err != nil {
+			return nil, fmt.Errorf("failed to parse advisory database %s: %w", source, err)
+		}
+		return db, nil
+	}
+
+	path, err := utils.ExpandPath(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand path: %w", err)
+	}
+
+	if utils.IsDir(path) {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || filepath.Ext(file) != ".json" {
+				return err
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			if err := db.parse(data); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load advisory database %s: %w", source, err)
+		}
+		return db, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read advisory database: %w", err)
+	}
+	if err := db.parse(data); err != nil {
+		return nil, fmt.Errorf("failed to parse advisory database %s: %w", source, err)
+	}
+
+	return db, nil
+}
+
+// fetch downloads an advisory database from a mirror
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download advisory database: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download advisory database: %s", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// parse detects the format of a JSON document and adds its advisories
+func (d *Database) parse(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+
+	// A JSON array is a list of OSV records
+	if data[0] == '[' {
+		var entries []osvEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			d.addOSV(entry)
+		}
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	// OSV records always carry an id; withdrawn ones may have no "affected" list
+	if _, ok := fields["id"]; ok {
+		var entry osvEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return err
+		}
+		d.addOSV(entry)
+		return nil
+	}
+
+	// Otherwise it is a bulk advisory document keyed by package name
+	for name, raw := range fields {
+		var advisories []npmAdvisory
+		if err := json.Unmarshal(raw, &advisories); err != nil {
+			return fmt.Errorf("unrecognised advisory entry for %s: %w", name, err)
+		}
+		for _, advisory := range advisories {
+			d.addNPM(name, advisory)
+		}
+	}
+
+	return nil
+}
+
+// addOSV converts the npm parts of an OSV record into advisories
+func (d *Database) addOSV(entry osvEntry) {
+	url := ""
+	for _, ref := range entry.References {
+		if ref.Type == "ADVISORY" || url == "" {
+			url = ref.URL
+		}
+	}
+
+	for _, affected := range entry.Affected {
+		if !strings.EqualFold(affected.Package.Ecosystem, "npm") {
+			continue
+		}
+
+		advisory := &Advisory{
+			ID:          entry.ID,
+			Package:     affected.Package.Name,
+			Title:       entry.Summary,
+			Description: entry.Details,
+			Severity:    entry.DatabaseSpecific.Severity,
+			URL:         url,
+		}
+		if advisory.Severity == "" {
+			advisory.Severity = affected.DatabaseSpecific.Severity
+		}
+
+		for _, r := range affected.Ranges {
+			if r.Type == "SEMVER" || r.Type == "ECOSYSTEM" {
+				advisory.Affected = append(advisory.Affected, osvEventRanges(r.Events)...)
+			}
+		}
+		// Records without ranges list the affected versions explicitly
+		if len(advisory.Affected) == 0 {
+			for _, version := range affected.Versions {
+				advisory.Affected = append(advisory.Affected, AffectedRange{Range: version})
+			}
+		}
+
+		if len(advisory.Affected) > 0 {
+			d.Add(advisory)
+		}
+	}
+}
+
+// osvEventRanges turns an OSV introduced/fixed/last_affected event list into ranges
+func osvEventRanges(events []map[string]string) []AffectedRange {
+	var ranges []AffectedRange
+	introduced := ""
+
+	for _, event := range events {
+		switch {
+		case event["introduced"] != "":
+			introduced = event["introduced"]
+			if introduced == "0" {
+				introduced = "0.0.0"
+			}
+		case event["fixed"] != "" && introduced != "":
+			ranges = append(ranges, AffectedRange{
+				Range:   fmt.Sprintf(">=%s <%s", introduced, event["fixed"]),
+				FixedIn: event["fixed"],
+			})
+			introduced = ""
+		case event["last_affected"] != "" && introduced != "":
+			ranges = append(ranges, AffectedRange{
+				Range: fmt.Sprintf(">=%s <=%s", introduced, event["last_affected"]),
+			})
+			introduced = ""
+		}
+	}
+
+	if introduced != "" {
+		ranges = append(ranges, AffectedRange{Range: ">=" + introduced})
+	}
+
+	return ranges
+}
+
+// addNPM converts an npm bulk advisory into an advisory
+func (d *Database) addNPM(name string, advisory npmAdvisory) {
+	if advisory.VulnerableVersions == "" {
+		return
+	}
+
+	id := ""
+	if advisory.ID != nil {
+		id = fmt.Sprint(advisory.ID)
+	}
+
+	d.Add(&Advisory{
+		ID:          id,
+		Package:     name,
+		Title:       advisory.Title,
+		Description: advisory.Overview,
+		Severity:    advisory.Severity,
+		URL:         advisory.URL,
+		Affected: []AffectedRange{{
+			Range:   advisory.VulnerableVersions,
+			FixedIn: npmFixedIn(advisory),
+		}},
+	})
+}
+
+// npmFixedIn derives the first fixed version from patched_versions (">=1.2.3")
+// or from the upper bound of vulnerable_versions ("<1.2.3")
+func npmFixedIn(advisory npmAdvisory) string {
+	if patched := strings.TrimSpace(advisory.PatchedVersions); strings.HasPrefix(patched, ">=") && !strings.ContainsAny(patched, " |") {
+		return strings.TrimSpace(strings.TrimPrefix(patched, ">="))
+	}
+
+	sets := strings.Split(advisory.VulnerableVersions, "||")
+	for _, token := range strings.Fields(strings.ReplaceAll(sets[len(sets)-1], "< ", "<")) {
+		if strings.HasPrefix(token, "<") && !strings.HasPrefix(token, "<=") {
+			return strings.TrimPrefix(token, "<")
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOSV(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "osv.json")
+	os.WriteFile(path, []byte(`[{
+		"id": "GHSA-xxxx-yyyy-zzzz",
+		"summary": "Prototype pollution in lodash",
+		"database_specific": {"severity": "HIGH"},
+		"affected": [
+			{"package": {"ecosystem": "npm", "name": "lodash"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]},
+			{"package": {"ecosystem": "PyPI", "name": "lodash"}, "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}]}]}
+		]
+	}]`), 0644)
+
+	db, err := Load(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if db.Len() != 1 {
+		t.Fatalf("Expected 1 advisory, got %d", db.Len())
+	}
+
+	vulns := db.Match("lodash", "4.17.20")
+	if len(vulns) != 1 {
+		t.Fatalf("Expected 1 vulnerability, got %d", len(vulns))
+	}
+	if vulns[0].Severity != "high" || vulns[0].FixedIn != "4.17.21" {
+		t.Errorf("Unexpected vulnerability: %+v", vulns[0])
+	}
+	if len(db.Match("lodash", "4.17.21")) != 0 {
+		t.Error("Fixed version should not match")
+	}
+}
+
+func TestLoadNPMBulk(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bulk.json")
+	os.WriteFile(path, []byte(`{
+		"minimist": [{
+			"id": 1179,
+			"title": "Prototype Pollution",
+			"severity": "moderate",
+			"vulnerable_versions": "<0.2.1 || >=1.0.0 <1.2.3",
+			"patched_versions": ">=1.2.3"
+		}]
+	}`), 0644)
+
+	db, err := Load(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	vulns := db.Match("minimist", "1.2.0")
+	if len(vulns) != 1 {
+		t.Fatalf("Expected 1 vulnerability, got %d", len(vulns))
+	}
+	if vulns[0].ID != "1179" || vulns[0].FixedIn != "1.2.3" {
+		t.Errorf("Unexpected vulnerability: %+v", vulns[0])
+	}
+	if len(db.Match("minimist", "0.2.1")) != 0 {
+		t.Error("Version between vulnerable ranges should not match")
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	tests := []struct {
+		severity  string
+		threshold string
+		expected  bool
+	}{
+		{"critical", "high", true},
+		{"moderate", "high", false},
+		{"medium", "moderate", true},
+		{"unknown", "low", true},
+	}
+
+	for _, tt := range tests {
+		if got := AtLeast(tt.severity, tt.threshold); got != tt.expected {
+			t.Errorf("AtLeast(%q, %q) = %v, want %v", tt.severity, tt.threshold, got, tt.expected)
+		}
+	}
+}
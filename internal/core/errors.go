@@ -12,12 +12,16 @@ var (
 	ErrInvalidPath         = errors.New("invalid path")
 	ErrInvalidConfig       = errors.New("invalid configuration")
 	ErrCacheNotFound       = errors.New("cache not found")
+	ErrCacheEntryNotFound  = errors.New("cache entry not found")
 	ErrProjectNotFound     = errors.New("project not found")
 	ErrPackageNotFound     = errors.New("package not found")
+	ErrLockfileNotFound    = errors.New("lockfile not found")
 	ErrPermissionDenied    = errors.New("permission denied")
 	ErrNetworkError        = errors.New("network error")
 	ErrInvalidRegistry     = errors.New("invalid registry URL")
 	ErrInvalidProxy        = errors.New("invalid proxy configuration")
+	ErrNotSupported        = errors.New("operation not supported")
+	ErrSnapshotNotFound    = errors.New("config snapshot not found")
 )
 
 // ManagerError represents an error specific to a package manager
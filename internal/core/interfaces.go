@@ -28,13 +28,70 @@ type PackageManager interface {
 	// SetRegistry sets the registry URL
 	SetRegistry(ctx context.Context, url string) error
 	
-	// SetProxy sets the proxy configuration
-	SetProxy(ctx context.Context, proxy string) error
+	// SetProxy sets the HTTP and HTTPS proxies and the hosts that bypass them
+	SetProxy(ctx context.Context, proxy ProxySettings) error
 	
 	// GetProjects scans for projects using this package manager
 	GetProjects(ctx context.Context, rootPath string) ([]Project, error)
 }
 
+// ScopedRegistryManager is implemented by package managers that can route
+// package scopes (e.g. "@ourco") to their own registries
+type ScopedRegistryManager interface {
+	// GetScopedRegistries returns the configured "@scope" -> registry mappings
+	GetScopedRegistries(ctx context.Context) (map[string]string, error)
+
+	// SetScopedRegistry maps a scope to a registry; an empty URL removes the mapping
+	SetScopedRegistry(ctx context.Context, scope string, url string) error
+}
+
+// CacheBrowser is implemented by package managers whose cache index can be
+// listed and edited entry by entry
+type CacheBrowser interface {
+	// ListCacheEntries returns the live entries of the cache index
+	ListCacheEntries(ctx context.Context) ([]CacheIndexEntry, error)
+
+	// RemoveCacheEntries removes the entries with the given keys, and the
+	// content no remaining entry uses
+	RemoveCacheEntries(ctx context.Context, keys []string) (*CacheEvictResult, error)
+}
+
+// CacheImporter is implemented by package managers whose cache can be
+// seeded with package tarballs so that offline installs find them
+type CacheImporter interface {
+	// ImportTarballs adds the tarballs at each package's File to the cache,
+	// or to dir for managers installing offline from a mirror directory
+	ImportTarballs(ctx context.Context, packages []BundledPackage, dir string) (*CacheImportResult, error)
+}
+
+// CredentialManager is implemented by package managers whose registry auth
+// tokens can be managed
+type CredentialManager interface {
+	// GetCredentials returns the configured registries with masked tokens
+	GetCredentials(ctx context.Context) ([]Credential, error)
+
+	// SetAuthToken stores a token for a registry, or a reference to envVar
+	// when it is set; if both are empty the token is removed
+	SetAuthToken(ctx context.Context, registry string, token string, envVar string) error
+}
+
+// ConfigExplainer is implemented by package managers that can report which
+// layer each config value comes from
+type ConfigExplainer interface {
+	// ExplainConfig returns every config key that applies to a project with
+	// its effective value and the values it shadows; cli holds values passed
+	// on the command line
+	ExplainConfig(ctx context.Context, projectPath string, cli map[string]string) ([]ConfigEntry, error)
+}
+
+// ConfigSetter is implemented by package managers that can set arbitrary
+// config keys
+type ConfigSetter interface {
+	// SetConfigValue sets a key in the user config file; an empty value
+	// removes it
+	SetConfigValue(ctx context.Context, key string, value string) error
+}
+
 // CacheService defines the interface for cache management
 type CacheService interface {
 	GetAllCacheInfo(ctx context.Context) ([]CacheInfo, error)
@@ -53,8 +110,19 @@ type PackageService interface {
 type ConfigService interface {
 	GetAllConfigs(ctx context.Context) ([]Config, error)
 	SetRegistry(ctx context.Context, manager string, url string) error
-	SetProxy(ctx context.Context, manager string, proxy string) error
-	TestRegistry(ctx context.Context, manager string, url string) error
+	SetProxy(ctx context.Context, manager string, proxy ProxySettings) error
+	TestRegistry(ctx context.Context, manager string, url string) (*RegistryTestResult, error)
+	TestProxy(ctx context.Context, manager string, proxy string) (*ProxyTestResult, error)
+	DetectProxy(ctx context.Context, manager string, pacFile string) (*ProxyDetection, error)
+	ApplyDetectedProxy(ctx context.Context, manager string, pacFile string) (*ProxyDetection, error)
+	ListScopedRegistries(ctx context.Context, manager string) ([]ScopedRegistry, error)
+	SetScopedRegistry(ctx context.Context, manager string, scope string, url string) error
+	ListCredentials(ctx context.Context, manager string) ([]Credential, error)
+	SetAuthToken(ctx context.Context, manager string, registry string, token string, envVar string) error
+	ExplainConfig(ctx context.Context, manager string, key string, projectPath string, cli map[string]string) ([]ConfigEntry, error)
+	ConfigHistory(ctx context.Context) ([]ConfigSnapshot, error)
+	DiffConfig(ctx context.Context, from string, to string) (*ConfigDiff, error)
+	RollbackConfig(ctx context.Context, id string) (*ConfigRollback, error)
 }
 
 // ProjectService defines the interface for project management
@@ -1,6 +1,10 @@
 package core
 
-import "time"
+import (
+	"strings"
+	"time"
+	"unicode"
+)
 
 // CacheInfo 表示包管理器的缓存信息
 type CacheInfo struct {
@@ -11,6 +15,145 @@ type CacheInfo struct {
 	LastUpdated time.Time `json:"last_updated"` // 最后缓存更新时间
 }
 
+// CacheEntry is a part of a package manager's cache that can be evicted on
+// its own: a file, or a directory holding one extracted package
+type CacheEntry struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`       // newest modification time within the entry
+	Keys    []string  `json:"keys,omitempty"` // cache index keys evicted with the entry, for npm
+}
+
+// CachePruneResult reports what a cache policy evicted from a manager's cache
+type CachePruneResult struct {
+	Manager    string `json:"manager"`
+	Path       string `json:"path"`
+	SizeBefore int64  `json:"size_before"`
+	SizeAfter  int64  `json:"size_after"`
+	Removed    int    `json:"removed"` // entries evicted
+	Expired    int    `json:"expired"` // of which were older than the max age
+	BytesFreed int64  `json:"bytes_freed"`
+	DryRun     bool   `json:"dry_run"`
+}
+
+// CacheIndexEntry is an entry of a content-addressed cache index, such as
+// npm's cacache: a request key and the content it points to
+type CacheIndexEntry struct {
+	Key       string    `json:"key"`
+	Kind      string    `json:"kind"`              // "tarball", "packument" or "other"
+	Package   string    `json:"package,omitempty"` // package the entry belongs to, when known
+	Version   string    `json:"version,omitempty"` // tarball version, when known
+	URL       string    `json:"url,omitempty"`
+	Integrity string    `json:"integrity"`
+	Size      int64     `json:"size"`
+	Time      time.Time `json:"time"`
+	Content   string    `json:"content,omitempty"` // path of the content file
+}
+
+// CacheEvictResult reports the cache index entries removed from a cache
+type CacheEvictResult struct {
+	Manager        string   `json:"manager"`
+	Keys           []string `json:"keys"`
+	ContentRemoved int      `json:"content_removed"` // content files no other entry used
+	BytesFreed     int64    `json:"bytes_freed"`
+}
+
+// CacheIssue is a problem found verifying a cache against its recorded
+// hashes. Repairing it deletes Path, and drops Key from the cache index.
+type CacheIssue struct {
+	Kind   string `json:"kind"` // "corrupt", "missing" or "orphaned"
+	Path   string `json:"path"`
+	Key    string `json:"key,omitempty"`
+	Detail string `json:"detail"`
+	Size   int64  `json:"size"`
+}
+
+// CacheVerifyResult reports the integrity check of a manager's cache
+type CacheVerifyResult struct {
+	Manager    string       `json:"manager"`
+	Path       string       `json:"path"`
+	Checked    int          `json:"checked"` // entries whose content was verified
+	Issues     []CacheIssue `json:"issues"`
+	Repaired   int          `json:"repaired"`
+	BytesFreed int64        `json:"bytes_freed"`
+}
+
+// CachedPackage is a package version held in a manager's cache
+type CachedPackage struct {
+	Manager   string `json:"manager"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Integrity string `json:"integrity,omitempty"` // sha512 of the package tarball, when recorded
+	Size      int64  `json:"size"`
+	Path      string `json:"path"`
+	Key       string `json:"key,omitempty"` // cache index key, for npm
+}
+
+// CacheDuplicate is a package version cached by more than one manager
+type CacheDuplicate struct {
+	Name        string          `json:"name"`
+	Version     string          `json:"version"`
+	SameContent bool            `json:"same_content"` // every copy records the same tarball integrity
+	Copies      []CachedPackage `json:"copies"`
+	Reclaimable int64           `json:"reclaimable"` // bytes freed by keeping a single copy
+}
+
+// CacheDuplicateReport lists the package versions cached more than once
+// across managers
+type CacheDuplicateReport struct {
+	Keep        string           `json:"keep,omitempty"` // manager whose copies are kept
+	Duplicates  []CacheDuplicate `json:"duplicates"`
+	Reclaimable int64            `json:"reclaimable"`
+}
+
+// CacheDedupeResult reports the copies a dedupe removed
+type CacheDedupeResult struct {
+	Keep       string           `json:"keep"`
+	Removed    []CachedPackage  `json:"removed"`
+	Skipped    []CacheDuplicate `json:"skipped,omitempty"` // copies whose content differs, left in place
+	BytesFreed int64            `json:"bytes_freed"`
+	DryRun     bool             `json:"dry_run"`
+}
+
+// CacheBundleManifest describes the package tarballs of an offline cache
+// bundle, exported from a project's lockfile
+type CacheBundleManifest struct {
+	FormatVersion int              `json:"format_version"`
+	Project       string           `json:"project"`
+	Lockfile      string           `json:"lockfile"`
+	CreatedAt     time.Time        `json:"created_at"`
+	Packages      []BundledPackage `json:"packages"`
+	Missing       []BundledPackage `json:"missing,omitempty"` // locked packages no local cache held
+}
+
+// BundledPackage is a package tarball in an offline cache bundle
+type BundledPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Resolved  string `json:"resolved,omitempty"`  // tarball URL from the lockfile
+	Integrity string `json:"integrity,omitempty"` // sha512 of the tarball
+	File      string `json:"file,omitempty"`      // path in the bundle, or on disk while importing
+	Size      int64  `json:"size,omitempty"`
+	Source    string `json:"source,omitempty"` // manager whose cache it was taken from
+}
+
+// CacheExportResult reports an offline cache bundle written to disk
+type CacheExportResult struct {
+	Archive  string               `json:"archive"`
+	Size     int64                `json:"size"`
+	Manifest *CacheBundleManifest `json:"manifest"`
+}
+
+// CacheImportResult reports the tarballs of a bundle added to a manager's
+// cache or offline mirror
+type CacheImportResult struct {
+	Manager  string   `json:"manager"`
+	Target   string   `json:"target"`   // cache, store or mirror directory
+	Imported int      `json:"imported"` // tarballs added
+	Skipped  int      `json:"skipped"`  // tarballs already present
+	Failed   []string `json:"failed,omitempty"`
+}
+
 // Package 表示一个包
 type Package struct {
 	Name        string            `json:"name"`        // 包名称
@@ -37,12 +180,265 @@ type PackageDetail struct {
 	PeerDependencies map[string]string `json:"peer_dependencies,omitempty"`
 }
 
+// SearchResult represents a package found in a registry search
+type SearchResult struct {
+	Name        string      `json:"name"`
+	Version     string      `json:"version"`
+	Description string      `json:"description"`
+	Keywords    []string    `json:"keywords,omitempty"`
+	Publisher   string      `json:"publisher,omitempty"`
+	Date        string      `json:"date,omitempty"`
+	Homepage    string      `json:"homepage,omitempty"`
+	Repository  string      `json:"repository,omitempty"`
+	Score       SearchScore `json:"score"`
+}
+
+// SearchScore represents the registry's ranking of a search result
+type SearchScore struct {
+	Final       float64 `json:"final"`
+	Quality     float64 `json:"quality"`
+	Popularity  float64 `json:"popularity"`
+	Maintenance float64 `json:"maintenance"`
+}
+
+// SearchResults represents a page of registry search results
+type SearchResults struct {
+	Query    string         `json:"query"`
+	Registry string         `json:"registry"`
+	Total    int            `json:"total"`
+	From     int            `json:"from"`
+	Size     int            `json:"size"`
+	Results  []SearchResult `json:"results"`
+}
+
 // Config represents package manager configuration
 type Config struct {
-	Manager  string            `json:"manager"`
-	Registry string            `json:"registry"`
-	Proxy    string            `json:"proxy"`
-	Settings map[string]string `json:"settings"`
+	Manager    string            `json:"manager"`
+	Registry   string            `json:"registry"`
+	Proxy      string            `json:"proxy"`                 // proxy for http:// requests
+	HTTPSProxy string            `json:"https_proxy,omitempty"` // proxy for https:// requests
+	NoProxy    []string          `json:"no_proxy,omitempty"`    // hosts reached without a proxy
+	Settings   map[string]string `json:"settings"`
+}
+
+// ProxySettings are the proxies a package manager uses and the hosts it
+// reaches directly. Empty settings remove every proxy.
+type ProxySettings struct {
+	HTTP    string   `json:"http,omitempty"`     // proxy for http:// requests
+	HTTPS   string   `json:"https,omitempty"`    // proxy for https:// requests
+	NoProxy []string `json:"no_proxy,omitempty"` // hosts and domains bypassing the proxies
+}
+
+// ProxyRoute is the proxy a PAC file picks for one registry
+type ProxyRoute struct {
+	Registry string `json:"registry"`
+	Result   string `json:"result"`          // what FindProxyForURL returned
+	Proxy    string `json:"proxy,omitempty"` // proxy used, empty when the registry is reached directly
+}
+
+// ProxyProposal is the proxy configuration detected for a package manager
+type ProxyProposal struct {
+	Manager  string        `json:"manager"`
+	Current  ProxySettings `json:"current"`
+	Proposed ProxySettings `json:"proposed"`
+	Routes   []ProxyRoute  `json:"routes,omitempty"`
+	Warnings []string      `json:"warnings,omitempty"`
+	Changed  bool          `json:"changed"`
+	Applied  bool          `json:"applied"`
+}
+
+// ProxyDetection is the outcome of detecting proxy settings from the
+// environment or a PAC file
+type ProxyDetection struct {
+	Source      string          `json:"source"` // "environment" or the PAC file evaluated
+	Environment ProxySettings   `json:"environment"`
+	Proposals   []ProxyProposal `json:"proposals"`
+}
+
+// ParseNoProxy splits a comma or space separated bypass list, as used by
+// NO_PROXY and npm's noproxy setting
+func ParseNoProxy(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// ConfigLayer is where a config value comes from
+type ConfigLayer string
+
+// Config layers, from lowest to highest precedence
+const (
+	LayerBuiltin ConfigLayer = "builtin"
+	LayerGlobal  ConfigLayer = "global"
+	LayerUser    ConfigLayer = "user"
+	LayerProject ConfigLayer = "project"
+	LayerEnv     ConfigLayer = "env"
+	LayerCLI     ConfigLayer = "cli"
+)
+
+// ConfigSource is the value a config key has in one layer
+type ConfigSource struct {
+	Layer  ConfigLayer `json:"layer"`
+	Source string      `json:"source,omitempty"` // file path or environment variable
+	Value  string      `json:"value"`
+}
+
+// ConfigEntry is the effective value of a config key, where it comes from
+// and the lower precedence values it shadows, highest first
+type ConfigEntry struct {
+	Manager  string         `json:"manager"`
+	Key      string         `json:"key"`
+	Value    string         `json:"value"`
+	Layer    ConfigLayer    `json:"layer"`
+	Source   string         `json:"source,omitempty"`
+	Shadowed []ConfigSource `json:"shadowed,omitempty"`
+}
+
+// ConfigSnapshot is a saved copy of the package managers' user config files
+type ConfigSnapshot struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Reason    string    `json:"reason"`
+	Files     []string  `json:"files"`
+}
+
+// ConfigChange is a config key that differs between two snapshots
+type ConfigChange struct {
+	Path   string `json:"path"`
+	Key    string `json:"key"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// ConfigDiff lists the config changes from one snapshot to another
+type ConfigDiff struct {
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Changes []ConfigChange `json:"changes"`
+}
+
+// ConfigRollback describes the restore of a config snapshot
+type ConfigRollback struct {
+	Snapshot string   `json:"snapshot"` // snapshot restored
+	Backup   string   `json:"backup"`   // snapshot taken before the rollback, to undo it
+	Restored []string `json:"restored"` // config files rewritten
+}
+
+// ConfigPlanChange is a config key that must change to bring a manager to
+// the desired config
+type ConfigPlanChange struct {
+	Manager string `json:"manager"`
+	Key     string `json:"key"`
+	Current string `json:"current,omitempty"`
+	Desired string `json:"desired,omitempty"` // empty removes the key
+}
+
+// ConfigPlan lists the changes that converge the managers to a desired config
+type ConfigPlan struct {
+	Changes  []ConfigPlanChange `json:"changes"`
+	Skipped  []string           `json:"skipped,omitempty"`  // managers in the desired config that are not available
+	Snapshot string             `json:"snapshot,omitempty"` // snapshot taken before the plan was applied
+}
+
+// ConfigDrift reports the config keys where the package managers differ from
+// the config npm-console stores for them
+type ConfigDrift struct {
+	InSync  bool               `json:"in_sync"`
+	Drift   []ConfigPlanChange `json:"drift"`
+	Skipped []string           `json:"skipped,omitempty"` // enabled managers that are not available
+}
+
+// RegistryTestResult represents the outcome of probing a registry
+type RegistryTestResult struct {
+	Manager       string   `json:"manager"`
+	Registry      string   `json:"registry"`
+	Proxy         string   `json:"proxy,omitempty"`
+	Success       bool     `json:"success"`
+	StatusCode    int      `json:"status_code,omitempty"`     // status of the packument request
+	PingStatus    int      `json:"ping_status,omitempty"`     // status of the /-/ping request
+	LatencyMs     int64    `json:"latency_ms"`                // packument request latency
+	PingLatencyMs int64    `json:"ping_latency_ms,omitempty"` // /-/ping request latency
+	AuthFailed    bool     `json:"auth_failed,omitempty"`
+	TLS           *TLSInfo `json:"tls,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// ProxyTestResult represents the outcome of reaching a registry through a proxy
+type ProxyTestResult struct {
+	Manager    string   `json:"manager"`
+	Proxy      string   `json:"proxy"`
+	Target     string   `json:"target"`
+	Success    bool     `json:"success"`
+	StatusCode int      `json:"status_code,omitempty"`
+	LatencyMs  int64    `json:"latency_ms"`
+	AuthFailed bool     `json:"auth_failed,omitempty"`
+	TLS        *TLSInfo `json:"tls,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// RegistryMirror represents a named registry candidate
+type RegistryMirror struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+// RegistryBenchResult represents the measurements of a registry benchmark
+type RegistryBenchResult struct {
+	Name         string  `json:"name"`
+	URL          string  `json:"url"`
+	Attempts     int     `json:"attempts"`
+	Successes    int     `json:"successes"`
+	SuccessRate  float64 `json:"success_rate"`
+	AvgLatencyMs int64   `json:"avg_latency_ms"`
+	MinLatencyMs int64   `json:"min_latency_ms"`
+	MaxLatencyMs int64   `json:"max_latency_ms"`
+	Error        string  `json:"error,omitempty"` // last failure, if any
+}
+
+// RegistryProfile represents a named set of registry settings that can be
+// applied to all package managers at once
+type RegistryProfile struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Registry    string            `json:"registry"`
+	Managers    map[string]string `json:"managers,omitempty"` // manager -> registry override
+	Scopes      map[string]string `json:"scopes,omitempty"`   // "@scope" -> registry URL
+}
+
+// RegistryFor returns the registry the profile assigns to a manager
+func (p *RegistryProfile) RegistryFor(manager string) string {
+	if registry, ok := p.Managers[manager]; ok && registry != "" {
+		return registry
+	}
+	return p.Registry
+}
+
+// ScopedRegistry represents a package scope routed to its own registry
+type ScopedRegistry struct {
+	Manager  string `json:"manager"`
+	Scope    string `json:"scope"`
+	Registry string `json:"registry"`
+}
+
+// Credential describes the auth token configured for a registry. Only a
+// masked form of the token is ever exposed.
+type Credential struct {
+	Manager  string   `json:"manager"`
+	Registry string   `json:"registry"`
+	Scopes   []string `json:"scopes,omitempty"`  // scopes routed to this registry
+	HasToken bool     `json:"has_token"`
+	Token    string   `json:"token,omitempty"`   // masked token
+	EnvVar   string   `json:"env_var,omitempty"` // variable the token is read from
+	Source   string   `json:"source,omitempty"`  // file holding the token
+}
+
+// TLSInfo describes the TLS connection negotiated with a server
+type TLSInfo struct {
+	Version     string    `json:"version"`
+	CipherSuite string    `json:"cipher_suite"`
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	NotAfter    time.Time `json:"not_after"`
 }
 
 // Project represents a project using package managers
@@ -61,7 +457,7 @@ type ProjectAnalysis struct {
 	PackageCount     int               `json:"package_count"`
 	DevPackageCount  int               `json:"dev_package_count"`
 	TotalSize        int64             `json:"total_size"`
-	OutdatedPackages []Package         `json:"outdated_packages"`
+	OutdatedPackages []OutdatedPackage `json:"outdated_packages"`
 	Vulnerabilities  []Vulnerability   `json:"vulnerabilities"`
 	Scripts          map[string]string `json:"scripts"`
 }
@@ -73,16 +469,33 @@ type DependencyTree struct {
 	Dependencies []*DependencyTree `json:"dependencies,omitempty"`
 	DevDependency bool             `json:"dev_dependency"`
 	Depth        int               `json:"depth"`
+	Deduped      bool              `json:"deduped,omitempty"`
+	Missing      bool              `json:"missing,omitempty"`
+}
+
+// OutdatedPackage represents a dependency with a newer version available
+type OutdatedPackage struct {
+	Name           string            `json:"name"`
+	Current        string            `json:"current"`
+	Wanted         string            `json:"wanted"`
+	Latest         string            `json:"latest"`
+	Range          string            `json:"range"`
+	DependencyType string            `json:"dependency_type"`
+	Manager        string            `json:"manager"`
+	Project        string            `json:"project"`
+	DistTags       map[string]string `json:"dist_tags,omitempty"`
 }
 
 // Vulnerability represents a security vulnerability
 type Vulnerability struct {
+	ID          string `json:"id,omitempty"`
 	Package     string `json:"package"`
 	Version     string `json:"version"`
 	Severity    string `json:"severity"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	FixedIn     string `json:"fixed_in"`
+	URL         string `json:"url,omitempty"`
 }
 
 // ManagerType represents the type of package manager
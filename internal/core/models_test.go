@@ -223,3 +223,26 @@ func TestDependencyTree(t *testing.T) {
 		t.Errorf("Dev dependency name = %v, want %v", devDep.Name, "typescript")
 	}
 }
+
+func TestRegistryProfileRegistryFor(t *testing.T) {
+	profile := RegistryProfile{
+		Name:     "corp",
+		Registry: "https://npm.corp.local/",
+		Managers: map[string]string{
+			"yarn": "https://yarn.corp.local/",
+			"bun":  "",
+		},
+	}
+
+	tests := map[string]string{
+		"npm":  "https://npm.corp.local/",
+		"yarn": "https://yarn.corp.local/",
+		"bun":  "https://npm.corp.local/",
+	}
+
+	for manager, expected := range tests {
+		if got := profile.RegistryFor(manager); got != expected {
+			t.Errorf("RegistryFor(%s) = %s, want %s", manager, got, expected)
+		}
+	}
+}
@@ -2,14 +2,15 @@ package managers
 
 import (
 	"context"
+	"errors"
 	"encoding/json"
-	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"time"
 
 	"npm-console/internal/core"
+	"npm-console/internal/registry"
 	"npm-console/pkg/logger"
 	"npm-console/pkg/utils"
 )
@@ -115,7 +116,17 @@ func (b *BunManager) GetInstalledPackages(ctx context.Context, projectPath strin
 		return nil, core.ErrProjectNotFound
 	}
 
-	// Bun doesn't have a list command yet, read from package.json
+	// Bun doesn't have a list command yet, so read resolved versions from its lockfile
+	lockfile, err := LoadLockfile(projectPath)
+	if err == nil && lockfile.Manager == "bun" {
+		if importer := lockfile.Importer(projectPath); importer != nil {
+			return lockfile.ImporterPackages(importer, projectPath), nil
+		}
+	} else if err != nil && !errors.Is(err, core.ErrLockfileNotFound) {
+		b.logger.WithError(err).Warn("Failed to parse lockfile, falling back to package.json")
+	}
+
+	// Fallback to reading package.json
 	return b.getPackagesFromPackageJson(packageJsonPath)
 }
 
@@ -126,49 +137,111 @@ func (b *BunManager) GetGlobalPackages(ctx context.Context) ([]core.Package, err
 	return []core.Package{}, nil
 }
 
-// GetConfig returns the current bun configuration
-func (b *BunManager) GetConfig(ctx context.Context) (*core.Config, error) {
-	config := &core.Config{
-		Manager:  "bun",
-		Settings: make(map[string]string),
-	}
+// configFiles returns the bunfig.toml files bun reads from the working directory
+func (b *BunManager) configFiles() []ConfigFile {
+	return ConfigFiles("bun", ".", false)
+}
 
-	// Try to read bunfig.toml if it exists
-	bunfigPath := filepath.Join(".", "bunfig.toml")
-	if utils.IsFile(bunfigPath) {
-		// For now, just note that bunfig.toml exists
-		config.Settings["bunfig"] = bunfigPath
+// GetConfig returns the effective bun configuration for the working directory
+func (b *BunManager) GetConfig(ctx context.Context) (*core.Config, error) {
+	entries, err := b.ExplainConfig(ctx, ".", nil)
+	if err != nil {
+		return nil, err
 	}
+	config := configFromEntries("bun", entries, "cache")
 
-	// Check for global bunfig.toml
-	home, err := utils.GetHomeDir()
-	if err == nil {
-		globalBunfig := filepath.Join(home, ".bunfig.toml")
-		if utils.IsFile(globalBunfig) {
-			config.Settings["global-bunfig"] = globalBunfig
+	for _, file := range b.configFiles() {
+		if !file.Exists() {
+			continue
+		}
+		switch file.Level {
+		case LevelProject:
+			config.Settings["bunfig"] = file.Path
+		case LevelUser:
+			config.Settings["global-bunfig"] = file.Path
 		}
 	}
 
-	// Bun uses npm registry by default
-	config.Registry = "https://registry.npmjs.org/"
-
 	return config, nil
 }
 
-// SetRegistry sets the bun registry URL
+// ExplainConfig resolves bun's config keys through the command line,
+// environment variables, the project and user bunfig.toml and bun's defaults
+func (b *BunManager) ExplainConfig(ctx context.Context, projectPath string, cli map[string]string) ([]core.ConfigEntry, error) {
+	builtin := builtinConfig(map[string]string{
+		"registry": registry.DefaultRegistry,
+		"cache":    b.getDefaultCachePath(),
+	})
+
+	return explainConfig("bun", ConfigFiles("bun", projectPath, false), envConfig("bun", false), builtin, cli), nil
+}
+
+// SetRegistry sets install.registry in the user bunfig.toml, keeping its token
 func (b *BunManager) SetRegistry(ctx context.Context, url string) error {
-	// Bun doesn't have a config set command yet
-	// This would need to be implemented by modifying bunfig.toml
-	b.logger.WithField("registry", url).Warn("bun registry configuration not yet supported")
-	return core.NewManagerError("bun", "set registry", fmt.Errorf("registry configuration not supported"))
+	if err := setConfigValue(b.configFiles(), "registry", url); err != nil {
+		return core.NewManagerError("bun", "set registry", err)
+	}
+
+	b.logger.WithField("registry", url).Info("bun registry updated")
+	return nil
+}
+
+// SetProxy records the proxies and bypass list as install.proxy,
+// install.https-proxy and install.noproxy in the user bunfig.toml; empty
+// values remove them. Bun itself takes them from the HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY environment variables.
+func (b *BunManager) SetProxy(ctx context.Context, proxy core.ProxySettings) error {
+	if err := setProxyValues(b.configFiles(), proxy); err != nil {
+		return core.NewManagerError("bun", "set proxy", err)
+	}
+
+	b.logger.WithField("proxy", utils.RedactURL(proxy.HTTP)).WithField("https_proxy", utils.RedactURL(proxy.HTTPS)).WithField("no_proxy", proxy.NoProxy).Info("bun proxy updated")
+	return nil
+}
+
+// GetScopedRegistries returns the [install.scopes] mappings of the bunfig.toml files
+func (b *BunManager) GetScopedRegistries(ctx context.Context) (map[string]string, error) {
+	return scopedRegistriesFromFiles(b.configFiles()), nil
 }
 
-// SetProxy sets the bun proxy configuration
-func (b *BunManager) SetProxy(ctx context.Context, proxy string) error {
-	// Bun doesn't have built-in proxy configuration
-	// This would need to be implemented by modifying bunfig.toml
-	b.logger.WithField("proxy", proxy).Warn("bun proxy configuration not yet supported")
-	return core.NewManagerError("bun", "set proxy", fmt.Errorf("proxy configuration not supported"))
+// SetScopedRegistry maps a scope to a registry in the user bunfig.toml; an
+// empty URL removes the mapping
+func (b *BunManager) SetScopedRegistry(ctx context.Context, scope string, url string) error {
+	if err := setConfigValue(b.configFiles(), NormalizeScope(scope)+":registry", url); err != nil {
+		return core.NewManagerError("bun", "set scoped registry", err)
+	}
+
+	b.logger.WithField("scope", NormalizeScope(scope)).WithField("registry", url).Info("bun scoped registry updated")
+	return nil
+}
+
+// SetConfigValue sets a key in the user config file; an empty value removes it
+func (b *BunManager) SetConfigValue(ctx context.Context, key string, value string) error {
+	if err := setConfigValue(b.configFiles(), key, value); err != nil {
+		return core.NewManagerError("bun", "set config", err)
+	}
+
+	b.logger.WithField("key", key).Info("bun config updated")
+	return nil
+}
+
+// GetCredentials returns the registries of the user bunfig.toml with masked tokens
+func (b *BunManager) GetCredentials(ctx context.Context) ([]core.Credential, error) {
+	credentials, err := bunfigCredentials()
+	if err != nil {
+		return nil, core.NewManagerError("bun", "read credentials", err)
+	}
+	return credentials, nil
+}
+
+// SetAuthToken stores a registry token on the matching bunfig.toml registry entries
+func (b *BunManager) SetAuthToken(ctx context.Context, registry string, token string, envVar string) error {
+	if err := setBunfigAuthToken(registry, token, envVar); err != nil {
+		return core.NewManagerError("bun", "set auth token", err)
+	}
+
+	b.logger.WithField("registry", registry).Info("bun auth token updated")
+	return nil
 }
 
 // GetProjects scans for bun projects
@@ -180,8 +253,10 @@ func (b *BunManager) GetProjects(ctx context.Context, rootPath string) ([]core.P
 			return nil // Continue walking
 		}
 
-		// Look for bun.lockb files
-		if info.Name() == "bun.lockb" && utils.IsFile(path) {
+		// Look for bun.lock files, or bun.lockb in projects that have not migrated yet
+		isLockfile := info.Name() == "bun.lock" ||
+			(info.Name() == "bun.lockb" && !utils.IsFile(filepath.Join(filepath.Dir(path), "bun.lock")))
+		if isLockfile && utils.IsFile(path) {
 			projectPath := filepath.Dir(path)
 			packageJsonPath := filepath.Join(projectPath, "package.json")
 			
@@ -0,0 +1,273 @@
+package managers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"npm-console/internal/core"
+	"npm-console/pkg/utils"
+)
+
+// bunLockfile mirrors the text bun.lock format
+type bunLockfile struct {
+	LockfileVersion int                          `json:"lockfileVersion"`
+	Workspaces      map[string]bunWorkspace      `json:"workspaces"`
+	Packages        map[string][]json.RawMessage `json:"packages"`
+}
+
+// bunWorkspace is an entry of the "workspaces" map, keyed by folder ("" is the root)
+type bunWorkspace struct {
+	Name                 string            `json:"name"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// bunPackageInfo is the metadata object of a "packages" entry
+type bunPackageInfo struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// ParseBunLockfile parses a text bun.lock file, or a binary bun.lockb file
+// on a best-effort basis
+func ParseBunLockfile(lockPath string) (*Lockfile, error) {
+	if filepath.Ext(lockPath) == ".lockb" {
+		return parseBunBinaryLockfile(lockPath)
+	}
+
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return nil, core.NewManagerError("bun", "read lockfile", err)
+	}
+
+	var raw bunLockfile
+	if err := json.Unmarshal(stripJSONC(data), &raw); err != nil {
+		return nil, core.NewManagerError("bun", "parse lockfile", err)
+	}
+
+	lockfile := newLockfile("bun", lockPath, strconv.Itoa(raw.LockfileVersion))
+
+	// Packages are keyed by their install path ("a/c" is c nested under a).
+	// Workspace members are listed as packages too but tracked as importers.
+	workspaceKeys := make(map[string]bool)
+	for key, fields := range raw.Packages {
+		if len(fields) == 0 {
+			continue
+		}
+
+		var ident string
+		if err := json.Unmarshal(fields[0], &ident); err != nil {
+			continue
+		}
+		name, version := splitPackageSpec(ident)
+		if strings.HasPrefix(version, "workspace:") {
+			workspaceKeys[key] = true
+			continue
+		}
+
+		locked := &LockedPackage{
+			ID:           key,
+			Name:         name,
+			Version:      version,
+			Dependencies: make(map[string]string),
+		}
+		if len(fields) > 1 {
+			_ = json.Unmarshal(fields[1], &locked.Resolved)
+		}
+		if len(fields) > 3 {
+			_ = json.Unmarshal(fields[len(fields)-1], &locked.Integrity)
+		}
+
+		var info bunPackageInfo
+		for _, field := range fields[1:] {
+			if bytes.HasPrefix(bytes.TrimSpace(field), []byte("{")) {
+				_ = json.Unmarshal(field, &info)
+				break
+			}
+		}
+		for _, deps := range []map[string]string{info.Dependencies, info.OptionalDependencies, info.PeerDependencies} {
+			for depName := range deps {
+				locked.Dependencies[depName] = key
+			}
+		}
+
+		lockfile.Packages[key] = locked
+	}
+
+	// Resolve dependency names to package keys now that all keys are known
+	for key, locked := range lockfile.Packages {
+		for depName := range locked.Dependencies {
+			if id := resolveBunDependency(lockfile.Packages, workspaceKeys, key, depName); id != "" {
+				locked.Dependencies[depName] = id
+			} else {
+				delete(locked.Dependencies, depName)
+			}
+		}
+	}
+
+	for path, workspace := range raw.Workspaces {
+		importer := &LockImporter{
+			Path:                 path,
+			Dependencies:         make(map[string]string),
+			DevDependencies:      make(map[string]string),
+			OptionalDependencies: make(map[string]string),
+		}
+
+		// Dependencies that conflict with the root are installed under the workspace name
+		prefix := workspace.Name
+		if path == "" {
+			importer.Path = RootImporter
+			prefix = ""
+		}
+
+		sections := []struct {
+			deps   map[string]string
+			target map[string]string
+		}{
+			{workspace.Dependencies, importer.Dependencies},
+			{workspace.DevDependencies, importer.DevDependencies},
+			{workspace.OptionalDependencies, importer.OptionalDependencies},
+		}
+		for _, section := range sections {
+			for name := range section.deps {
+				if id := resolveBunDependency(lockfile.Packages, workspaceKeys, prefix, name); id != "" {
+					section.target[name] = id
+				}
+			}
+		}
+
+		lockfile.Importers[importer.Path] = importer
+	}
+
+	return lockfile, nil
+}
+
+// resolveBunDependency finds which package key name resolves to from the package
+// at key, walking up the install path like node's module resolution
+func resolveBunDependency(packages map[string]*LockedPackage, workspaceKeys map[string]bool, key, name string) string {
+	prefix := key
+	for {
+		candidate := name
+		if prefix != "" {
+			candidate = prefix + "/" + name
+		}
+
+		if workspaceKeys[candidate] {
+			return ""
+		}
+		if _, ok := packages[candidate]; ok {
+			return candidate
+		}
+
+		if prefix == "" {
+			return ""
+		}
+		prefix = bunParentKey(prefix)
+	}
+}
+
+// bunParentKey drops the last package name (which may be scoped) from an install path
+func bunParentKey(key string) string {
+	segments := strings.Split(key, "/")
+	segments = segments[:len(segments)-1]
+	if len(segments) > 0 && strings.HasPrefix(segments[len(segments)-1], "@") {
+		segments = segments[:len(segments)-1]
+	}
+	return strings.Join(segments, "/")
+}
+
+// parseBunBinaryLockfile reads a binary bun.lockb by asking bun to print it,
+// which produces a yarn v1 compatible lockfile
+func parseBunBinaryLockfile(lockPath string) (*Lockfile, error) {
+	if !utils.IsCommandAvailable("bun") {
+		return nil, core.NewManagerError("bun", "read lockfile", fmt.Errorf("bun is required to read %s", filepath.Base(lockPath)))
+	}
+
+	result := utils.ExecuteCommandWithTimeout(30*time.Second, "bun", lockPath)
+	if result.Error != nil {
+		return nil, core.NewManagerError("bun", "read lockfile", result.Error)
+	}
+
+	return parseYarnV1Lockfile("bun", lockPath, []byte(result.Stdout))
+}
+
+// stripJSONC removes comments and trailing commas so JSONC can be decoded as JSON
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case c == ',':
+			// Drop the comma if the next significant character closes the container
+			if next := nextJSONCToken(data[i+1:]); next == '}' || next == ']' {
+				continue
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
+
+// nextJSONCToken returns the next character that is not whitespace or part of a comment
+func nextJSONCToken(data []byte) byte {
+	for {
+		data = bytes.TrimLeft(data, " \t\r\n")
+		switch {
+		case len(data) == 0:
+			return 0
+		case bytes.HasPrefix(data, []byte("//")):
+			end := bytes.IndexByte(data, '\n')
+			if end < 0 {
+				return 0
+			}
+			data = data[end:]
+		case bytes.HasPrefix(data, []byte("/*")):
+			end := bytes.Index(data[2:], []byte("*/"))
+			if end < 0 {
+				return 0
+			}
+			data = data[end+4:]
+		default:
+			return data[0]
+		}
+	}
+}
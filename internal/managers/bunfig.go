@@ -0,0 +1,402 @@
+package managers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"npm-console/internal/registry"
+	"npm-console/pkg/utils"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// bunfig.toml tables npm-console edits
+const (
+	bunInstallHeader = "[install]"
+	bunScopesHeader  = "[install.scopes]"
+	bunCacheHeader   = "[install.cache]"
+)
+
+// BunfigPath returns the user-level bunfig.toml bun reads its global settings from
+func BunfigPath() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		path := filepath.Join(xdg, ".bunfig.toml")
+		if utils.IsFile(path) {
+			return path, nil
+		}
+	}
+
+	home, err := utils.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".bunfig.toml"), nil
+}
+
+// bunfig mirrors the registry related parts of bunfig.toml
+type bunfig struct {
+	Install struct {
+		Registry interface{}            `toml:"registry"`
+		Scopes   map[string]interface{} `toml:"scopes"`
+	} `toml:"install"`
+}
+
+// bunRegistry is a registry entry of bunfig.toml, written either as a URL or
+// as an inline table with url and token keys
+type bunRegistry struct {
+	URL   string
+	Token string
+}
+
+// parseBunRegistry reads a registry entry decoded from bunfig.toml
+func parseBunRegistry(value interface{}) (bunRegistry, bool) {
+	switch entry := value.(type) {
+	case string:
+		return bunRegistry{URL: entry}, true
+	case map[string]interface{}:
+		url, _ := entry["url"].(string)
+		token, _ := entry["token"].(string)
+		return bunRegistry{URL: url, Token: token}, url != ""
+	}
+	return bunRegistry{}, false
+}
+
+// parseBunRegistryValue parses the right-hand side of a bunfig.toml line
+func parseBunRegistryValue(raw string) (bunRegistry, bool) {
+	var line map[string]interface{}
+	if err := toml.Unmarshal([]byte("v = "+raw), &line); err != nil {
+		return bunRegistry{}, false
+	}
+	return parseBunRegistry(line["v"])
+}
+
+// String renders the entry as a bunfig.toml value
+func (r bunRegistry) String() string {
+	if r.Token == "" {
+		return fmt.Sprintf("%q", r.URL)
+	}
+	return fmt.Sprintf("{ url = %q, token = %q }", r.URL, r.Token)
+}
+
+// readBunfig parses a bunfig.toml; a missing file yields an empty config
+func readBunfig(path string) (*bunfig, error) {
+	var cfg bunfig
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &cfg, nil
+		}
+		return nil, err
+	}
+
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// readBunfigValues flattens the [install] settings of a bunfig.toml into npm
+// style keys: scopes become "@scope:registry" and registry tokens
+// "//host/path/:_authToken"
+func readBunfigValues(data []byte) (map[string]string, error) {
+	var doc map[string]interface{}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	install, _ := doc["install"].(map[string]interface{})
+	for key, value := range install {
+		switch key {
+		case "registry":
+			if entry, ok := parseBunRegistry(value); ok {
+				values["registry"] = entry.URL
+				if entry.Token != "" {
+					values["_authToken"] = entry.Token
+				}
+			}
+		case "scopes":
+			scopes, _ := value.(map[string]interface{})
+			for name, scope := range scopes {
+				if entry, ok := parseBunRegistry(scope); ok {
+					values[NormalizeScope(name)+":registry"] = entry.URL
+					if entry.Token != "" {
+						values[NerfDart(entry.URL)+":_authToken"] = entry.Token
+					}
+				}
+			}
+		case "cache":
+			switch cache := value.(type) {
+			case string:
+				values["cache"] = cache
+			case map[string]interface{}:
+				if dir, ok := cache["dir"].(string); ok {
+					values["cache"] = dir
+				}
+			}
+		default:
+			switch value.(type) {
+			case string, bool, int64, float64:
+				values[key] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// setBunfigValue sets an npm style key in a bunfig.toml in place. The
+// registry, scopes and tokens map to bun's registry entries, cache to
+// [install.cache] dir and anything else, proxy included, to an [install]
+// key. An empty value removes the key.
+func setBunfigValue(path, key, value string) error {
+	switch {
+	case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+		return setBunfigScope(path, strings.TrimSuffix(key, ":registry"), value)
+	case strings.HasPrefix(key, "//") && strings.HasSuffix(key, ":_authToken"):
+		return setBunfigToken(path, "https:"+strings.TrimSuffix(key, ":_authToken"), value)
+	case key == "_authToken":
+		cfg, err := readBunfig(path)
+		if err != nil {
+			return err
+		}
+		registryURL := registry.DefaultRegistry
+		if entry, ok := parseBunRegistry(cfg.Install.Registry); ok {
+			registryURL = entry.URL
+		}
+		return setBunfigToken(path, registryURL, value)
+	}
+
+	file, err := openBunfig(path)
+	if err != nil {
+		return err
+	}
+
+	switch key {
+	case "registry":
+		// Keep the token of an existing entry
+		entry := bunRegistry{URL: value}
+		file.entries(bunInstallHeader, func(i int, key, raw string) {
+			if existing, ok := parseBunRegistryValue(raw); key == "registry" && ok {
+				entry.Token = existing.Token
+			}
+		})
+		if value == "" {
+			file.put(bunInstallHeader, "registry", "")
+		} else {
+			file.put(bunInstallHeader, "registry", entry.String())
+		}
+	case "cache":
+		file.put(bunCacheHeader, "dir", quoteBunValue(value))
+	default:
+		file.put(bunInstallHeader, key, quoteBunValue(value))
+	}
+
+	return file.save()
+}
+
+// quoteBunValue renders a string as a TOML value, or "" to remove it
+func quoteBunValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// bunfigFile holds the lines of a bunfig.toml being edited
+type bunfigFile struct {
+	path  string
+	lines []string
+}
+
+// openBunfig reads a bunfig.toml for line-based editing
+func openBunfig(path string) (*bunfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	file := &bunfigFile{path: path}
+	if len(data) > 0 {
+		file.lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+	return file, nil
+}
+
+// section returns the first line after a table header and the index where the
+// table ends, or -1 when the table is missing
+func (f *bunfigFile) section(header string) (int, int) {
+	start := -1
+	for i, line := range f.lines {
+		trimmed := strings.TrimSpace(line)
+		if start < 0 {
+			if trimmed == header {
+				start = i + 1
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") {
+			return start, i
+		}
+	}
+	return start, len(f.lines)
+}
+
+// entries calls fn with the line index, key and raw value of each entry in a table
+func (f *bunfigFile) entries(header string, fn func(i int, key, value string)) {
+	start, end := f.section(header)
+	if start < 0 {
+		return
+	}
+	for i := start; i < end; i++ {
+		trimmed := strings.TrimSpace(f.lines[i])
+		if strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if key, value, ok := strings.Cut(trimmed, "="); ok {
+			fn(i, strings.Trim(strings.TrimSpace(key), `"'`), strings.TrimSpace(value))
+		}
+	}
+}
+
+// insert adds a line at the end of a table, creating the table if needed
+func (f *bunfigFile) insert(header, line string) {
+	start, end := f.section(header)
+	if start < 0 {
+		if len(f.lines) > 0 {
+			f.lines = append(f.lines, "")
+		}
+		f.lines = append(f.lines, header, line)
+		return
+	}
+
+	// Insert after the last non-blank line of the table
+	at := end
+	for at > start && strings.TrimSpace(f.lines[at-1]) == "" {
+		at--
+	}
+	f.lines = append(f.lines[:at], append([]string{line}, f.lines[at:]...)...)
+}
+
+// setValue replaces the value of the entry on line i, keeping its key as written
+func (f *bunfigFile) setValue(i int, value string) {
+	key, _, _ := strings.Cut(f.lines[i], "=")
+	f.lines[i] = strings.TrimRight(key, " ") + " = " + value
+}
+
+// put sets the raw value of key in a table, adding the entry if needed; an
+// empty value removes it
+func (f *bunfigFile) put(header, key, value string) {
+	found := -1
+	f.entries(header, func(i int, k, _ string) {
+		if k == key {
+			found = i
+		}
+	})
+
+	switch {
+	case found >= 0 && value == "":
+		f.remove(found)
+	case found >= 0:
+		f.setValue(found, value)
+	case value != "":
+		f.insert(header, key+" = "+value)
+	}
+}
+
+// remove deletes a line
+func (f *bunfigFile) remove(i int) {
+	f.lines = append(f.lines[:i], f.lines[i+1:]...)
+}
+
+// save writes the edited lines back
+func (f *bunfigFile) save() error {
+	if err := utils.MakeDir(filepath.Dir(f.path)); err != nil {
+		return err
+	}
+	// bunfig.toml can hold registry tokens
+	return utils.WriteFileAtomic(f.path, []byte(strings.Join(f.lines, "\n")+"\n"), 0600)
+}
+
+// setBunfigScope updates one entry of [install.scopes] in place, leaving the
+// rest of the file, comments included, untouched. An empty URL removes the
+// entry; an existing token is kept.
+func setBunfigScope(path, scope, url string) error {
+	scope = NormalizeScope(scope)
+
+	file, err := openBunfig(path)
+	if err != nil {
+		return err
+	}
+
+	found := -1
+	entry := bunRegistry{URL: url}
+	file.entries(bunScopesHeader, func(i int, key, value string) {
+		if NormalizeScope(key) == scope {
+			found = i
+			if existing, ok := parseBunRegistryValue(value); ok {
+				entry.Token = existing.Token
+			}
+		}
+	})
+
+	switch {
+	case found >= 0 && url == "":
+		file.remove(found)
+	case found >= 0:
+		file.setValue(found, entry.String())
+	case url == "":
+		return nil
+	default:
+		file.insert(bunScopesHeader, fmt.Sprintf("%q = %s", scope, entry))
+	}
+
+	return file.save()
+}
+
+// setBunfigToken sets the token of every bunfig.toml registry entry (the
+// default registry and scopes) that points at registry. Bun has no
+// per-host credentials, so a token for the default npm registry creates an
+// install.registry entry when none exists; other registries must already be
+// referenced. An empty token removes it.
+func setBunfigToken(path, registryURL, token string) error {
+	file, err := openBunfig(path)
+	if err != nil {
+		return err
+	}
+
+	target := NerfDart(registryURL)
+	matched := false
+
+	update := func(i int, key, value string) {
+		entry, ok := parseBunRegistryValue(value)
+		if !ok || NerfDart(entry.URL) != target {
+			return
+		}
+		entry.Token = token
+		file.setValue(i, entry.String())
+		matched = true
+	}
+
+	file.entries(bunInstallHeader, func(i int, key, value string) {
+		if key == "registry" {
+			update(i, key, value)
+		}
+	})
+	file.entries(bunScopesHeader, update)
+
+	if !matched {
+		if token == "" {
+			return nil
+		}
+		if target != NerfDart(registry.DefaultRegistry) {
+			return fmt.Errorf("no install.registry or install.scopes entry uses %s", registryURL)
+		}
+		file.insert(bunInstallHeader, "registry = "+bunRegistry{URL: registry.DefaultRegistry, Token: token}.String())
+	}
+
+	return file.save()
+}
@@ -0,0 +1,72 @@
+package managers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bunfigScopes reads the scoped registries of one bunfig.toml
+func bunfigScopes(path string) map[string]string {
+	return scopedRegistriesFromFiles([]ConfigFile{{Manager: "bun", Format: FormatBunfig, Path: path}})
+}
+
+func TestSetBunfigScope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bunfig.toml")
+	os.WriteFile(path, []byte(`# user settings
+[install]
+registry = "https://registry.npmjs.org/"
+
+[install.scopes]
+# private packages
+"@corp" = { url = "https://old.corp.local/", token = "$CORP_TOKEN" }
+
+[run]
+bun = true
+`), 0644)
+
+	if err := setBunfigScope(path, "corp", "https://npm.corp.local/"); err != nil {
+		t.Fatalf("setBunfigScope() error = %v", err)
+	}
+	if err := setBunfigScope(path, "@other", "https://other.local/"); err != nil {
+		t.Fatalf("setBunfigScope() error = %v", err)
+	}
+
+	scopes := bunfigScopes(path)
+	if scopes["@corp"] != "https://npm.corp.local/" || scopes["@other"] != "https://other.local/" {
+		t.Errorf("scopes = %v", scopes)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	for _, kept := range []string{"# user settings", "# private packages", `token = "$CORP_TOKEN"`, "[run]"} {
+		if !strings.Contains(content, kept) {
+			t.Errorf("bunfig.toml lost %q:\n%s", kept, content)
+		}
+	}
+	if strings.Index(content, `"@other"`) > strings.Index(content, "[run]") {
+		t.Errorf("new scope was not added to [install.scopes]:\n%s", content)
+	}
+
+	if err := setBunfigScope(path, "@corp", ""); err != nil {
+		t.Fatalf("setBunfigScope() error = %v", err)
+	}
+	scopes = bunfigScopes(path)
+	if _, ok := scopes["@corp"]; ok || len(scopes) != 1 {
+		t.Errorf("scopes after removal = %v", scopes)
+	}
+}
+
+func TestSetBunfigScopeNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bunfig.toml")
+
+	if err := setBunfigScope(path, "@corp", "https://npm.corp.local/"); err != nil {
+		t.Fatalf("setBunfigScope() error = %v", err)
+	}
+
+	scopes := bunfigScopes(path)
+	if scopes["@corp"] != "https://npm.corp.local/" {
+		t.Errorf("scopes = %v", scopes)
+	}
+}
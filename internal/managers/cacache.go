@@ -0,0 +1,271 @@
+package managers
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"npm-console/internal/core"
+)
+
+// npm keeps its HTTP cache in cacache: index-v5 holds one bucket file per
+// request key, content-v2 the response bodies named by their integrity
+const (
+	cacacheIndexDir   = "index-v5"
+	cacacheContentDir = "content-v2"
+
+	// fetchCachePrefix starts the keys npm's fetch cache stores responses under
+	fetchCachePrefix = "make-fetch-happen:request-cache:"
+)
+
+// cacacheLine is an entry of an index bucket. A null integrity marks the
+// key as deleted.
+type cacacheLine struct {
+	Key       string          `json:"key"`
+	Integrity *string         `json:"integrity"`
+	Time      int64           `json:"time"`
+	Size      int64           `json:"size"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+
+	raw string // the line as written, hash included
+}
+
+// cacacheBucketPath returns the index bucket holding a key
+func cacacheBucketPath(root, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])
+	return filepath.Join(root, cacacheIndexDir, hash[:2], hash[2:4], hash[4:])
+}
+
+// cacacheContentPath returns the content file for an integrity string such
+// as "sha512-<base64>". Of several hashes the strongest one present is used.
+func cacacheContentPath(root, integrity string) string {
+	var candidates []string
+	for _, algo := range []string{"sha512", "sha384", "sha256", "sha1"} {
+		for _, field := range strings.Fields(integrity) {
+			digest, ok := strings.CutPrefix(field, algo+"-")
+			if !ok {
+				continue
+			}
+			digest, _, _ = strings.Cut(digest, "?")
+			sum, err := base64.StdEncoding.DecodeString(digest)
+			if err != nil || len(sum) < 3 {
+				continue
+			}
+			hash := hex.EncodeToString(sum)
+			candidates = append(candidates, filepath.Join(root, cacacheContentDir, algo, hash[:2], hash[2:4], hash[4:]))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0]
+	}
+	return ""
+}
+
+// readCacacheBucket parses the lines of an index bucket. Lines whose hash
+// does not match, as left by an interrupted write, are skipped.
+func readCacacheBucket(data []byte) []cacacheLine {
+	var lines []cacacheLine
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		hash, entry, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		sum := sha1.Sum([]byte(entry))
+		if hex.EncodeToString(sum[:]) != hash {
+			continue
+		}
+		var line cacacheLine
+		if json.Unmarshal([]byte(entry), &line) == nil && line.Key != "" {
+			line.raw = scanner.Text()
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// ReadCacacheIndex returns the live entries of the cacache index at root,
+// e.g. ~/.npm/_cacache, sorted by key. The last line written for a key wins.
+func ReadCacacheIndex(root string) ([]core.CacheIndexEntry, error) {
+	latest := make(map[string]cacacheLine)
+	err := filepath.WalkDir(filepath.Join(root, cacacheIndexDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		for _, line := range readCacacheBucket(data) {
+			latest[line.Key] = line
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]core.CacheIndexEntry, 0, len(latest))
+	for _, line := range latest {
+		if line.Integrity == nil {
+			continue
+		}
+		entry := core.CacheIndexEntry{
+			Key:       line.Key,
+			Kind:      "other",
+			Integrity: *line.Integrity,
+			Size:      line.Size,
+			Time:      time.UnixMilli(line.Time),
+			Content:   cacacheContentPath(root, *line.Integrity),
+		}
+		describeCacheKey(&entry)
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries, nil
+}
+
+// describeCacheKey fills in the URL, kind, package and version of an entry
+// of npm's fetch cache from its key
+func describeCacheKey(entry *core.CacheIndexEntry) {
+	rawURL, ok := strings.CutPrefix(entry.Key, fetchCachePrefix)
+	if !ok {
+		return
+	}
+	entry.URL = rawURL
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+	segments := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	for i, segment := range segments {
+		segments[i], _ = url.PathUnescape(segment)
+	}
+
+	// Tarballs live at <registry>/<name>/-/<basename>-<version>.tgz
+	for i := len(segments) - 2; i > 0; i-- {
+		if segments[i] != "-" {
+			continue
+		}
+		name := segments[i-1]
+		if i >= 2 && strings.HasPrefix(segments[i-2], "@") {
+			name = segments[i-2] + "/" + name
+		}
+		file := segments[len(segments)-1]
+		if !strings.HasSuffix(file, ".tgz") {
+			return
+		}
+		entry.Kind = "tarball"
+		entry.Package = name
+		entry.Version = strings.TrimPrefix(strings.TrimSuffix(file, ".tgz"), path.Base(name)+"-")
+		return
+	}
+
+	// Packuments live at <registry>/<name>, with a scoped name's slash escaped
+	last := segments[len(segments)-1]
+	if last == "" {
+		return
+	}
+	if len(segments) >= 2 && strings.HasPrefix(segments[len(segments)-2], "@") && !strings.Contains(last, "/") {
+		last = segments[len(segments)-2] + "/" + last
+	}
+	entry.Kind = "packument"
+	entry.Package = last
+}
+
+// RemoveCacacheEntries drops the given keys from the cacache index at root
+// and deletes the content files no remaining entry points to
+func RemoveCacacheEntries(root string, keys []string) (*core.CacheEvictResult, error) {
+	remove := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		remove[key] = true
+	}
+
+	entries, err := ReadCacacheIndex(root)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &core.CacheEvictResult{Manager: "npm"}
+	inUse := make(map[string]bool)
+	var orphans []string
+	for _, entry := range entries {
+		if !remove[entry.Key] {
+			inUse[entry.Content] = true
+			continue
+		}
+		if err := removeCacacheKey(root, entry.Key); err != nil {
+			return nil, err
+		}
+		result.Keys = append(result.Keys, entry.Key)
+		orphans = append(orphans, entry.Content)
+	}
+
+	for _, content := range orphans {
+		if content == "" || inUse[content] {
+			continue
+		}
+		info, err := os.Stat(content)
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(content); err != nil {
+			return nil, err
+		}
+		inUse[content] = true
+		result.ContentRemoved++
+		result.BytesFreed += info.Size()
+	}
+
+	return result, nil
+}
+
+// removeCacacheKey rewrites the bucket of a key without its lines, and
+// deletes the bucket when nothing else is left in it
+func removeCacacheKey(root, key string) error {
+	bucket := cacacheBucketPath(root, key)
+	data, err := os.ReadFile(bucket)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var kept []byte
+	for _, line := range readCacacheBucket(data) {
+		if line.Key == key {
+			continue
+		}
+		kept = append(kept, "\n"+line.raw...)
+	}
+
+	if len(kept) == 0 {
+		return os.Remove(bucket)
+	}
+	return os.WriteFile(bucket, kept, 0644)
+}
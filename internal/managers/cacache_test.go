@@ -0,0 +1,122 @@
+package managers
+
+import (
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeCacacheEntry stores content under its integrity and appends an index
+// line for key the way cacache does; nil content writes a deletion marker
+func writeCacacheEntry(t *testing.T, root, key string, content []byte) string {
+	t.Helper()
+
+	line := map[string]any{"key": key, "integrity": nil, "time": 1700000000000}
+	integrity := ""
+	if content != nil {
+		sum := sha512.Sum512(content)
+		integrity = "sha512-" + base64.StdEncoding.EncodeToString(sum[:])
+		line["integrity"] = integrity
+		line["size"] = len(content)
+
+		path := cacacheContentPath(root, integrity)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entry, _ := json.Marshal(line)
+	hash := sha1.Sum(entry)
+	bucket := cacacheBucketPath(root, key)
+	os.MkdirAll(filepath.Dir(bucket), 0755)
+	f, err := os.OpenFile(bucket, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString("\n" + hex.EncodeToString(hash[:]) + "\t" + string(entry))
+	return integrity
+}
+
+func TestReadCacacheIndex(t *testing.T) {
+	root := t.TempDir()
+	const registry = fetchCachePrefix + "https://registry.npmjs.org/"
+
+	writeCacacheEntry(t, root, registry+"lodash/-/lodash-4.17.21.tgz", []byte("lodash tarball"))
+	writeCacacheEntry(t, root, registry+"lodash", []byte(`{"name":"lodash"}`))
+	writeCacacheEntry(t, root, registry+"@babel/core/-/core-7.24.0.tgz", []byte("babel tarball"))
+	writeCacacheEntry(t, root, registry+"@babel%2fcore", []byte(`{"name":"@babel/core"}`))
+	writeCacacheEntry(t, root, registry+"left-pad", []byte(`{"name":"left-pad"}`))
+	writeCacacheEntry(t, root, registry+"left-pad", nil)
+
+	// A line cut short by an interrupted write is ignored
+	bucket := cacacheBucketPath(root, registry+"lodash")
+	f, _ := os.OpenFile(bucket, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("\n0000\t{\"key\":\"broken")
+	f.Close()
+
+	entries, err := ReadCacacheIndex(root)
+	if err != nil {
+		t.Fatalf("ReadCacacheIndex() error = %v", err)
+	}
+
+	want := map[string][3]string{
+		registry + "@babel%2fcore":                 {"packument", "@babel/core", ""},
+		registry + "@babel/core/-/core-7.24.0.tgz": {"tarball", "@babel/core", "7.24.0"},
+		registry + "lodash":                        {"packument", "lodash", ""},
+		registry + "lodash/-/lodash-4.17.21.tgz":   {"tarball", "lodash", "4.17.21"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("ReadCacacheIndex() = %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for _, entry := range entries {
+		w, ok := want[entry.Key]
+		if !ok {
+			t.Errorf("unexpected entry %s", entry.Key)
+			continue
+		}
+		if entry.Kind != w[0] || entry.Package != w[1] || entry.Version != w[2] {
+			t.Errorf("%s = %s %s@%s, want %v", entry.Key, entry.Kind, entry.Package, entry.Version, w)
+		}
+		if _, err := os.Stat(entry.Content); err != nil || entry.Size == 0 {
+			t.Errorf("%s content = %q, size %d", entry.Key, entry.Content, entry.Size)
+		}
+	}
+}
+
+func TestRemoveCacacheEntries(t *testing.T) {
+	root := t.TempDir()
+	const registry = fetchCachePrefix + "https://registry.npmjs.org/"
+
+	tarball := []byte("lodash tarball")
+	writeCacacheEntry(t, root, registry+"lodash/-/lodash-4.17.21.tgz", tarball)
+	writeCacacheEntry(t, root, registry+"lodash", []byte(`{"name":"lodash"}`))
+	// The same tarball fetched from a mirror shares its content file
+	mirror := fetchCachePrefix + "https://mirror.local/lodash/-/lodash-4.17.21.tgz"
+	writeCacacheEntry(t, root, mirror, tarball)
+
+	result, err := RemoveCacacheEntries(root, []string{registry + "lodash/-/lodash-4.17.21.tgz", registry + "lodash"})
+	if err != nil {
+		t.Fatalf("RemoveCacacheEntries() error = %v", err)
+	}
+	if len(result.Keys) != 2 || result.ContentRemoved != 1 {
+		t.Errorf("result = %+v, want 2 keys and only the packument content removed", result)
+	}
+
+	entries, _ := ReadCacacheIndex(root)
+	if len(entries) != 1 || entries[0].Key != mirror {
+		t.Fatalf("entries left = %+v, want only the mirror tarball", entries)
+	}
+	if _, err := os.Stat(entries[0].Content); err != nil {
+		t.Errorf("shared tarball content was removed: %v", err)
+	}
+	if _, err := os.Stat(cacacheBucketPath(root, registry+"lodash")); !os.IsNotExist(err) {
+		t.Errorf("empty bucket was not removed: %v", err)
+	}
+}
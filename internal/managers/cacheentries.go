@@ -0,0 +1,298 @@
+package managers
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"npm-console/internal/core"
+)
+
+// yarnClassicCacheDir matches the versioned directory yarn 1 keeps its
+// extracted packages in, e.g. v6
+var yarnClassicCacheDir = regexp.MustCompile(`^v\d+$`)
+
+// isCacheUnit reports whether a directory of a manager's cache, given
+// relative to the cache root with forward slashes, holds a single package
+// and must be evicted as a whole. Other directories are split into files.
+// npm's _cacache and the pnpm store are content-addressed and listed by
+// their index instead, see cacacheEntries and pnpmCacheEntries.
+func isCacheUnit(manager, rel string) bool {
+	parts := strings.Split(rel, "/")
+	switch manager {
+	case "npm":
+		// _npx installs and other top level directories are kept whole
+		return len(parts) == 1
+	case "yarn":
+		// yarn 1 extracts into v6/<package>, yarn 2+ keeps flat zip files
+		if yarnClassicCacheDir.MatchString(parts[0]) {
+			return len(parts) == 2
+		}
+		return len(parts) == 1
+	case "bun":
+		// bun extracts each package@version into its own directory
+		return len(parts) == 1
+	}
+	return false
+}
+
+// CacheEntries lists the entries of a manager's cache at root that can be
+// evicted independently, see isCacheUnit
+func CacheEntries(manager, root string) ([]core.CacheEntry, error) {
+	if manager == "pnpm" {
+		entries, err := pnpmCacheEntries(root)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return entries, err
+	}
+
+	var entries []core.CacheEntry
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir() && manager == "npm" && rel == "_cacache":
+			cached, err := cacacheEntries(path)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, cached...)
+			return filepath.SkipDir
+		case d.IsDir() && isCacheUnit(manager, filepath.ToSlash(rel)):
+			entry, err := dirEntry(path)
+			if err != nil {
+				return nil
+			}
+			entries = append(entries, entry)
+			return filepath.SkipDir
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			entries = append(entries, core.CacheEntry{Path: path, Size: info.Size(), ModTime: info.ModTime()})
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// cacacheEntries lists the content files of the cacache at root with the
+// index keys pointing to them, so that keys and content are evicted
+// together. Content no key points to is listed on its own.
+func cacacheEntries(root string) ([]core.CacheEntry, error) {
+	index, err := ReadCacacheIndex(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []core.CacheEntry
+	byContent := make(map[string]int)
+	for _, indexEntry := range index {
+		i, ok := byContent[indexEntry.Content]
+		if !ok {
+			i = len(entries)
+			byContent[indexEntry.Content] = i
+			entries = append(entries, core.CacheEntry{Path: indexEntry.Content, Size: fileSize(indexEntry.Content)})
+		}
+		entries[i].Keys = append(entries[i].Keys, indexEntry.Key)
+		if indexEntry.Time.After(entries[i].ModTime) {
+			entries[i].ModTime = indexEntry.Time
+		}
+	}
+
+	err = filepath.WalkDir(filepath.Join(root, cacacheContentDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if _, ok := byContent[path]; ok {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, core.CacheEntry{Path: path, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	return entries, err
+}
+
+// pnpmCacheEntries lists the package index files of a pnpm store, each
+// sized with the content only that package uses, and the content no
+// package uses. Content shared by several packages goes with the last of
+// them to be evicted.
+func pnpmCacheEntries(root string) ([]core.CacheEntry, error) {
+	indexes, err := findPnpmIndexes(root)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]int)
+	contents := make(map[string][]string)
+	complete := true
+	for _, path := range indexes {
+		index, err := readPnpmIndex(path)
+		if err != nil {
+			complete = false
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, file := range index.Files {
+			content := pnpmContentPath(path, file.Integrity, file.Mode)
+			if content == "" || seen[content] {
+				continue
+			}
+			seen[content] = true
+			users[content]++
+			contents[path] = append(contents[path], content)
+		}
+	}
+
+	var entries []core.CacheEntry
+	for _, path := range indexes {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		entry := core.CacheEntry{Path: path, Size: info.Size(), ModTime: info.ModTime()}
+		for _, content := range contents[path] {
+			if users[content] == 1 {
+				entry.Size += fileSize(content)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	// Without a readable index for every package, unreferenced content
+	// cannot be told apart
+	if len(indexes) == 0 || !complete {
+		return entries, nil
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || users[path] > 0 || strings.HasSuffix(path, "-index.json") {
+			return nil
+		}
+		if !isHexByte(filepath.Base(filepath.Dir(path))) || filepath.Base(filepath.Dir(filepath.Dir(path))) != "files" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, core.CacheEntry{Path: path, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	return entries, err
+}
+
+// EvictCacheEntries removes entries listed by CacheEntries from a manager's
+// cache at root and returns the bytes freed. Index keys are dropped with
+// their content, and pnpm content is only deleted once no remaining package
+// uses it.
+func EvictCacheEntries(manager, root string, entries []core.CacheEntry) (int64, error) {
+	switch manager {
+	case "npm":
+		var keys []string
+		var others []core.CacheEntry
+		for _, entry := range entries {
+			if len(entry.Keys) > 0 {
+				keys = append(keys, entry.Keys...)
+			} else {
+				others = append(others, entry)
+			}
+		}
+
+		var freed int64
+		if len(keys) > 0 {
+			result, err := RemoveCacacheEntries(filepath.Join(root, "_cacache"), keys)
+			if err != nil {
+				return 0, err
+			}
+			freed = result.BytesFreed
+		}
+		for _, entry := range others {
+			if err := RemoveCacheEntry(root, entry); err != nil {
+				return freed, err
+			}
+			freed += entry.Size
+		}
+		return freed, nil
+	case "pnpm":
+		packages := make([]core.CachedPackage, 0, len(entries))
+		for _, entry := range entries {
+			packages = append(packages, core.CachedPackage{Path: entry.Path})
+		}
+		return removePnpmPackages(root, packages)
+	}
+
+	var freed int64
+	for _, entry := range entries {
+		if err := RemoveCacheEntry(root, entry); err != nil {
+			return freed, err
+		}
+		freed += entry.Size
+	}
+	return freed, nil
+}
+
+// dirEntry sizes a directory evicted as a whole
+func dirEntry(dir string) (core.CacheEntry, error) {
+	entry := core.CacheEntry{Path: dir}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().After(entry.ModTime) {
+			entry.ModTime = info.ModTime()
+		}
+		if info.Mode().IsRegular() {
+			entry.Size += info.Size()
+		}
+		return nil
+	})
+	return entry, err
+}
+
+// RemoveCacheEntry deletes an entry of the cache at root, then the
+// directories it leaves empty up to root
+func RemoveCacheEntry(root string, entry core.CacheEntry) error {
+	rel, err := filepath.Rel(root, entry.Path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return os.ErrInvalid
+	}
+	if err := os.RemoveAll(entry.Path); err != nil {
+		return err
+	}
+
+	for dir := filepath.Dir(entry.Path); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package managers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"npm-console/internal/core"
+)
+
+func TestCacheEntriesNpm(t *testing.T) {
+	root := t.TempDir()
+	cacache := filepath.Join(root, "_cacache")
+	const registry = fetchCachePrefix + "https://registry.npmjs.org/"
+
+	writeCacacheEntry(t, cacache, registry+"lodash/-/lodash-4.17.21.tgz", []byte("lodash tarball"))
+	writeCacacheEntry(t, cacache, registry+"lodash", []byte(`{"name":"lodash"}`))
+	// Content no index key points to, stored under its integrity
+	orphan := cacacheContentPath(cacache, writeCacacheEntry(t, t.TempDir(), "elsewhere", []byte("orphaned")))
+	os.MkdirAll(filepath.Dir(orphan), 0755)
+	os.WriteFile(orphan, []byte("orphaned"), 0644)
+
+	entries, err := CacheEntries("npm", root)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("CacheEntries() = %+v, %v", entries, err)
+	}
+	var tarball, stray int
+	for i, entry := range entries {
+		switch {
+		case len(entry.Keys) == 1 && entry.Keys[0] == registry+"lodash/-/lodash-4.17.21.tgz":
+			tarball = i
+		case len(entry.Keys) == 0:
+			stray = i
+		}
+	}
+	if entries[stray].Path != orphan || entries[tarball].Size != int64(len("lodash tarball")) {
+		t.Fatalf("entries = %+v", entries)
+	}
+
+	freed, err := EvictCacheEntries("npm", root, []core.CacheEntry{entries[tarball], entries[stray]})
+	if err != nil || freed != int64(len("lodash tarball")+len("orphaned")) {
+		t.Fatalf("EvictCacheEntries() = %d, %v", freed, err)
+	}
+
+	// The index and the content stay consistent
+	result, err := VerifyCache("npm", root)
+	if err != nil || result.Checked != 1 || len(result.Issues) != 0 {
+		t.Errorf("VerifyCache() = %+v, %v", result, err)
+	}
+}
+
+func TestCacheEntriesPnpm(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "v3")
+
+	shared := writePnpmFile(t, store, []byte("MIT License"), 0644)
+	own := writePnpmFile(t, store, []byte(`{"name":"is-odd"}`), 0644)
+	writePnpmFile(t, store, []byte("no package uses this"), 0644)
+	writeIndex := func(hash string, files map[string]any) string {
+		data, _ := json.Marshal(map[string]any{"name": "is-" + hash, "version": "1.0.0", "files": files})
+		path := filepath.Join(store, "files", hash[:2], hash[2:]+"-index.json")
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, data, 0644)
+		return path
+	}
+	odd := writeIndex("aa11", map[string]any{"LICENSE": shared, "package.json": own})
+	writeIndex("bb22", map[string]any{"LICENSE": shared})
+
+	entries, err := CacheEntries("pnpm", store)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("CacheEntries() = %+v, %v", entries, err)
+	}
+	var evict []core.CacheEntry
+	for _, entry := range entries {
+		if entry.Path == odd {
+			// The shared LICENSE is not counted for either package
+			if want := fileSize(odd) + int64(len(`{"name":"is-odd"}`)); entry.Size != want {
+				t.Errorf("is-odd size = %d, want %d", entry.Size, want)
+			}
+			evict = append(evict, entry)
+		} else if filepath.Ext(entry.Path) != ".json" {
+			evict = append(evict, entry)
+		}
+	}
+	if len(evict) != 2 {
+		t.Fatalf("entries = %+v, want is-odd and the unused file", entries)
+	}
+
+	if _, err := EvictCacheEntries("pnpm", store, evict); err != nil {
+		t.Fatalf("EvictCacheEntries() error = %v", err)
+	}
+
+	// The other package keeps the file it shared with is-odd
+	result, err := VerifyCache("pnpm", store)
+	if err != nil || result.Checked != 1 || len(result.Issues) != 0 {
+		t.Errorf("VerifyCache() = %+v, %v", result, err)
+	}
+}
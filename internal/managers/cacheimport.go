@@ -0,0 +1,194 @@
+package managers
+
+import (
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"npm-console/internal/core"
+)
+
+// defaultTarballRegistry is used for the tarball URL of packages whose
+// lockfile records none
+const defaultTarballRegistry = "https://registry.npmjs.org/"
+
+// CachedTarball returns the package tarball a cached copy keeps, or "" when
+// the manager only keeps the extracted files
+func CachedTarball(pkg core.CachedPackage) string {
+	switch pkg.Manager {
+	case "npm":
+		return pkg.Path
+	case "yarn":
+		// yarn 1 keeps the tarball next to the metadata when asked to
+		if metadata := findYarnMetadata(pkg.Path); metadata != "" {
+			tarball := filepath.Join(filepath.Dir(metadata), ".yarn-tarball.tgz")
+			if _, err := os.Stat(tarball); err == nil {
+				return tarball
+			}
+		}
+	}
+	return ""
+}
+
+// VerifyTarball reports whether a tarball matches a subresource integrity
+// string. An empty integrity, or one naming no supported hash, never matches.
+func VerifyTarball(path, integrity string) bool {
+	ok, err := verifyIntegrity(path, integrity)
+	return err == nil && ok
+}
+
+// TarballIntegrity returns the sha512 subresource integrity of a file
+func TarballIntegrity(path string) (string, error) {
+	sum, err := hashFile(path, sha512.New())
+	if err != nil {
+		return "", err
+	}
+	return "sha512-" + base64.StdEncoding.EncodeToString(sum), nil
+}
+
+// tarballURL returns the URL a package's tarball is fetched from, without
+// the hash yarn 1 appends to it
+func tarballURL(pkg core.BundledPackage) string {
+	if strings.HasPrefix(pkg.Resolved, "http://") || strings.HasPrefix(pkg.Resolved, "https://") {
+		rawURL, _, _ := strings.Cut(pkg.Resolved, "#")
+		return rawURL
+	}
+	return defaultTarballRegistry + pkg.Name + "/-/" + path.Base(pkg.Name) + "-" + pkg.Version + ".tgz"
+}
+
+// copyFile copies src to dst through a temporary file, so that an
+// interrupted copy never leaves a partial dst behind
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// ImportCacacheTarball stores a package tarball in the cacache at root the
+// way npm's fetch cache does, under the request key of its URL. It reports
+// false when the entry was already there.
+func ImportCacacheTarball(root string, pkg core.BundledPackage) (bool, error) {
+	integrity, err := TarballIntegrity(pkg.File)
+	if err != nil {
+		return false, err
+	}
+	rawURL := tarballURL(pkg)
+	key := fetchCachePrefix + rawURL
+
+	content := cacacheContentPath(root, integrity)
+	if _, err := os.Stat(content); err == nil {
+		entries, _ := readCacacheBucketFile(cacacheBucketPath(root, key))
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].Key == key {
+				if entries[i].Integrity != nil && *entries[i].Integrity == integrity {
+					return false, nil
+				}
+				break
+			}
+		}
+	} else if err := copyFile(pkg.File, content); err != nil {
+		return false, err
+	}
+
+	line, err := json.Marshal(map[string]any{
+		"key":       key,
+		"integrity": integrity,
+		"time":      time.Now().UnixMilli(),
+		"size":      fileSize(pkg.File),
+		"metadata": map[string]any{
+			"url":        rawURL,
+			"reqHeaders": map[string]string{},
+			"resHeaders": map[string]string{"content-type": "application/octet-stream"},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	sum := sha1.Sum(line)
+
+	bucket := cacacheBucketPath(root, key)
+	if err := os.MkdirAll(filepath.Dir(bucket), 0755); err != nil {
+		return false, err
+	}
+	f, err := os.OpenFile(bucket, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString("\n" + hex.EncodeToString(sum[:]) + "\t" + string(line)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// readCacacheBucketFile parses the index bucket at path
+func readCacacheBucketFile(path string) ([]cacacheLine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return readCacacheBucket(data), nil
+}
+
+// yarnMirrorFilename returns the name yarn 1 gives a tarball in its offline
+// mirror: the file name of its URL, with the scope prepended for scoped
+// packages, e.g. @babel-core-7.24.0.tgz
+func yarnMirrorFilename(pkg core.BundledPackage) string {
+	name := path.Base(pkg.Name) + "-" + pkg.Version + ".tgz"
+	if u, err := url.Parse(tarballURL(pkg)); err == nil && strings.HasSuffix(u.Path, ".tgz") {
+		name = path.Base(u.Path)
+	}
+
+	if scope, _, ok := strings.Cut(pkg.Name, "/"); ok && strings.HasPrefix(scope, "@") && !strings.HasPrefix(name, "@") {
+		name = scope + "-" + name
+	}
+	return name
+}
+
+// ImportMirrorTarballs copies package tarballs into a yarn 1 offline mirror
+func ImportMirrorTarballs(dir string, packages []core.BundledPackage) *core.CacheImportResult {
+	result := &core.CacheImportResult{Manager: "yarn", Target: dir}
+	for _, pkg := range packages {
+		dst := filepath.Join(dir, yarnMirrorFilename(pkg))
+		if info, err := os.Stat(dst); err == nil && info.Size() == fileSize(pkg.File) {
+			result.Skipped++
+			continue
+		}
+		if err := copyFile(pkg.File, dst); err != nil {
+			result.Failed = append(result.Failed, pkg.Name+"@"+pkg.Version+": "+err.Error())
+			continue
+		}
+		result.Imported++
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+package managers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"npm-console/internal/core"
+)
+
+func TestImportCacacheTarball(t *testing.T) {
+	tarball := filepath.Join(t.TempDir(), "core-7.24.0.tgz")
+	os.WriteFile(tarball, []byte("babel tarball"), 0644)
+	pkg := core.BundledPackage{
+		Name:     "@babel/core",
+		Version:  "7.24.0",
+		Resolved: "https://registry.npmjs.org/@babel/core/-/core-7.24.0.tgz#abc",
+		File:     tarball,
+	}
+
+	root := filepath.Join(t.TempDir(), "_cacache")
+	added, err := ImportCacacheTarball(root, pkg)
+	if err != nil || !added {
+		t.Fatalf("ImportCacacheTarball() = %v, %v", added, err)
+	}
+	if added, err := ImportCacacheTarball(root, pkg); err != nil || added {
+		t.Errorf("second ImportCacacheTarball() = %v, %v; want it skipped", added, err)
+	}
+
+	entries, err := ReadCacacheIndex(root)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ReadCacacheIndex() = %+v, %v", entries, err)
+	}
+	entry := entries[0]
+	if entry.Kind != "tarball" || entry.Package != "@babel/core" || entry.Version != "7.24.0" || entry.URL != "https://registry.npmjs.org/@babel/core/-/core-7.24.0.tgz" {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	result, err := VerifyCache("npm", filepath.Dir(root))
+	if err != nil || result.Checked != 1 || len(result.Issues) != 0 {
+		t.Errorf("VerifyCache() = %+v, %v; want the imported tarball intact", result, err)
+	}
+}
+
+func TestImportMirrorTarballs(t *testing.T) {
+	tarball := filepath.Join(t.TempDir(), "pkg.tgz")
+	os.WriteFile(tarball, []byte("tarball"), 0644)
+
+	packages := []core.BundledPackage{
+		{Name: "lodash", Version: "4.17.21", Resolved: "https://registry.yarnpkg.com/lodash/-/lodash-4.17.21.tgz#679591c5", File: tarball},
+		{Name: "@babel/core", Version: "7.24.0", Resolved: "https://registry.yarnpkg.com/@babel/core/-/core-7.24.0.tgz", File: tarball},
+		{Name: "left-pad", Version: "1.3.0", File: tarball},
+	}
+
+	mirror := t.TempDir()
+	result := ImportMirrorTarballs(mirror, packages)
+	if result.Imported != 3 || len(result.Failed) != 0 {
+		t.Fatalf("ImportMirrorTarballs() = %+v", result)
+	}
+	for _, name := range []string{"lodash-4.17.21.tgz", "@babel-core-7.24.0.tgz", "left-pad-1.3.0.tgz"} {
+		if _, err := os.Stat(filepath.Join(mirror, name)); err != nil {
+			t.Errorf("mirror is missing %s: %v", name, err)
+		}
+	}
+
+	if result := ImportMirrorTarballs(mirror, packages); result.Skipped != 3 {
+		t.Errorf("second ImportMirrorTarballs() = %+v, want all skipped", result)
+	}
+}
@@ -0,0 +1,385 @@
+package managers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"npm-console/internal/core"
+)
+
+var (
+	// yarnZipName matches the cache zips of yarn 2+, e.g.
+	// lodash-npm-4.17.21-6382451519-eb835a2e51.zip
+	yarnZipName = regexp.MustCompile(`^(.+)-npm-(.+?)(?:-[0-9a-f]{10}){1,2}\.zip$`)
+
+	// bunCacheName matches bun's package directories, e.g. lodash@4.17.21@@@1
+	bunCacheName = regexp.MustCompile(`^(.+)@([^@]+)@@@\d+$`)
+)
+
+// SHA512Integrity returns the sha512 part of an integrity string, or ""
+func SHA512Integrity(integrity string) string {
+	for _, field := range strings.Fields(integrity) {
+		if strings.HasPrefix(field, "sha512-") {
+			field, _, _ = strings.Cut(field, "?")
+			return field
+		}
+	}
+	return ""
+}
+
+// CachedPackages lists the package versions in a manager's cache at root
+// with the tarball integrity the manager recorded for them, if any
+func CachedPackages(manager, root string) ([]core.CachedPackage, error) {
+	var packages []core.CachedPackage
+	var err error
+	switch manager {
+	case "npm":
+		packages, err = npmCachedPackages(root)
+	case "pnpm":
+		packages, err = pnpmCachedPackages(root)
+	case "yarn":
+		packages, err = yarnCachedPackages(root)
+	case "bun":
+		packages, err = bunCachedPackages(root)
+	default:
+		return nil, core.ErrNotSupported
+	}
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	for i := range packages {
+		packages[i].Manager = manager
+	}
+	return packages, err
+}
+
+// npmCachedPackages lists the tarballs of npm's cacache
+func npmCachedPackages(root string) ([]core.CachedPackage, error) {
+	entries, err := ReadCacacheIndex(filepath.Join(root, "_cacache"))
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []core.CachedPackage
+	for _, entry := range entries {
+		if entry.Kind != "tarball" {
+			continue
+		}
+		packages = append(packages, core.CachedPackage{
+			Name:      entry.Package,
+			Version:   entry.Version,
+			Integrity: SHA512Integrity(entry.Integrity),
+			Size:      entry.Size,
+			Path:      entry.Content,
+			Key:       entry.Key,
+		})
+	}
+	return packages, nil
+}
+
+// findPnpmIndexes returns the package index files of a pnpm store: in
+// files/<aa> next to the content up to store v3, in index/<aa> after
+func findPnpmIndexes(root string) ([]string, error) {
+	var indexes []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !isHexByte(filepath.Base(filepath.Dir(path))) {
+			return nil
+		}
+		store := filepath.Base(filepath.Dir(filepath.Dir(path)))
+		if (store == "index" && strings.HasSuffix(d.Name(), ".json")) ||
+			(store == "files" && strings.HasSuffix(d.Name(), "-index.json")) {
+			indexes = append(indexes, path)
+		}
+		return nil
+	})
+	return indexes, err
+}
+
+// readPnpmIndex parses a package index file of a pnpm store
+func readPnpmIndex(path string) (*pnpmPackageIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var index pnpmPackageIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+// pnpmContentPath returns the store file holding content of the given
+// integrity, for a package index at indexPath. Executables carry -exec.
+func pnpmContentPath(indexPath, integrity string, mode uint32) string {
+	sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(SHA512Integrity(integrity), "sha512-"))
+	if err != nil || len(sum) != 64 {
+		return ""
+	}
+	digest := hex.EncodeToString(sum)
+	store := filepath.Dir(filepath.Dir(filepath.Dir(indexPath)))
+	path := filepath.Join(store, "files", digest[:2], digest[2:])
+	if mode&0111 != 0 {
+		path += "-exec"
+	}
+	return path
+}
+
+// pnpmIndexIntegrity recovers the tarball integrity a store v3 index file
+// is named after, files/<aa>/<rest of the sha512 hex>-index.json
+func pnpmIndexIntegrity(path string) string {
+	digest := filepath.Base(filepath.Dir(path)) + strings.TrimSuffix(filepath.Base(path), "-index.json")
+	sum, err := hex.DecodeString(digest)
+	if err != nil || len(sum) != 64 {
+		return ""
+	}
+	return "sha512-" + base64.StdEncoding.EncodeToString(sum)
+}
+
+// pnpmCachedPackages lists the packages of a pnpm store by their index
+// files. Their size is that of the files they reference.
+func pnpmCachedPackages(root string) ([]core.CachedPackage, error) {
+	indexes, err := findPnpmIndexes(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []core.CachedPackage
+	for _, path := range indexes {
+		index, err := readPnpmIndex(path)
+		if err != nil {
+			continue
+		}
+		pkg := core.CachedPackage{
+			Name:      index.Name,
+			Version:   index.Version,
+			Integrity: pnpmIndexIntegrity(path),
+			Path:      path,
+		}
+		for _, file := range index.Files {
+			pkg.Size += file.Size
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// yarnCachedPackages lists the zips of a yarn 2+ cache and the package
+// directories of a yarn 1 cache
+func yarnCachedPackages(root string) ([]core.CachedPackage, error) {
+	var packages []core.CachedPackage
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir() && isCacheUnit("yarn", filepath.ToSlash(rel)):
+			if pkg, ok := yarnClassicPackage(path); ok {
+				packages = append(packages, pkg)
+			}
+			return filepath.SkipDir
+		case d.Type().IsRegular():
+			match := yarnZipName.FindStringSubmatch(d.Name())
+			if match == nil {
+				return nil
+			}
+			packages = append(packages, core.CachedPackage{
+				Name:    unslugYarnName(match[1]),
+				Version: match[2],
+				Size:    fileSize(path),
+				Path:    path,
+			})
+		}
+		return nil
+	})
+	return packages, err
+}
+
+// unslugYarnName turns the name part of a yarn cache zip back into a
+// package name. Scopes are joined to the name by a dash there, so a scope
+// that contains a dash itself is split at the wrong place.
+func unslugYarnName(slug string) string {
+	if scope, name, ok := strings.Cut(strings.TrimPrefix(slug, "@"), "-"); ok && strings.HasPrefix(slug, "@") {
+		return "@" + scope + "/" + name
+	}
+	return slug
+}
+
+// yarnClassicPackage describes a package directory of a yarn 1 cache from
+// its .yarn-metadata.json
+func yarnClassicPackage(dir string) (core.CachedPackage, bool) {
+	metadata := findYarnMetadata(dir)
+	if metadata == "" {
+		return core.CachedPackage{}, false
+	}
+	data, err := os.ReadFile(metadata)
+	if err != nil {
+		return core.CachedPackage{}, false
+	}
+	var meta yarnMetadata
+	if err := json.Unmarshal(data, &meta); err != nil || meta.Manifest.Name == "" {
+		return core.CachedPackage{}, false
+	}
+
+	entry, _ := dirEntry(dir)
+	return core.CachedPackage{
+		Name:      meta.Manifest.Name,
+		Version:   meta.Manifest.Version,
+		Integrity: SHA512Integrity(meta.Remote.Integrity),
+		Size:      entry.Size,
+		Path:      dir,
+	}, true
+}
+
+// bunCachedPackages lists bun's package directories, <name>@<version>@@@1
+// with scoped packages inside their @scope directory
+func bunCachedPackages(root string) ([]core.CachedPackage, error) {
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []core.CachedPackage
+	add := func(dir, scope string) {
+		match := bunCacheName.FindStringSubmatch(filepath.Base(dir))
+		if match == nil {
+			return
+		}
+		entry, _ := dirEntry(dir)
+		packages = append(packages, core.CachedPackage{
+			Name:    scope + match[1],
+			Version: match[2],
+			Size:    entry.Size,
+			Path:    dir,
+		})
+	}
+
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		path := filepath.Join(root, d.Name())
+		if !strings.HasPrefix(d.Name(), "@") || bunCacheName.MatchString(d.Name()) {
+			add(path, "")
+			continue
+		}
+		scoped, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, s := range scoped {
+			if s.IsDir() {
+				add(filepath.Join(path, s.Name()), d.Name()+"/")
+			}
+		}
+	}
+	return packages, nil
+}
+
+// RemoveCachedPackages deletes package versions from a manager's cache at
+// root and returns the bytes freed. A pnpm package loses its index file and
+// the store files no other package uses; files still hard linked into a
+// node_modules keep their disk space until that is removed too.
+func RemoveCachedPackages(manager, root string, packages []core.CachedPackage) (int64, error) {
+	switch manager {
+	case "npm":
+		keys := make([]string, 0, len(packages))
+		for _, pkg := range packages {
+			keys = append(keys, pkg.Key)
+		}
+		result, err := RemoveCacacheEntries(filepath.Join(root, "_cacache"), keys)
+		if err != nil {
+			return 0, err
+		}
+		return result.BytesFreed, nil
+	case "pnpm":
+		return removePnpmPackages(root, packages)
+	}
+
+	var freed int64
+	for _, pkg := range packages {
+		if err := RemoveCacheEntry(root, core.CacheEntry{Path: pkg.Path}); err != nil {
+			return freed, err
+		}
+		freed += pkg.Size
+	}
+	return freed, nil
+}
+
+// removePnpmPackages deletes package index files from a pnpm store with
+// the content only they referenced
+func removePnpmPackages(root string, packages []core.CachedPackage) (int64, error) {
+	remove := make(map[string]bool, len(packages))
+	for _, pkg := range packages {
+		remove[pkg.Path] = true
+	}
+
+	indexes, err := findPnpmIndexes(root)
+	if err != nil {
+		return 0, err
+	}
+
+	inUse := make(map[string]bool)
+	var candidates []string
+	for _, path := range indexes {
+		index, err := readPnpmIndex(path)
+		if err != nil {
+			continue
+		}
+		for _, file := range index.Files {
+			content := pnpmContentPath(path, file.Integrity, file.Mode)
+			if remove[path] {
+				candidates = append(candidates, content)
+			} else {
+				inUse[content] = true
+			}
+		}
+	}
+
+	for _, pkg := range packages {
+		candidates = append(candidates, pkg.Path)
+	}
+
+	var freed int64
+	for _, path := range candidates {
+		if path == "" || inUse[path] {
+			continue
+		}
+		size := fileSize(path)
+		if err := RemoveCacheEntry(root, core.CacheEntry{Path: path}); err != nil {
+			return freed, err
+		}
+		inUse[path] = true
+		freed += size
+	}
+	return freed, nil
+}
@@ -0,0 +1,99 @@
+package managers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCachedPackagesYarnAndBun(t *testing.T) {
+	yarn := t.TempDir()
+	for _, name := range []string{
+		"lodash-npm-4.17.21-6382451519-eb835a2e51.zip",
+		"@babel-core-npm-7.24.0-rc.1-1234567890-abcdef0123.zip",
+		"not-a-package.zip",
+	} {
+		os.WriteFile(filepath.Join(yarn, name), []byte("zip"), 0644)
+	}
+	classic := filepath.Join(yarn, "v6", "npm-vue-3.4.0-abc-integrity", "node_modules", "vue")
+	os.MkdirAll(classic, 0755)
+	os.WriteFile(filepath.Join(classic, ".yarn-metadata.json"),
+		[]byte(`{"manifest":{"name":"vue","version":"3.4.0"},"remote":{"integrity":"sha1-abc sha512-xyz"}}`), 0644)
+
+	packages, err := CachedPackages("yarn", yarn)
+	if err != nil {
+		t.Fatalf("CachedPackages(yarn) error = %v", err)
+	}
+	got := make(map[string]string)
+	for _, pkg := range packages {
+		got[pkg.Name+"@"+pkg.Version] = pkg.Integrity
+	}
+	want := map[string]string{"lodash@4.17.21": "", "@babel/core@7.24.0-rc.1": "", "vue@3.4.0": "sha512-xyz"}
+	if len(got) != len(want) {
+		t.Fatalf("CachedPackages(yarn) = %v, want %v", got, want)
+	}
+	for id, integrity := range want {
+		if got[id] != integrity {
+			t.Errorf("%s integrity = %q, want %q", id, got[id], integrity)
+		}
+	}
+
+	bun := t.TempDir()
+	os.MkdirAll(filepath.Join(bun, "lodash@4.17.21@@@1"), 0755)
+	os.MkdirAll(filepath.Join(bun, "@types", "node@20.11.0@@@1"), 0755)
+	os.WriteFile(filepath.Join(bun, "lodash.npm"), []byte("manifest"), 0644)
+
+	packages, err = CachedPackages("bun", bun)
+	if err != nil || len(packages) != 2 {
+		t.Fatalf("CachedPackages(bun) = %+v, %v", packages, err)
+	}
+	if scoped := packages[0]; scoped.Name != "@types/node" || scoped.Version != "20.11.0" || scoped.Manager != "bun" {
+		t.Errorf("scoped bun package = %+v", scoped)
+	}
+}
+
+func TestRemoveCachedPackagesPnpm(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "v3")
+
+	shared := writePnpmFile(t, store, []byte("MIT License"), 0644)
+	own := writePnpmFile(t, store, []byte(`{"name":"is-odd"}`), 0644)
+	writeIndex := func(hash string, files map[string]any) string {
+		data, _ := json.Marshal(map[string]any{"name": "is-" + hash, "version": "1.0.0", "files": files})
+		path := filepath.Join(store, "files", hash[:2], hash[2:]+"-index.json")
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, data, 0644)
+		return path
+	}
+	odd := writeIndex("aa11", map[string]any{"LICENSE": shared, "package.json": own})
+	even := writeIndex("bb22", map[string]any{"LICENSE": shared})
+
+	packages, err := CachedPackages("pnpm", store)
+	if err != nil || len(packages) != 2 {
+		t.Fatalf("CachedPackages(pnpm) = %+v, %v", packages, err)
+	}
+
+	for _, pkg := range packages {
+		if pkg.Path == odd && pkg.Name != "is-aa11" {
+			t.Errorf("package name = %q, want is-aa11", pkg.Name)
+		}
+	}
+	if packages[0].Path != odd {
+		packages[0], packages[1] = packages[1], packages[0]
+	}
+	freed, err := RemoveCachedPackages("pnpm", store, packages[:1])
+	if err != nil {
+		t.Fatalf("RemoveCachedPackages() error = %v", err)
+	}
+	if freed == 0 {
+		t.Error("RemoveCachedPackages() freed nothing")
+	}
+
+	result, _ := VerifyCache("pnpm", store)
+	if len(result.Issues) != 0 || result.Checked != 1 {
+		t.Errorf("after removal: checked %d, issues %+v; want the shared file intact", result.Checked, result.Issues)
+	}
+	if _, err := os.Stat(even); err != nil {
+		t.Errorf("other package index removed: %v", err)
+	}
+}
@@ -0,0 +1,460 @@
+package managers
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"npm-console/internal/core"
+)
+
+// Kinds of cache issues
+const (
+	CacheIssueCorrupt  = "corrupt"  // content does not match its recorded hash
+	CacheIssueMissing  = "missing"  // an index entry points to content that is gone
+	CacheIssueOrphaned = "orphaned" // content no index entry points to
+)
+
+// sriHashes are the subresource integrity algorithms, strongest first
+var sriHashes = []struct {
+	name string
+	new  func() hash.Hash
+}{
+	{"sha512", sha512.New},
+	{"sha384", sha512.New384},
+	{"sha256", sha256.New},
+	{"sha1", sha1.New},
+}
+
+// hashFile returns the digest of a file's content
+func hashFile(path string, h hash.Hash) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// fileSize returns the size of a file, 0 when it cannot be read
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// verifyIntegrity reports whether a file matches an integrity string such
+// as "sha512-<base64>". The strongest algorithm present is checked.
+func verifyIntegrity(path, integrity string) (bool, error) {
+	for _, algo := range sriHashes {
+		for _, field := range strings.Fields(integrity) {
+			digest, ok := strings.CutPrefix(field, algo.name+"-")
+			if !ok {
+				continue
+			}
+			digest, _, _ = strings.Cut(digest, "?")
+			sum, err := hashFile(path, algo.new())
+			if err != nil {
+				return false, err
+			}
+			return base64.StdEncoding.EncodeToString(sum) == digest, nil
+		}
+	}
+	return false, fmt.Errorf("unsupported integrity %q", integrity)
+}
+
+// VerifyCache checks a manager's cache at root against the hashes it
+// records: npm's cacache SRI entries, pnpm's content-addressed store and
+// yarn's cache zips. Nothing is removed; see RepairCache.
+func VerifyCache(manager, root string) (*core.CacheVerifyResult, error) {
+	result := &core.CacheVerifyResult{Manager: manager, Path: root}
+	var err error
+	switch manager {
+	case "npm":
+		err = verifyCacache(filepath.Join(root, "_cacache"), result)
+	case "pnpm":
+		err = verifyPnpmStore(root, result)
+	case "yarn":
+		err = verifyYarnCache(root, result)
+	default:
+		return nil, core.ErrNotSupported
+	}
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// verifyCacache checks each live index entry's content against its
+// integrity, and looks for content no entry points to
+func verifyCacache(root string, result *core.CacheVerifyResult) error {
+	entries, err := ReadCacacheIndex(root)
+	if err != nil {
+		return err
+	}
+
+	referenced := make(map[string]bool)
+	corrupt := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Content == "" {
+			continue
+		}
+		first := !referenced[entry.Content]
+		referenced[entry.Content] = true
+
+		if _, err := os.Stat(entry.Content); err != nil {
+			result.Issues = append(result.Issues, core.CacheIssue{
+				Kind:   CacheIssueMissing,
+				Path:   entry.Content,
+				Key:    entry.Key,
+				Detail: "content file is missing",
+			})
+			continue
+		}
+
+		// Entries sharing content are checked once
+		if first {
+			result.Checked++
+			ok, err := verifyIntegrity(entry.Content, entry.Integrity)
+			corrupt[entry.Content] = err != nil || !ok
+		}
+		if corrupt[entry.Content] {
+			result.Issues = append(result.Issues, core.CacheIssue{
+				Kind:   CacheIssueCorrupt,
+				Path:   entry.Content,
+				Key:    entry.Key,
+				Detail: "content does not match " + entry.Integrity,
+				Size:   fileSize(entry.Content),
+			})
+		}
+	}
+
+	contentDir := filepath.Join(root, cacacheContentDir)
+	return filepath.WalkDir(contentDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == contentDir {
+				return err
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || referenced[path] {
+			return nil
+		}
+		result.Issues = append(result.Issues, core.CacheIssue{
+			Kind:   CacheIssueOrphaned,
+			Path:   path,
+			Detail: "no index entry refers to this content",
+			Size:   fileSize(path),
+		})
+		return nil
+	})
+}
+
+// pnpmPackageIndex is a package's index file in the pnpm store, mapping
+// the files of the package to their content
+type pnpmPackageIndex struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Files   map[string]struct {
+		Integrity string `json:"integrity"`
+		Mode      uint32 `json:"mode"`
+		Size      int64  `json:"size"`
+	} `json:"files"`
+}
+
+// isHexByte reports whether name is a two digit hex directory of a
+// content-addressed store
+func isHexByte(name string) bool {
+	_, err := hex.DecodeString(name)
+	return len(name) == 2 && err == nil
+}
+
+// verifyPnpmStore checks the files of pnpm's content-addressable store,
+// files/<aa>/<rest of the sha512 hex>[-exec], against the hash their name
+// records, and the package index files for content that is gone
+func verifyPnpmStore(root string, result *core.CacheVerifyResult) error {
+	content := make(map[string]bool) // content file -> intact
+	var indexes []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		dir := filepath.Base(filepath.Dir(path))
+		store := filepath.Base(filepath.Dir(filepath.Dir(path)))
+		name := d.Name()
+		switch {
+		case !isHexByte(dir):
+			return nil
+		case store == "index" && strings.HasSuffix(name, ".json"),
+			store == "files" && strings.HasSuffix(name, "-index.json"):
+			indexes = append(indexes, path)
+			return nil
+		case store != "files":
+			return nil
+		}
+
+		want := dir + strings.TrimSuffix(name, "-exec")
+		if _, err := hex.DecodeString(want); err != nil || len(want) != sha512.Size*2 {
+			return nil
+		}
+
+		result.Checked++
+		sum, err := hashFile(path, sha512.New())
+		content[path] = err == nil && hex.EncodeToString(sum) == want
+		if !content[path] {
+			result.Issues = append(result.Issues, core.CacheIssue{
+				Kind:   CacheIssueCorrupt,
+				Path:   path,
+				Detail: "content does not match its sha512 name",
+				Size:   fileSize(path),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	referenced := make(map[string]bool)
+	for _, path := range indexes {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		issue := core.CacheIssue{Path: path, Size: fileSize(path)}
+
+		var index pnpmPackageIndex
+		if err := json.Unmarshal(data, &index); err != nil {
+			issue.Kind = CacheIssueCorrupt
+			issue.Detail = "package index is not valid JSON"
+			result.Issues = append(result.Issues, issue)
+			continue
+		}
+
+		for name, file := range index.Files {
+			target := pnpmContentPath(path, file.Integrity, file.Mode)
+			if target == "" {
+				continue
+			}
+			referenced[target] = true
+
+			intact, ok := content[target]
+			switch {
+			case !ok:
+				issue.Kind = CacheIssueMissing
+				issue.Detail = "content of " + name + " is missing"
+			case !intact:
+				issue.Kind = CacheIssueCorrupt
+				issue.Detail = "content of " + name + " is corrupt"
+			}
+		}
+		if issue.Kind != "" {
+			result.Issues = append(result.Issues, issue)
+		}
+	}
+
+	// Without index files there is nothing to tell orphans apart by
+	if len(indexes) == 0 {
+		return nil
+	}
+	for path, intact := range content {
+		if !intact || referenced[path] {
+			continue
+		}
+		result.Issues = append(result.Issues, core.CacheIssue{
+			Kind:   CacheIssueOrphaned,
+			Path:   path,
+			Detail: "no package index refers to this content",
+			Size:   fileSize(path),
+		})
+	}
+	return nil
+}
+
+// yarnMetadata is the .yarn-metadata.json yarn 1 keeps with each package
+type yarnMetadata struct {
+	Manifest struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"manifest"`
+	Remote struct {
+		Integrity string `json:"integrity"`
+	} `json:"remote"`
+}
+
+// verifyYarnCache checks the zips of a yarn 2+ cache against their CRC-32
+// checksums, and the packages of a yarn 1 cache against their metadata
+func verifyYarnCache(root string, result *core.CacheVerifyResult) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case d.IsDir() && isCacheUnit("yarn", filepath.ToSlash(rel)):
+			result.Checked++
+			if detail := verifyYarnPackage(path); detail != "" {
+				entry, _ := dirEntry(path)
+				result.Issues = append(result.Issues, core.CacheIssue{Kind: CacheIssueCorrupt, Path: path, Detail: detail, Size: entry.Size})
+			}
+			return filepath.SkipDir
+		case d.Type().IsRegular() && strings.HasSuffix(d.Name(), ".zip"):
+			result.Checked++
+			if err := verifyZip(path); err != nil {
+				result.Issues = append(result.Issues, core.CacheIssue{Kind: CacheIssueCorrupt, Path: path, Detail: err.Error(), Size: fileSize(path)})
+			}
+		}
+		return nil
+	})
+}
+
+// verifyZip reads every file of a zip archive, which checks their CRC-32
+func verifyZip(path string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		_, err = io.Copy(io.Discard, rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// findYarnMetadata returns the .yarn-metadata.json of a yarn 1 package
+// directory, or "" when it has none
+func findYarnMetadata(dir string) string {
+	var metadata string
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.Name() == ".yarn-metadata.json" {
+			metadata = path
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return metadata
+}
+
+// verifyYarnPackage checks a package directory of a yarn 1 cache. It
+// returns what is wrong with it, or "" when it is intact.
+func verifyYarnPackage(dir string) string {
+	metadata := findYarnMetadata(dir)
+	if metadata == "" {
+		return "package has no .yarn-metadata.json, the extraction was interrupted"
+	}
+
+	data, err := os.ReadFile(metadata)
+	if err != nil {
+		return err.Error()
+	}
+	var meta yarnMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return ".yarn-metadata.json is not valid JSON"
+	}
+
+	// The tarball is only kept with yarn-offline-mirror style settings
+	tarball := filepath.Join(filepath.Dir(metadata), ".yarn-tarball.tgz")
+	if meta.Remote.Integrity == "" {
+		return ""
+	}
+	if _, err := os.Stat(tarball); err != nil {
+		return ""
+	}
+	if ok, err := verifyIntegrity(tarball, meta.Remote.Integrity); err == nil && !ok {
+		return ".yarn-tarball.tgz does not match " + meta.Remote.Integrity
+	}
+	return ""
+}
+
+// RepairCache deletes what the issues of a verification point to: index
+// entries are dropped from npm's cacache, files and directories removed
+func RepairCache(result *core.CacheVerifyResult) error {
+	root := result.Path
+	var keys []string
+	for _, issue := range result.Issues {
+		if issue.Key != "" {
+			keys = append(keys, issue.Key)
+		}
+	}
+
+	evicted := make(map[string]bool)
+	if len(keys) > 0 {
+		// Content no other entry uses goes with the index entries
+		removed, err := RemoveCacacheEntries(filepath.Join(root, "_cacache"), keys)
+		if err != nil {
+			return err
+		}
+		result.BytesFreed += removed.BytesFreed
+		for _, key := range removed.Keys {
+			evicted[key] = true
+		}
+	}
+
+	for _, issue := range result.Issues {
+		if issue.Key != "" {
+			if evicted[issue.Key] {
+				result.Repaired++
+			}
+			continue
+		}
+		if err := RemoveCacheEntry(root, core.CacheEntry{Path: issue.Path}); err != nil {
+			return err
+		}
+		result.Repaired++
+		result.BytesFreed += issue.Size
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package managers
+
+import (
+	"archive/zip"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"npm-console/internal/core"
+)
+
+// issueKinds maps the base name of each issue's path to its kind
+func issueKinds(result *core.CacheVerifyResult) map[string]string {
+	kinds := make(map[string]string)
+	for _, issue := range result.Issues {
+		kinds[filepath.Base(issue.Path)] = issue.Kind
+	}
+	return kinds
+}
+
+func TestVerifyCacheNpm(t *testing.T) {
+	root := t.TempDir()
+	cacache := filepath.Join(root, "_cacache")
+	const registry = fetchCachePrefix + "https://registry.npmjs.org/"
+
+	writeCacacheEntry(t, cacache, registry+"lodash", []byte(`{"name":"lodash"}`))
+	corrupt := writeCacacheEntry(t, cacache, registry+"react", []byte(`{"name":"react"}`))
+	missing := writeCacacheEntry(t, cacache, registry+"vue", []byte(`{"name":"vue"}`))
+	os.WriteFile(cacacheContentPath(cacache, corrupt), []byte("truncated"), 0644)
+	os.Remove(cacacheContentPath(cacache, missing))
+	orphan := filepath.Join(cacache, cacacheContentDir, "sha512", "ab", "cd", "ef")
+	os.MkdirAll(filepath.Dir(orphan), 0755)
+	os.WriteFile(orphan, []byte("left over"), 0644)
+
+	result, err := VerifyCache("npm", root)
+	if err != nil {
+		t.Fatalf("VerifyCache() error = %v", err)
+	}
+	if result.Checked != 2 || len(result.Issues) != 3 {
+		t.Fatalf("VerifyCache() = %d checked, issues %+v; want 2 checked and 3 issues", result.Checked, result.Issues)
+	}
+	keys := make(map[string]string)
+	for _, issue := range result.Issues {
+		keys[issue.Kind] = issue.Key
+	}
+	if keys[CacheIssueCorrupt] != registry+"react" || keys[CacheIssueMissing] != registry+"vue" || keys[CacheIssueOrphaned] != "" {
+		t.Errorf("issues by kind = %v", keys)
+	}
+
+	if err := RepairCache(result); err != nil {
+		t.Fatalf("RepairCache() error = %v", err)
+	}
+	if result.Repaired != 3 {
+		t.Errorf("Repaired = %d, want 3", result.Repaired)
+	}
+
+	result, err = VerifyCache("npm", root)
+	if err != nil || len(result.Issues) != 0 || result.Checked != 1 {
+		t.Errorf("after repair: checked %d, issues %+v, err %v; want only lodash left", result.Checked, result.Issues, err)
+	}
+}
+
+// writePnpmFile stores content in a pnpm store and returns its index record
+func writePnpmFile(t *testing.T, store string, content []byte, mode uint32) map[string]any {
+	t.Helper()
+	sum := sha512.Sum512(content)
+	digest := hex.EncodeToString(sum[:])
+	path := filepath.Join(store, "files", digest[:2], digest[2:])
+	if mode&0111 != 0 {
+		path += "-exec"
+	}
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return map[string]any{"integrity": "sha512-" + base64.StdEncoding.EncodeToString(sum[:]), "mode": mode, "size": len(content)}
+}
+
+func TestVerifyCachePnpm(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "v3")
+
+	index := map[string]any{"files": map[string]any{
+		"package.json": writePnpmFile(t, store, []byte(`{"name":"is-odd"}`), 0644),
+		"cli.js":       writePnpmFile(t, store, []byte("#!/usr/bin/env node"), 0755),
+		"index.js":     writePnpmFile(t, store, []byte("module.exports = n => n % 2 === 1"), 0644),
+	}}
+	data, _ := json.Marshal(index)
+	indexPath := filepath.Join(store, "files", "12", "3456-index.json")
+	os.MkdirAll(filepath.Dir(indexPath), 0755)
+	os.WriteFile(indexPath, data, 0644)
+
+	// Content no package index refers to
+	writePnpmFile(t, store, []byte("unused"), 0644)
+
+	// Corrupt index.js
+	sum := sha512.Sum512([]byte("module.exports = n => n % 2 === 1"))
+	digest := hex.EncodeToString(sum[:])
+	os.WriteFile(filepath.Join(store, "files", digest[:2], digest[2:]), []byte("garbage"), 0644)
+
+	result, err := VerifyCache("pnpm", store)
+	if err != nil {
+		t.Fatalf("VerifyCache() error = %v", err)
+	}
+	if result.Checked != 4 {
+		t.Errorf("Checked = %d, want 4 content files", result.Checked)
+	}
+	kinds := issueKinds(result)
+	if len(kinds) != 3 || kinds[digest[2:]] != CacheIssueCorrupt || kinds["3456-index.json"] != CacheIssueCorrupt {
+		t.Fatalf("issues = %+v, want the corrupt file, its index and the orphan", result.Issues)
+	}
+
+	if err := RepairCache(result); err != nil {
+		t.Fatalf("RepairCache() error = %v", err)
+	}
+	result, _ = VerifyCache("pnpm", store)
+	if len(result.Issues) != 0 {
+		t.Errorf("after repair issues = %+v", result.Issues)
+	}
+}
+
+func TestVerifyCacheYarn(t *testing.T) {
+	root := t.TempDir()
+
+	good := filepath.Join(root, "lodash-npm-4.17.21-6382451519-eb835a2e51.zip")
+	f, _ := os.Create(good)
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("node_modules/lodash/package.json")
+	w.Write([]byte(`{"name":"lodash","version":"4.17.21"}`))
+	zw.Close()
+	f.Close()
+
+	data, _ := os.ReadFile(good)
+	bad := filepath.Join(root, "react-npm-18.2.0-1234567890-abcdef0123.zip")
+	os.WriteFile(bad, data[:len(data)/2], 0644)
+
+	// A yarn 1 package whose extraction never finished
+	os.MkdirAll(filepath.Join(root, "v6", "npm-vue-3.4.0-abc-integrity", "node_modules", "vue"), 0755)
+
+	result, err := VerifyCache("yarn", root)
+	if err != nil {
+		t.Fatalf("VerifyCache() error = %v", err)
+	}
+	kinds := issueKinds(result)
+	if result.Checked != 3 || len(kinds) != 2 || kinds[filepath.Base(bad)] == "" || kinds["npm-vue-3.4.0-abc-integrity"] == "" {
+		t.Errorf("VerifyCache() = %d checked, issues %+v", result.Checked, result.Issues)
+	}
+
+	if _, err := VerifyCache("bun", root); err != core.ErrNotSupported {
+		t.Errorf("VerifyCache(bun) error = %v, want ErrNotSupported", err)
+	}
+}
@@ -0,0 +1,372 @@
+package managers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"npm-console/internal/core"
+	"npm-console/pkg/utils"
+)
+
+// ConfigLevel identifies which of a manager's config files a setting lives in
+type ConfigLevel string
+
+const (
+	LevelProject ConfigLevel = "project"
+	LevelUser    ConfigLevel = "user"
+	LevelGlobal  ConfigLevel = "global"
+)
+
+// ConfigFormat identifies the syntax of a native config file
+type ConfigFormat string
+
+const (
+	FormatNpmrc     ConfigFormat = "npmrc"      // ini-style key=value (.npmrc, pnpm rc)
+	FormatYarnrc    ConfigFormat = "yarnrc"     // yarn 1 `key "value"` lines
+	FormatYarnrcYml ConfigFormat = "yarnrc.yml" // yarn 2+ YAML
+	FormatBunfig    ConfigFormat = "bunfig"     // bunfig.toml
+)
+
+// ConfigFile is one native config file of a package manager. Values are read
+// and written with npm's key names ("registry", "proxy", "https-proxy",
+// "@scope:registry", "//host/path/:_authToken", ...) whatever the format, so
+// callers need not know each manager's syntax. Edits are made in place and
+// keep comments and ordering.
+type ConfigFile struct {
+	Manager string       `json:"manager"`
+	Level   ConfigLevel  `json:"level"`
+	Format  ConfigFormat `json:"format"`
+	Path    string       `json:"path"`
+}
+
+// ConfigValue is a resolved setting and the file it came from
+type ConfigValue struct {
+	Key   string     `json:"key"`
+	Value string     `json:"value"`
+	File  ConfigFile `json:"file"`
+}
+
+// Exists reports whether the file is present on disk
+func (f ConfigFile) Exists() bool {
+	return f.Path != "" && utils.IsFile(f.Path)
+}
+
+// Read returns the settings of the file. Environment references are left
+// unexpanded and a missing file yields no settings.
+func (f ConfigFile) Read() (map[string]string, error) {
+	if f.Path == "" {
+		return map[string]string{}, nil
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	return f.Parse(data)
+}
+
+// Parse reads settings from the contents of a file in this file's format
+func (f ConfigFile) Parse(data []byte) (map[string]string, error) {
+	var values map[string]string
+	var err error
+	switch f.Format {
+	case FormatNpmrc:
+		values = parseNpmrcRaw(data)
+	case FormatYarnrc:
+		values = parseYarnClassic(data)
+	case FormatYarnrcYml:
+		values, err = readYarnrcValues(data)
+	case FormatBunfig:
+		values, err = readBunfigValues(data)
+	default:
+		return nil, fmt.Errorf("unknown config format: %s", f.Format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", f.Path, err)
+	}
+	return values, nil
+}
+
+// Accepts reports whether the format can store a key. Yarn 1 reads auth
+// tokens from .npmrc only.
+func (f ConfigFile) Accepts(key string) bool {
+	if f.Format == FormatYarnrc {
+		return !strings.HasSuffix(key, "_authToken")
+	}
+	return true
+}
+
+// configFileLocks holds a mutex per config file path. Several managers share
+// a file (npm and pnpm both write the user .npmrc), and each write reads,
+// edits and rewrites the whole file.
+var configFileLocks sync.Map
+
+// lockConfigFile locks a config file for a read-modify-write cycle and
+// returns the function that unlocks it
+func lockConfigFile(path string) func() {
+	value, _ := configFileLocks.LoadOrStore(filepath.Clean(path), &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// Set writes one setting; an empty value removes it
+func (f ConfigFile) Set(key, value string) error {
+	if f.Path == "" {
+		return fmt.Errorf("%s has no %s config file", f.Manager, f.Level)
+	}
+	defer lockConfigFile(f.Path)()
+
+	switch f.Format {
+	case FormatNpmrc:
+		return setNpmrcValue(f.Path, key, value)
+	case FormatYarnrc:
+		return setYarnClassicValue(f.Path, key, value)
+	case FormatYarnrcYml:
+		return setYarnrcValue(f.Path, key, value)
+	case FormatBunfig:
+		return setBunfigValue(f.Path, key, value)
+	default:
+		return fmt.Errorf("unknown config format: %s", f.Format)
+	}
+}
+
+// ConfigFiles returns the config files a manager reads for a project, most
+// specific first. For yarn, berry selects .yarnrc.yml over yarn 1's .yarnrc
+// and .npmrc. projectPath may be empty to skip project files.
+func ConfigFiles(manager, projectPath string, berry bool) []ConfigFile {
+	var files []ConfigFile
+	add := func(level ConfigLevel, format ConfigFormat, path string) {
+		if path != "" {
+			files = append(files, ConfigFile{Manager: manager, Level: level, Format: format, Path: path})
+		}
+	}
+
+	project := func(name string) string {
+		if projectPath == "" {
+			return ""
+		}
+		return filepath.Join(projectPath, name)
+	}
+	home, _ := utils.GetHomeDir()
+	userNpmrc, _ := UserNpmrcPath()
+
+	switch manager {
+	case "npm":
+		add(LevelProject, FormatNpmrc, project(".npmrc"))
+		add(LevelUser, FormatNpmrc, userNpmrc)
+		add(LevelGlobal, FormatNpmrc, npmGlobalConfigPath())
+	case "pnpm":
+		add(LevelProject, FormatNpmrc, project(".npmrc"))
+		add(LevelUser, FormatNpmrc, userNpmrc)
+		add(LevelGlobal, FormatNpmrc, pnpmGlobalConfigPath())
+	case "yarn":
+		if berry {
+			add(LevelProject, FormatYarnrcYml, project(".yarnrc.yml"))
+			if home != "" {
+				add(LevelUser, FormatYarnrcYml, filepath.Join(home, ".yarnrc.yml"))
+			}
+			break
+		}
+		add(LevelProject, FormatYarnrc, project(".yarnrc"))
+		add(LevelProject, FormatNpmrc, project(".npmrc"))
+		if home != "" {
+			add(LevelUser, FormatYarnrc, filepath.Join(home, ".yarnrc"))
+		}
+		add(LevelUser, FormatNpmrc, userNpmrc)
+	case "bun":
+		add(LevelProject, FormatBunfig, project("bunfig.toml"))
+		if path, err := BunfigPath(); err == nil {
+			add(LevelUser, FormatBunfig, path)
+		}
+	}
+
+	return files
+}
+
+// UserConfigFiles returns the user-level config files of every manager, the
+// files npm-console changes, each path once
+func UserConfigFiles() []ConfigFile {
+	var files []ConfigFile
+	seen := make(map[string]bool)
+
+	add := func(candidates []ConfigFile) {
+		for _, file := range candidates {
+			if file.Level == LevelUser && !seen[file.Path] {
+				seen[file.Path] = true
+				files = append(files, file)
+			}
+		}
+	}
+	for _, manager := range []string{"npm", "pnpm", "yarn", "bun"} {
+		add(ConfigFiles(manager, "", false))
+	}
+	add(ConfigFiles("yarn", "", true))
+
+	return files
+}
+
+// ResolveConfig merges config files, the first file setting a key wins
+func ResolveConfig(files []ConfigFile) map[string]ConfigValue {
+	resolved := make(map[string]ConfigValue)
+	for _, file := range files {
+		values, err := file.Read()
+		if err != nil {
+			continue
+		}
+		for key, value := range values {
+			if _, ok := resolved[key]; !ok {
+				resolved[key] = ConfigValue{Key: key, Value: value, File: file}
+			}
+		}
+	}
+	return resolved
+}
+
+// WritableFile returns the first file at a level that can store key
+func WritableFile(files []ConfigFile, level ConfigLevel, key string) (ConfigFile, error) {
+	for _, file := range files {
+		if file.Level == level && file.Accepts(key) {
+			return file, nil
+		}
+	}
+	return ConfigFile{}, fmt.Errorf("no %s config file can store %s", level, key)
+}
+
+// setConfigLine sets the line holding key in a line-based config file,
+// keeping comments and the order of the other lines. keyOf returns the key of
+// a line, if any; an empty line removes the key.
+func setConfigLine(path, key, line string, keyOf func(line string) (string, bool)) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+
+	found := false
+	kept := lines[:0]
+	for _, existing := range lines {
+		if k, ok := keyOf(existing); ok && k == key {
+			if line == "" || found {
+				continue
+			}
+			existing = line
+			found = true
+		}
+		kept = append(kept, existing)
+	}
+	lines = kept
+
+	if !found && line != "" {
+		lines = append(lines, line)
+	}
+
+	if err := utils.MakeDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+}
+
+// setProxyValues writes proxy, https-proxy and noproxy; empty values remove
+// the keys
+func setProxyValues(files []ConfigFile, proxy core.ProxySettings) error {
+	values := map[string]string{
+		"proxy":       proxy.HTTP,
+		"https-proxy": proxy.HTTPS,
+		"noproxy":     strings.Join(proxy.NoProxy, ","),
+	}
+	for _, key := range []string{"proxy", "https-proxy", "noproxy"} {
+		if err := setConfigValue(files, key, values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setConfigValue writes a setting to the manager's user-level config file
+func setConfigValue(files []ConfigFile, key, value string) error {
+	file, err := WritableFile(files, LevelUser, key)
+	if err != nil {
+		return err
+	}
+	return file.Set(key, value)
+}
+
+// scopedRegistriesFromFiles returns the "@scope" -> registry mappings of config files
+func scopedRegistriesFromFiles(files []ConfigFile) map[string]string {
+	scopes := make(map[string]string)
+	for key, value := range ResolveConfig(files) {
+		if strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry") {
+			scopes[strings.TrimSuffix(key, ":registry")] = expandEnv(value.Value)
+		}
+	}
+	return scopes
+}
+
+// npmPrefix returns the npm global prefix: NPM_CONFIG_PREFIX, or the
+// installation directory of node
+func npmPrefix() string {
+	if prefix := os.Getenv("NPM_CONFIG_PREFIX"); prefix != "" {
+		return prefix
+	}
+
+	node, err := exec.LookPath("node")
+	if err != nil {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(node); err == nil {
+		node = resolved
+	}
+
+	if runtime.GOOS == "windows" {
+		return filepath.Dir(node)
+	}
+	return filepath.Dir(filepath.Dir(node))
+}
+
+// npmGlobalConfigPath returns the global npmrc, {prefix}/etc/npmrc
+func npmGlobalConfigPath() string {
+	if path := os.Getenv("NPM_CONFIG_GLOBALCONFIG"); path != "" {
+		return path
+	}
+
+	prefix := npmPrefix()
+	if prefix == "" {
+		return ""
+	}
+	return filepath.Join(prefix, "etc", "npmrc")
+}
+
+// pnpmGlobalConfigPath returns the rc file written by "pnpm config set --global"
+func pnpmGlobalConfigPath() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "pnpm", "rc")
+	}
+
+	home, err := utils.GetHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(os.Getenv("LOCALAPPDATA"), "pnpm", "config", "rc")
+	case "darwin":
+		return filepath.Join(home, "Library", "Preferences", "pnpm", "rc")
+	default: // linux and others
+		return filepath.Join(home, ".config", "pnpm", "rc")
+	}
+}
@@ -0,0 +1,227 @@
+package managers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"npm-console/internal/core"
+)
+
+func TestResolveConfig(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	global := filepath.Join(t.TempDir(), "npmrc")
+	t.Setenv("HOME", home)
+	t.Setenv("NPM_CONFIG_USERCONFIG", "")
+	t.Setenv("NPM_CONFIG_GLOBALCONFIG", global)
+
+	os.WriteFile(global, []byte("registry=https://global.local/\nstrict-ssl=false\n"), 0644)
+	os.WriteFile(filepath.Join(home, ".npmrc"), []byte("registry=https://user.local/\nproxy=http://proxy.local:8080\n"), 0644)
+	os.WriteFile(filepath.Join(project, ".npmrc"), []byte("registry=https://project.local/\n"), 0644)
+
+	files := ConfigFiles("npm", project, false)
+	if len(files) != 3 || files[0].Level != LevelProject || files[2].Level != LevelGlobal {
+		t.Fatalf("ConfigFiles() = %+v", files)
+	}
+
+	resolved := ResolveConfig(files)
+	if value := resolved["registry"]; value.Value != "https://project.local/" || value.File.Level != LevelProject {
+		t.Errorf("registry = %+v, want the project value", value)
+	}
+	if value := resolved["proxy"]; value.File.Level != LevelUser {
+		t.Errorf("proxy = %+v, want the user value", value)
+	}
+	if value := resolved["strict-ssl"]; value.Value != "false" || value.File.Path != global {
+		t.Errorf("strict-ssl = %+v, want the global value", value)
+	}
+}
+
+func TestYarnClassicConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".yarnrc")
+	os.WriteFile(path, []byte(`# yarn lockfile v1
+registry "https://registry.yarnpkg.com"
+"@corp:registry" "https://npm.corp.local/"
+`), 0644)
+
+	file := ConfigFile{Manager: "yarn", Level: LevelUser, Format: FormatYarnrc, Path: path}
+	if err := file.Set("registry", "https://mirror.local/"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := file.Set("@other:registry", "https://other.local/"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	values, err := file.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if values["registry"] != "https://mirror.local/" || values["@corp:registry"] != "https://npm.corp.local/" || values["@other:registry"] != "https://other.local/" {
+		t.Errorf("values = %v", values)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	if !strings.HasPrefix(content, "# yarn lockfile v1\nregistry ") || !strings.Contains(content, `"@other:registry" "https://other.local/"`) {
+		t.Errorf(".yarnrc = %s", content)
+	}
+
+	if file.Accepts("//npm.corp.local/:_authToken") {
+		t.Error(".yarnrc should not accept auth tokens")
+	}
+}
+
+func TestYarnrcYmlConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".yarnrc.yml")
+	os.WriteFile(path, []byte(`# shared settings
+nodeLinker: node-modules
+npmRegistryServer: "https://registry.yarnpkg.com"
+`), 0644)
+
+	file := ConfigFile{Manager: "yarn", Level: LevelUser, Format: FormatYarnrcYml, Path: path}
+	for key, value := range map[string]string{
+		"https-proxy":                  "http://proxy.local:8080",
+		"@corp:registry":               "https://npm.corp.local",
+		"//npm.corp.local/:_authToken": "${CORP_TOKEN}",
+	} {
+		if err := file.Set(key, value); err != nil {
+			t.Fatalf("Set(%s) error = %v", key, err)
+		}
+	}
+
+	values, err := file.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if values["registry"] != "https://registry.yarnpkg.com" || values["nodeLinker"] != "node-modules" {
+		t.Errorf("values = %v", values)
+	}
+	if values["https-proxy"] != "http://proxy.local:8080" || values["//npm.corp.local/:_authToken"] != "${CORP_TOKEN}" {
+		t.Errorf("values = %v", values)
+	}
+
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{"# shared settings", "httpsProxy:", "npmRegistries:"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf(".yarnrc.yml missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestBunManagerWritesBunfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("http_proxy", "")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("https_proxy", "")
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("no_proxy", "")
+	t.Chdir(t.TempDir())
+
+	path := filepath.Join(home, ".bunfig.toml")
+	os.WriteFile(path, []byte(`# global bun settings
+[install]
+registry = { url = "https://registry.npmjs.org/", token = "$NPM_TOKEN" }
+
+[run]
+bun = true
+`), 0644)
+
+	ctx := context.Background()
+	bun := NewBunManager()
+	if err := bun.SetRegistry(ctx, "https://mirror.local/"); err != nil {
+		t.Fatalf("SetRegistry() error = %v", err)
+	}
+	if err := bun.SetProxy(ctx, core.ProxySettings{HTTP: "http://proxy.local:8080", HTTPS: "http://proxy.local:8443", NoProxy: []string{"corp.local"}}); err != nil {
+		t.Fatalf("SetProxy() error = %v", err)
+	}
+
+	config, err := bun.GetConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
+	if config.Registry != "https://mirror.local/" || config.Proxy != "http://proxy.local:8080" || config.HTTPSProxy != "http://proxy.local:8443" {
+		t.Errorf("config = %+v", config)
+	}
+	if len(config.NoProxy) != 1 || config.NoProxy[0] != "corp.local" {
+		t.Errorf("config = %+v", config)
+	}
+	if config.Settings["global-bunfig"] != path {
+		t.Errorf("Settings = %v", config.Settings)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	if !strings.Contains(content, `token = "$NPM_TOKEN"`) || !strings.Contains(content, "# global bun settings") {
+		t.Errorf("bunfig.toml lost its token or comment:\n%s", content)
+	}
+	if strings.Index(content, "proxy =") > strings.Index(content, "[run]") {
+		t.Errorf("proxy was not added to [install]:\n%s", content)
+	}
+
+	if err := bun.SetProxy(ctx, core.ProxySettings{}); err != nil {
+		t.Fatalf("SetProxy() error = %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "proxy") {
+		t.Errorf("proxy was not removed:\n%s", data)
+	}
+}
+
+func TestConcurrentWritesToSharedNpmrc(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("NPM_CONFIG_USERCONFIG", "")
+	npmrc := filepath.Join(home, ".npmrc")
+	os.WriteFile(npmrc, []byte("//corp/:_authToken=secret\n"), 0600)
+
+	// npm and pnpm both write the user .npmrc
+	proxy := core.ProxySettings{HTTP: "http://proxy.local:8080", NoProxy: []string{"corp"}}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for _, manager := range []string{"npm", "pnpm"} {
+			wg.Add(1)
+			go func(manager string, i int) {
+				defer wg.Done()
+				files := ConfigFiles(manager, "", false)
+				if err := setProxyValues(files, proxy); err != nil {
+					t.Errorf("setProxyValues(%s) error = %v", manager, err)
+				}
+				if err := setConfigValue(files, fmt.Sprintf("%s-key-%d", manager, i), "set"); err != nil {
+					t.Errorf("setConfigValue(%s) error = %v", manager, err)
+				}
+			}(manager, i)
+		}
+	}
+	wg.Wait()
+
+	values := parseNpmrcRaw(mustReadFile(t, npmrc))
+	if values["//corp/:_authToken"] != "secret" {
+		t.Errorf("token lost after concurrent writes: %v", values)
+	}
+	if values["proxy"] != proxy.HTTP || values["noproxy"] != "corp" {
+		t.Errorf("proxy = %q, noproxy = %q", values["proxy"], values["noproxy"])
+	}
+	for i := 0; i < 20; i++ {
+		for _, manager := range []string{"npm", "pnpm"} {
+			if key := fmt.Sprintf("%s-key-%d", manager, i); values[key] != "set" {
+				t.Errorf("%s lost after concurrent writes", key)
+			}
+		}
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s) error = %v", path, err)
+	}
+	return data
+}
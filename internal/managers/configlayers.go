@@ -0,0 +1,193 @@
+package managers
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"npm-console/internal/core"
+)
+
+// envSetting is a config value taken from an environment variable
+type envSetting struct {
+	name  string
+	value string
+}
+
+// envConfig returns the settings a manager reads from environment variables,
+// keyed like its config files
+func envConfig(manager string, berry bool) map[string]envSetting {
+	settings := make(map[string]envSetting)
+
+	// The first variable found for a key wins, so prefixes go most specific first
+	addPrefix := func(prefix string, toKey func(rest string) string) {
+		for _, variable := range os.Environ() {
+			name, value, _ := strings.Cut(variable, "=")
+			if len(name) <= len(prefix) || !strings.EqualFold(name[:len(prefix)], prefix) || value == "" {
+				continue
+			}
+			key := toKey(name[len(prefix):])
+			if _, ok := settings[key]; !ok {
+				settings[key] = envSetting{name: name, value: value}
+			}
+		}
+	}
+	addVar := func(key string, names ...string) {
+		for _, name := range names {
+			if value := os.Getenv(name); value != "" {
+				if _, ok := settings[key]; !ok {
+					settings[key] = envSetting{name: name, value: value}
+				}
+				return
+			}
+		}
+	}
+
+	switch manager {
+	case "npm":
+		addPrefix("npm_config_", npmEnvKey)
+	case "pnpm":
+		addPrefix("pnpm_config_", npmEnvKey)
+		addPrefix("npm_config_", npmEnvKey)
+	case "yarn":
+		if berry {
+			addPrefix("YARN_", berryEnvKey)
+		} else {
+			addPrefix("yarn_", npmEnvKey)
+			addPrefix("npm_config_", npmEnvKey)
+		}
+	case "bun":
+		addVar("registry", "BUN_CONFIG_REGISTRY", "NPM_CONFIG_REGISTRY")
+		addVar("_authToken", "BUN_CONFIG_TOKEN")
+		addVar("cache", "BUN_INSTALL_CACHE_DIR")
+		addVar("proxy", "HTTP_PROXY", "http_proxy")
+		addVar("https-proxy", "HTTPS_PROXY", "https_proxy")
+		addVar("noproxy", "NO_PROXY", "no_proxy")
+	}
+
+	return settings
+}
+
+// npmEnvKey turns the suffix of an npm_config_ variable into its key
+func npmEnvKey(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// berryEnvKey turns the suffix of a YARN_ variable, e.g. NPM_REGISTRY_SERVER,
+// into the npm style key of the .yarnrc.yml setting it overrides
+func berryEnvKey(name string) string {
+	words := strings.Split(strings.ToLower(name), "_")
+	for i := 1; i < len(words); i++ {
+		if words[i] != "" {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+
+	key := strings.Join(words, "")
+	if npmKey, ok := yarnrcKeys[key]; ok {
+		return npmKey
+	}
+	return key
+}
+
+// builtinConfig turns a manager's defaults into builtin config sources
+func builtinConfig(defaults map[string]string) map[string]core.ConfigSource {
+	builtin := make(map[string]core.ConfigSource)
+	for key, value := range defaults {
+		if value != "" {
+			builtin[key] = core.ConfigSource{Layer: core.LayerBuiltin, Value: value}
+		}
+	}
+	return builtin
+}
+
+// addProxyEnvDefaults adds the proxy defaults npm, pnpm and yarn 1 take from
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+func addProxyEnvDefaults(builtin map[string]core.ConfigSource) {
+	defaults := map[string][]string{
+		"proxy":       {"HTTP_PROXY", "http_proxy"},
+		"https-proxy": {"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"},
+		"noproxy":     {"NO_PROXY", "no_proxy"},
+	}
+	for key, names := range defaults {
+		for _, name := range names {
+			if value := os.Getenv(name); value != "" {
+				builtin[key] = core.ConfigSource{Layer: core.LayerBuiltin, Source: name, Value: value}
+				break
+			}
+		}
+	}
+}
+
+// explainConfig resolves every key of a manager through its layers: values
+// passed on the command line, environment variables, config files (most
+// specific first) and builtin defaults. Values are returned as written, with
+// environment references unexpanded.
+func explainConfig(manager string, files []ConfigFile, env map[string]envSetting, builtin map[string]core.ConfigSource, cli map[string]string) []core.ConfigEntry {
+	sources := make(map[string][]core.ConfigSource)
+
+	for key, value := range cli {
+		sources[key] = append(sources[key], core.ConfigSource{Layer: core.LayerCLI, Value: value})
+	}
+	for key, setting := range env {
+		sources[key] = append(sources[key], core.ConfigSource{Layer: core.LayerEnv, Source: setting.name, Value: setting.value})
+	}
+	for _, file := range files {
+		values, err := file.Read()
+		if err != nil {
+			continue
+		}
+		for key, value := range values {
+			sources[key] = append(sources[key], core.ConfigSource{Layer: core.ConfigLayer(file.Level), Source: file.Path, Value: value})
+		}
+	}
+	for key, source := range builtin {
+		sources[key] = append(sources[key], source)
+	}
+
+	keys := make([]string, 0, len(sources))
+	for key := range sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]core.ConfigEntry, 0, len(keys))
+	for _, key := range keys {
+		effective := sources[key][0]
+		entries = append(entries, core.ConfigEntry{
+			Manager:  manager,
+			Key:      key,
+			Value:    effective.Value,
+			Layer:    effective.Layer,
+			Source:   effective.Source,
+			Shadowed: sources[key][1:],
+		})
+	}
+	return entries
+}
+
+// configFromEntries builds a manager configuration from its resolved entries,
+// reporting the listed settings that have a value
+func configFromEntries(manager string, entries []core.ConfigEntry, settings ...string) *core.Config {
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		values[entry.Key] = expandEnv(entry.Value)
+	}
+
+	config := &core.Config{
+		Manager:    manager,
+		Registry:   values["registry"],
+		Proxy:      values["proxy"],
+		HTTPSProxy: values["https-proxy"],
+		NoProxy:    core.ParseNoProxy(values["noproxy"]),
+		Settings:   make(map[string]string),
+	}
+
+	for _, key := range settings {
+		if value := values[key]; value != "" {
+			config.Settings[key] = value
+		}
+	}
+
+	return config
+}
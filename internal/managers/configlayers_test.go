@@ -0,0 +1,64 @@
+package managers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"npm-console/internal/core"
+)
+
+func TestExplainConfig(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("NPM_CONFIG_USERCONFIG", "")
+	t.Setenv("NPM_CONFIG_GLOBALCONFIG", filepath.Join(t.TempDir(), "npmrc"))
+	t.Setenv("npm_config_strict_ssl", "false")
+	t.Setenv("HTTPS_PROXY", "http://env-proxy.local:3128")
+
+	os.WriteFile(filepath.Join(home, ".npmrc"), []byte("registry=https://user.local/\n"), 0644)
+	os.WriteFile(filepath.Join(project, ".npmrc"), []byte("registry=https://project.local/\n"), 0644)
+
+	entries, err := NewNPMManager().ExplainConfig(context.Background(), project, map[string]string{"cache": "/tmp/cli-cache"})
+	if err != nil {
+		t.Fatalf("ExplainConfig() error = %v", err)
+	}
+
+	byKey := make(map[string]core.ConfigEntry)
+	for _, entry := range entries {
+		byKey[entry.Key] = entry
+	}
+
+	registry := byKey["registry"]
+	if registry.Value != "https://project.local/" || registry.Layer != core.LayerProject {
+		t.Errorf("registry = %+v, want the project value", registry)
+	}
+	if len(registry.Shadowed) != 2 || registry.Shadowed[0].Layer != core.LayerUser || registry.Shadowed[1].Layer != core.LayerBuiltin {
+		t.Errorf("registry shadows %+v, want user then builtin", registry.Shadowed)
+	}
+
+	if entry := byKey["strict-ssl"]; entry.Layer != core.LayerEnv || entry.Source != "npm_config_strict_ssl" {
+		t.Errorf("strict-ssl = %+v, want the environment value", entry)
+	}
+	if entry := byKey["cache"]; entry.Layer != core.LayerCLI || entry.Shadowed[0].Layer != core.LayerBuiltin {
+		t.Errorf("cache = %+v, want the command line value over the default", entry)
+	}
+	if entry := byKey["https-proxy"]; entry.Layer != core.LayerBuiltin || entry.Source != "HTTPS_PROXY" {
+		t.Errorf("https-proxy = %+v, want the HTTPS_PROXY default", entry)
+	}
+}
+
+func TestBerryEnvKey(t *testing.T) {
+	tests := map[string]string{
+		"NPM_REGISTRY_SERVER": "registry",
+		"HTTPS_PROXY":         "https-proxy",
+		"CACHE_FOLDER":        "cacheFolder",
+	}
+	for name, want := range tests {
+		if got := berryEnvKey(name); got != want {
+			t.Errorf("berryEnvKey(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package managers
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"npm-console/internal/core"
+	"npm-console/internal/registry"
+	"npm-console/pkg/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yarnBerryDefaultRegistry is the registry yarn 2+ uses when none is configured
+const yarnBerryDefaultRegistry = "https://registry.yarnpkg.com"
+
+// envReferencePattern matches a value that only references an environment
+// variable, as ${VAR} (npm, yarn) or $VAR (bun)
+var envReferencePattern = regexp.MustCompile(`^\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?$`)
+
+// credentialSet collects the registries of a config file keyed by nerf-dart
+type credentialSet struct {
+	manager string
+	source  string
+	order   []string
+	byKey   map[string]*core.Credential
+}
+
+func newCredentialSet(manager, source string) *credentialSet {
+	return &credentialSet{
+		manager: manager,
+		source:  source,
+		byKey:   make(map[string]*core.Credential),
+	}
+}
+
+// registry returns the entry for a registry, adding it if needed
+func (c *credentialSet) registry(registryURL string) *core.Credential {
+	key := NerfDart(registryURL)
+	if cred, ok := c.byKey[key]; ok {
+		return cred
+	}
+
+	cred := &core.Credential{Manager: c.manager, Registry: registryURL}
+	c.byKey[key] = cred
+	c.order = append(c.order, key)
+	return cred
+}
+
+// scope records that a scope is routed to a registry
+func (c *credentialSet) scope(scope, registryURL string) {
+	cred := c.registry(registryURL)
+	cred.Scopes = append(cred.Scopes, NormalizeScope(scope))
+	sort.Strings(cred.Scopes)
+}
+
+// token records the raw token value of a registry in masked form
+func (c *credentialSet) token(registryURL, raw string) {
+	if raw == "" {
+		return
+	}
+
+	cred := c.registry(registryURL)
+	cred.HasToken = true
+	cred.Source = c.source
+	if match := envReferencePattern.FindStringSubmatch(raw); match != nil {
+		cred.EnvVar = match[1]
+		cred.Token = utils.MaskSecret(os.Getenv(match[1]))
+	} else {
+		cred.Token = utils.MaskSecret(raw)
+	}
+}
+
+// list returns the collected credentials in the order they were found
+func (c *credentialSet) list() []core.Credential {
+	credentials := make([]core.Credential, 0, len(c.order))
+	for _, key := range c.order {
+		credentials = append(credentials, *c.byKey[key])
+	}
+	return credentials
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// npmrcCredentials lists the registries and tokens of the user .npmrc used by
+// npm, pnpm and yarn 1
+func npmrcCredentials(manager string) ([]core.Credential, error) {
+	path, err := UserNpmrcPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	values := parseNpmrcRaw(data)
+
+	set := newCredentialSet(manager, path)
+	defaultRegistry := registry.DefaultRegistry
+	if values["registry"] != "" {
+		defaultRegistry = values["registry"]
+	}
+	set.registry(defaultRegistry)
+
+	for _, key := range sortedKeys(values) {
+		if strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry") {
+			set.scope(strings.TrimSuffix(key, ":registry"), values[key])
+		}
+	}
+
+	set.token(defaultRegistry, values["_authToken"])
+	for _, key := range sortedKeys(values) {
+		if strings.HasPrefix(key, "//") && strings.HasSuffix(key, ":_authToken") {
+			nerfed := strings.TrimSuffix(key, ":_authToken")
+			// Prefer the full URL of a registry already listed
+			registryURL := nerfed
+			if cred, ok := set.byKey[nerfed]; ok {
+				registryURL = cred.Registry
+			}
+			set.token(registryURL, values[key])
+		}
+	}
+
+	return set.list(), nil
+}
+
+// setNpmrcAuthToken stores a registry token in the user .npmrc
+func setNpmrcAuthToken(registryURL, token, envVar string) error {
+	if envVar != "" {
+		token = "${" + envVar + "}"
+	}
+	return setConfigValue(ConfigFiles("npm", "", false), NerfDart(registryURL)+":_authToken", token)
+}
+
+// yarnrcCredentials lists the registries and tokens of the user .yarnrc.yml
+func yarnrcCredentials() ([]core.Credential, error) {
+	path, err := YarnrcPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var rc yarnrc
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &rc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	set := newCredentialSet("yarn", path)
+	defaultRegistry := yarnBerryDefaultRegistry
+	if rc.NpmRegistryServer != "" {
+		defaultRegistry = rc.NpmRegistryServer
+	}
+	set.registry(defaultRegistry)
+
+	for _, scope := range sortedKeys(rc.NpmScopes) {
+		cfg := rc.NpmScopes[scope]
+		if cfg.NpmRegistryServer != "" {
+			set.scope(scope, cfg.NpmRegistryServer)
+			set.token(cfg.NpmRegistryServer, cfg.NpmAuthToken)
+		}
+	}
+
+	set.token(defaultRegistry, rc.NpmAuthToken)
+	for _, server := range sortedKeys(rc.NpmRegistries) {
+		set.token(server, rc.NpmRegistries[server].NpmAuthToken)
+	}
+
+	return set.list(), nil
+}
+
+// setYarnrcAuthToken stores a registry token in the user .yarnrc.yml: as
+// npmAuthToken for the default registry, otherwise under npmRegistries
+func setYarnrcAuthToken(registryURL, token, envVar string) error {
+	if envVar != "" {
+		token = "${" + envVar + "}"
+	}
+	return setConfigValue(ConfigFiles("yarn", "", true), NerfDart(registryURL)+":_authToken", token)
+}
+
+// bunfigCredentials lists the registries and tokens of the user bunfig.toml
+func bunfigCredentials() ([]core.Credential, error) {
+	path, err := BunfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := readBunfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	set := newCredentialSet("bun", path)
+	defaultRegistry := bunRegistry{URL: registry.DefaultRegistry}
+	if entry, ok := parseBunRegistry(cfg.Install.Registry); ok {
+		defaultRegistry = entry
+	}
+	set.registry(defaultRegistry.URL)
+
+	for _, scope := range sortedKeys(cfg.Install.Scopes) {
+		if entry, ok := parseBunRegistry(cfg.Install.Scopes[scope]); ok {
+			set.scope(scope, entry.URL)
+			set.token(entry.URL, entry.Token)
+		}
+	}
+	set.token(defaultRegistry.URL, defaultRegistry.Token)
+
+	return set.list(), nil
+}
+
+// setBunfigAuthToken stores a registry token in the user bunfig.toml
+func setBunfigAuthToken(registryURL, token, envVar string) error {
+	if envVar != "" {
+		token = "$" + envVar
+	}
+	return setConfigValue(ConfigFiles("bun", "", false), NerfDart(registryURL)+":_authToken", token)
+}
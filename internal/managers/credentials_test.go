@@ -0,0 +1,131 @@
+package managers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNpmrcCredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("NPM_CONFIG_USERCONFIG", "")
+	t.Setenv("CORP_TOKEN", "corp-secret-1234")
+
+	path := filepath.Join(home, ".npmrc")
+	os.WriteFile(path, []byte(`# company settings
+registry=https://registry.npmjs.org/
+@ourco:registry=https://npm.corp.local/
+`), 0600)
+
+	if err := setNpmrcAuthToken("https://npm.corp.local/", "", "CORP_TOKEN"); err != nil {
+		t.Fatalf("setNpmrcAuthToken() error = %v", err)
+	}
+	if err := setNpmrcAuthToken("https://registry.npmjs.org/", "npm_abcdefghijklmnop", ""); err != nil {
+		t.Fatalf("setNpmrcAuthToken() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	if !strings.HasPrefix(content, "# company settings\n") || !strings.Contains(content, "//npm.corp.local/:_authToken=${CORP_TOKEN}") {
+		t.Errorf(".npmrc = %s", content)
+	}
+
+	credentials, err := npmrcCredentials("npm")
+	if err != nil {
+		t.Fatalf("npmrcCredentials() error = %v", err)
+	}
+	if len(credentials) != 2 {
+		t.Fatalf("credentials = %+v", credentials)
+	}
+
+	npmjs, corp := credentials[0], credentials[1]
+	if npmjs.Registry != "https://registry.npmjs.org/" || npmjs.Token != "********mnop" {
+		t.Errorf("npmjs credential = %+v", npmjs)
+	}
+	if corp.EnvVar != "CORP_TOKEN" || corp.Token != "********1234" || len(corp.Scopes) != 1 || corp.Scopes[0] != "@ourco" {
+		t.Errorf("corp credential = %+v", corp)
+	}
+	for _, credential := range credentials {
+		if strings.Contains(credential.Token, "abcdefgh") || strings.Contains(credential.Token, "corp-secret") {
+			t.Errorf("credential leaks its token: %+v", credential)
+		}
+	}
+
+	// Removing a token leaves the rest of the file alone
+	if err := setNpmrcAuthToken("https://registry.npmjs.org/", "", ""); err != nil {
+		t.Fatalf("setNpmrcAuthToken() error = %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "registry.npmjs.org/:_authToken") || !strings.Contains(string(data), "@ourco:registry") {
+		t.Errorf(".npmrc after removal = %s", data)
+	}
+}
+
+func TestYarnrcCredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	os.WriteFile(filepath.Join(home, ".yarnrc.yml"), []byte(`npmScopes:
+  ourco:
+    npmRegistryServer: "https://npm.corp.local"
+`), 0600)
+
+	if err := setYarnrcAuthToken("https://registry.yarnpkg.com/", "yarn-token-abcd", ""); err != nil {
+		t.Fatalf("setYarnrcAuthToken() error = %v", err)
+	}
+	if err := setYarnrcAuthToken("https://npm.corp.local/", "", "CORP_TOKEN"); err != nil {
+		t.Fatalf("setYarnrcAuthToken() error = %v", err)
+	}
+
+	credentials, err := yarnrcCredentials()
+	if err != nil {
+		t.Fatalf("yarnrcCredentials() error = %v", err)
+	}
+	if len(credentials) != 2 || credentials[0].Token != "********abcd" || credentials[1].EnvVar != "CORP_TOKEN" {
+		t.Errorf("credentials = %+v", credentials)
+	}
+
+	// The token is picked up when talking to the registry
+	t.Setenv("CORP_TOKEN", "secret")
+	settings := LoadRegistrySettings("yarn", "")
+	if settings.Tokens["//npm.corp.local/"] != "secret" {
+		t.Errorf("Tokens = %v", settings.Tokens)
+	}
+}
+
+func TestBunfigCredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	path := filepath.Join(home, ".bunfig.toml")
+	os.WriteFile(path, []byte(`[install.scopes]
+"@ourco" = "https://npm.corp.local/"
+`), 0600)
+
+	if err := setBunfigAuthToken("https://npm.corp.local/", "", "CORP_TOKEN"); err != nil {
+		t.Fatalf("setBunfigAuthToken() error = %v", err)
+	}
+	if err := setBunfigAuthToken("https://registry.npmjs.org/", "npm-token-wxyz", ""); err != nil {
+		t.Fatalf("setBunfigAuthToken() error = %v", err)
+	}
+	if err := setBunfigAuthToken("https://unknown.local/", "token", ""); err == nil {
+		t.Error("setBunfigAuthToken() for an unreferenced registry should fail")
+	}
+
+	credentials, err := bunfigCredentials()
+	if err != nil {
+		t.Fatalf("bunfigCredentials() error = %v", err)
+	}
+	if len(credentials) != 2 {
+		t.Fatalf("credentials = %+v", credentials)
+	}
+	if credentials[0].Registry != "https://registry.npmjs.org/" || credentials[0].Token != "********wxyz" {
+		t.Errorf("default credential = %+v", credentials[0])
+	}
+	if credentials[1].EnvVar != "CORP_TOKEN" || credentials[1].Scopes[0] != "@ourco" {
+		t.Errorf("scope credential = %+v", credentials[1])
+	}
+}
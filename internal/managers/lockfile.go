@@ -0,0 +1,231 @@
+package managers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"npm-console/internal/core"
+	"npm-console/pkg/utils"
+)
+
+// RootImporter is the importer key used for the project root in a Lockfile
+const RootImporter = "."
+
+// Lockfile is a manager-independent view of a parsed lockfile
+type Lockfile struct {
+	Manager   string                    `json:"manager"`
+	Path      string                    `json:"path"`
+	Version   string                    `json:"version"`
+	Importers map[string]*LockImporter  `json:"importers"`
+	Packages  map[string]*LockedPackage `json:"packages"`
+}
+
+// LockImporter represents a project (or workspace package) recorded in a lockfile.
+// Dependency maps go from dependency name to a key in Lockfile.Packages.
+type LockImporter struct {
+	Path                 string            `json:"path"`
+	Dependencies         map[string]string `json:"dependencies,omitempty"`
+	DevDependencies      map[string]string `json:"dev_dependencies,omitempty"`
+	OptionalDependencies map[string]string `json:"optional_dependencies,omitempty"`
+}
+
+// LockedPackage represents a single resolved package in a lockfile.
+// Dependencies go from dependency name to a key in Lockfile.Packages.
+type LockedPackage struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Resolved     string            `json:"resolved,omitempty"`
+	Integrity    string            `json:"integrity,omitempty"`
+	Dev          bool              `json:"dev,omitempty"`
+	Optional     bool              `json:"optional,omitempty"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+}
+
+// lockfileCandidates lists the supported lockfiles in detection order
+var lockfileCandidates = []struct {
+	name    string
+	manager string
+}{
+	{"package-lock.json", "npm"},
+	{"npm-shrinkwrap.json", "npm"},
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"bun.lock", "bun"},
+	{"bun.lockb", "bun"},
+}
+
+// FindLockfile returns the path and manager of the first lockfile found in projectPath
+func FindLockfile(projectPath string) (string, string) {
+	for _, candidate := range lockfileCandidates {
+		path := filepath.Join(projectPath, candidate.name)
+		if utils.IsFile(path) {
+			return path, candidate.manager
+		}
+	}
+	return "", ""
+}
+
+// FindLockfiles returns the first lockfile of each manager found in projectPath,
+// keyed by manager, for projects that keep lockfiles for several managers
+func FindLockfiles(projectPath string) map[string]string {
+	found := make(map[string]string)
+	for _, candidate := range lockfileCandidates {
+		if _, ok := found[candidate.manager]; ok {
+			continue
+		}
+		path := filepath.Join(projectPath, candidate.name)
+		if utils.IsFile(path) {
+			found[candidate.manager] = path
+		}
+	}
+	return found
+}
+
+// LoadLockfile finds and parses the lockfile of the project at projectPath. Workspace
+// packages usually have no lockfile of their own, so parent directories are searched
+// too; use Lockfile.Importer to get the entry for projectPath.
+func LoadLockfile(projectPath string) (*Lockfile, error) {
+	dir, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if path, manager := FindLockfile(dir); path != "" {
+			return ParseLockfile(path, manager)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, core.ErrLockfileNotFound
+		}
+		dir = parent
+	}
+}
+
+// ParseLockfile parses the lockfile at path using the parser for the given manager
+func ParseLockfile(path, manager string) (*Lockfile, error) {
+	switch manager {
+	case "npm":
+		return ParseNPMLockfile(path)
+	case "yarn":
+		return ParseYarnLockfile(path)
+	case "pnpm":
+		return ParsePNPMLockfile(path)
+	case "bun":
+		return ParseBunLockfile(path)
+	default:
+		return nil, core.NewManagerError(manager, "parse lockfile", core.ErrManagerNotFound)
+	}
+}
+
+// Root returns the importer for the project root, or nil if there is none
+func (l *Lockfile) Root() *LockImporter {
+	return l.Importers[RootImporter]
+}
+
+// Importer returns the importer for a project directory inside the lockfile's workspace
+func (l *Lockfile) Importer(projectPath string) *LockImporter {
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(filepath.Dir(l.Path), absPath)
+	if err != nil {
+		return nil
+	}
+	return l.Importers[filepath.ToSlash(rel)]
+}
+
+// Package returns the locked package with the given key, or nil if it is not locked
+func (l *Lockfile) Package(id string) *LockedPackage {
+	return l.Packages[id]
+}
+
+// ImporterPackages returns the direct dependencies of an importer as core packages
+// carrying their locked versions, sorted by name
+func (l *Lockfile) ImporterPackages(importer *LockImporter, projectPath string) []core.Package {
+	var packages []core.Package
+
+	sections := []map[string]string{importer.Dependencies, importer.DevDependencies, importer.OptionalDependencies}
+	for _, deps := range sections {
+		for name, id := range deps {
+			locked := l.Package(id)
+			if locked == nil {
+				continue
+			}
+
+			pkg := core.Package{
+				Name:     name,
+				Version:  locked.Version,
+				Manager:  l.Manager,
+				IsGlobal: false,
+				Path:     filepath.Join(projectPath, "node_modules", name),
+			}
+			if len(locked.Dependencies) > 0 {
+				pkg.Dependencies = make(map[string]string, len(locked.Dependencies))
+				for depName, depID := range locked.Dependencies {
+					if dep := l.Package(depID); dep != nil {
+						pkg.Dependencies[depName] = dep.Version
+					}
+				}
+			}
+			packages = append(packages, pkg)
+		}
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+
+	return packages
+}
+
+// newLockfile creates an empty Lockfile for the given manager and path
+func newLockfile(manager, path, version string) *Lockfile {
+	return &Lockfile{
+		Manager:   manager,
+		Path:      path,
+		Version:   version,
+		Importers: make(map[string]*LockImporter),
+		Packages:  make(map[string]*LockedPackage),
+	}
+}
+
+// splitPackageSpec splits "name@spec" into its name and spec, handling scoped names
+func splitPackageSpec(spec string) (string, string) {
+	if spec == "" {
+		return "", ""
+	}
+	idx := strings.Index(spec[1:], "@")
+	if idx < 0 {
+		return spec, ""
+	}
+	return spec[:idx+1], spec[idx+2:]
+}
+
+// manifestDependencies holds the dependency sections of a package.json
+type manifestDependencies struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// readManifestDependencies reads the dependency sections of the package.json in dir
+func readManifestDependencies(dir string) (*manifestDependencies, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest manifestDependencies
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
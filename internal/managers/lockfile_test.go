@@ -0,0 +1,425 @@
+package managers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPackageJson = `{
+  "name": "fixture",
+  "version": "1.0.0",
+  "dependencies": {
+    "a": "^1.0.0"
+  },
+  "devDependencies": {
+    "@scope/b": "~2.0.0"
+  }
+}`
+
+const testNPMLockfile = `{
+  "name": "fixture",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {
+      "name": "fixture",
+      "dependencies": { "a": "^1.0.0" },
+      "devDependencies": { "@scope/b": "~2.0.0" }
+    },
+    "node_modules/a": {
+      "version": "1.2.0",
+      "resolved": "https://registry.npmjs.org/a/-/a-1.2.0.tgz",
+      "integrity": "sha512-a",
+      "dependencies": { "c": "^3.0.0" }
+    },
+    "node_modules/a/node_modules/c": {
+      "version": "3.1.0"
+    },
+    "node_modules/@scope/b": {
+      "version": "2.0.5",
+      "dev": true,
+      "dependencies": { "c": "^4.0.0" }
+    },
+    "node_modules/c": {
+      "version": "4.0.1",
+      "dev": true
+    }
+  }
+}`
+
+const testYarnLockfile = `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@scope/b@~2.0.0":
+  version "2.0.5"
+  resolved "https://registry.yarnpkg.com/@scope/b/-/b-2.0.5.tgz#abc"
+  integrity sha512-b
+  dependencies:
+    c "^4.0.0"
+
+a@^1.0.0, a@^1.1.0:
+  version "1.2.0"
+  resolved "https://registry.yarnpkg.com/a/-/a-1.2.0.tgz#def"
+  integrity sha512-a
+  dependencies:
+    c "^3.0.0"
+
+c@^3.0.0:
+  version "3.1.0"
+
+c@^4.0.0:
+  version "4.0.1"
+`
+
+const testYarnBerryLockfile = `# This file is generated by running "yarn install" inside your project.
+# Manual changes might be lost - proceed with caution!
+
+__metadata:
+  version: 8
+  cacheKey: 10c0
+
+"@scope/b@npm:~2.0.0":
+  version: 2.0.5
+  resolution: "@scope/b@npm:2.0.5"
+  dependencies:
+    c: "npm:^4.0.0"
+  checksum: 10c0/bbbb
+  languageName: node
+  linkType: hard
+
+"a@npm:^1.0.0, a@npm:^1.1.0":
+  version: 1.2.0
+  resolution: "a@npm:1.2.0"
+  dependencies:
+    c: ^3.0.0
+  checksum: 10c0/aaaa
+  languageName: node
+  linkType: hard
+
+"c@npm:^3.0.0":
+  version: 3.1.0
+  resolution: "c@npm:3.1.0"
+  languageName: node
+  linkType: hard
+
+"c@npm:^4.0.0":
+  version: 4.0.1
+  resolution: "c@npm:4.0.1"
+  languageName: node
+  linkType: hard
+
+"fixture@workspace:.":
+  version: 0.0.0-use.local
+  resolution: "fixture@workspace:."
+  dependencies:
+    "@scope/b": "npm:~2.0.0"
+    a: "npm:^1.0.0"
+  languageName: unknown
+  linkType: soft
+`
+
+const testPNPMLockfile = `lockfileVersion: '6.0'
+
+dependencies:
+  a:
+    specifier: ^1.0.0
+    version: 1.2.0
+
+devDependencies:
+  '@scope/b':
+    specifier: ~2.0.0
+    version: 2.0.5
+
+packages:
+
+  /a@1.2.0:
+    resolution: {integrity: sha512-a}
+    dependencies:
+      c: 3.1.0
+    dev: false
+
+  /@scope/b@2.0.5:
+    resolution: {integrity: sha512-b}
+    dependencies:
+      c: 4.0.1
+    dev: true
+
+  /c@3.1.0:
+    resolution: {integrity: sha512-c3}
+    dev: false
+
+  /c@4.0.1:
+    resolution: {integrity: sha512-c4}
+    dev: true
+`
+
+const testBunLockfile = `{
+  "lockfileVersion": 1,
+  "workspaces": {
+    "": {
+      "name": "fixture",
+      "dependencies": {
+        "a": "^1.0.0",
+      },
+      "devDependencies": {
+        "@scope/b": "~2.0.0",
+      },
+    },
+  },
+  "packages": {
+    "@scope/b": ["@scope/b@2.0.5", "", { "dependencies": { "c": "^4.0.0" } }, "sha512-b"],
+
+    "a": ["a@1.2.0", "", { "dependencies": { "c": "^3.0.0" } }, "sha512-a"],
+
+    "c": ["c@4.0.1", "", {}, "sha512-c4"],
+
+    "a/c": ["c@3.1.0", "", {}, "sha512-c3"],
+  }
+}
+`
+
+const testPNPMWorkspaceLockfile = `lockfileVersion: '9.0'
+
+importers:
+
+  .:
+    dependencies:
+      a:
+        specifier: ^1.0.0
+        version: 1.2.0
+    devDependencies:
+      '@scope/b':
+        specifier: ~2.0.0
+        version: 2.0.5
+
+  packages/web:
+    dependencies:
+      a:
+        specifier: ^1.0.0
+        version: 1.2.0
+      b-alias:
+        specifier: npm:@scope/b@~2.0.0
+        version: '@scope/b@2.0.5'
+      shared:
+        specifier: workspace:*
+        version: link:../shared
+      react-dom:
+        specifier: ^18.0.0
+        version: 18.2.0(react@18.2.0)
+
+packages:
+
+  a@1.2.0:
+    resolution: {integrity: sha512-a}
+
+  '@scope/b@2.0.5':
+    resolution: {integrity: sha512-b}
+
+  c@3.1.0:
+    resolution: {integrity: sha512-c3}
+
+  c@4.0.1:
+    resolution: {integrity: sha512-c4}
+
+  react@18.2.0:
+    resolution: {integrity: sha512-react}
+
+  react-dom@18.2.0:
+    resolution: {integrity: sha512-react-dom}
+    peerDependencies:
+      react: ^18.2.0
+
+snapshots:
+
+  a@1.2.0:
+    dependencies:
+      c: 3.1.0
+
+  '@scope/b@2.0.5':
+    dependencies:
+      c: 4.0.1
+
+  c@3.1.0: {}
+
+  c@4.0.1: {}
+
+  react@18.2.0: {}
+
+  react-dom@18.2.0(react@18.2.0):
+    dependencies:
+      react: 18.2.0
+`
+
+func writeFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadLockfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		lockName string
+		content  string
+		manager  string
+	}{
+		{"npm package-lock v3", "package-lock.json", testNPMLockfile, "npm"},
+		{"yarn.lock v1", "yarn.lock", testYarnLockfile, "yarn"},
+		{"yarn.lock berry", "yarn.lock", testYarnBerryLockfile, "yarn"},
+		{"pnpm-lock v6", "pnpm-lock.yaml", testPNPMLockfile, "pnpm"},
+		{"pnpm-lock v9", "pnpm-lock.yaml", testPNPMWorkspaceLockfile, "pnpm"},
+		{"bun.lock", "bun.lock", testBunLockfile, "bun"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFixture(t, map[string]string{
+				"package.json": testPackageJson,
+				tt.lockName:    tt.content,
+			})
+
+			lockfile, err := LoadLockfile(dir)
+			if err != nil {
+				t.Fatalf("LoadLockfile() error = %v", err)
+			}
+			if lockfile.Manager != tt.manager {
+				t.Errorf("Manager = %s, want %s", lockfile.Manager, tt.manager)
+			}
+
+			root := lockfile.Root()
+			if root == nil {
+				t.Fatal("Expected a root importer")
+			}
+
+			a := lockfile.Package(root.Dependencies["a"])
+			if a == nil || a.Version != "1.2.0" {
+				t.Fatalf("Expected a@1.2.0, got %+v", a)
+			}
+			if a.Integrity == "" {
+				t.Error("Expected a to carry an integrity hash")
+			}
+
+			b := lockfile.Package(root.DevDependencies["@scope/b"])
+			if b == nil || b.Name != "@scope/b" || b.Version != "2.0.5" {
+				t.Fatalf("Expected @scope/b@2.0.5, got %+v", b)
+			}
+
+			// Both copies of c must be resolved to the version their parent needs
+			if c := lockfile.Package(a.Dependencies["c"]); c == nil || c.Version != "3.1.0" {
+				t.Errorf("Expected a to depend on c@3.1.0, got %+v", c)
+			}
+			if c := lockfile.Package(b.Dependencies["c"]); c == nil || c.Version != "4.0.1" {
+				t.Errorf("Expected @scope/b to depend on c@4.0.1, got %+v", c)
+			}
+		})
+	}
+}
+
+func TestLoadLockfilePNPMWorkspace(t *testing.T) {
+	root := writeFixture(t, map[string]string{
+		"package.json":   testPackageJson,
+		"pnpm-lock.yaml": testPNPMWorkspaceLockfile,
+	})
+	project := filepath.Join(root, "packages", "web")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatalf("Failed to create workspace package: %v", err)
+	}
+
+	// The lockfile is found in the workspace root
+	lockfile, err := LoadLockfile(project)
+	if err != nil {
+		t.Fatalf("LoadLockfile() error = %v", err)
+	}
+
+	importer := lockfile.Importer(project)
+	if importer == nil {
+		t.Fatal("Expected an importer for packages/web")
+	}
+
+	if _, ok := importer.Dependencies["shared"]; ok {
+		t.Error("Expected workspace links to be skipped")
+	}
+
+	tests := []struct {
+		dep     string
+		name    string
+		version string
+	}{
+		{"a", "a", "1.2.0"},
+		{"b-alias", "@scope/b", "2.0.5"},
+		{"react-dom", "react-dom", "18.2.0"},
+	}
+
+	for _, tt := range tests {
+		pkg := lockfile.Package(importer.Dependencies[tt.dep])
+		if pkg == nil || pkg.Name != tt.name || pkg.Version != tt.version {
+			t.Errorf("Expected %s to resolve to %s@%s, got %+v", tt.dep, tt.name, tt.version, pkg)
+			continue
+		}
+		if pkg.Integrity == "" {
+			t.Errorf("Expected %s to carry integrity from the packages map", tt.dep)
+		}
+	}
+
+	reactDOM := lockfile.Package(importer.Dependencies["react-dom"])
+	if reactDOM != nil && reactDOM.Dependencies["react"] != "react@18.2.0" {
+		t.Errorf("Expected react-dom to depend on react@18.2.0, got %q", reactDOM.Dependencies["react"])
+	}
+}
+
+func TestLoadLockfileNotFound(t *testing.T) {
+	dir := writeFixture(t, map[string]string{"package.json": testPackageJson})
+
+	if _, err := LoadLockfile(dir); err == nil {
+		t.Error("Expected an error for a project without lockfile")
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"a": 1,}`, `{"a": 1}`},
+		{`[1, 2,
+]`, `[1, 2
+]`},
+		{`{"a": "x,}" , "b": 2}`, `{"a": "x,}" , "b": 2}`},
+		{`{"a": "\"", // comment
+}`, `{"a": "\"" 
+}`},
+		{`{/* block */"a": 1}`, `{"a": 1}`},
+	}
+
+	for _, tt := range tests {
+		if got := string(stripJSONC([]byte(tt.input))); got != tt.want {
+			t.Errorf("stripJSONC(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSplitPackageSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		name    string
+		version string
+	}{
+		{"lodash@4.17.21", "lodash", "4.17.21"},
+		{"@babel/core@^7.0.0", "@babel/core", "^7.0.0"},
+		{"foo@npm:bar@^1.0.0", "foo", "npm:bar@^1.0.0"},
+		{"@scope/pkg", "@scope/pkg", ""},
+	}
+
+	for _, tt := range tests {
+		name, version := splitPackageSpec(tt.spec)
+		if name != tt.name || version != tt.version {
+			t.Errorf("splitPackageSpec(%q) = (%q, %q), want (%q, %q)", tt.spec, name, version, tt.name, tt.version)
+		}
+	}
+}
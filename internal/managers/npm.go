@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"npm-console/internal/core"
+	"npm-console/internal/registry"
 	"npm-console/pkg/logger"
 	"npm-console/pkg/utils"
 )
@@ -37,12 +38,12 @@ func (n *NPMManager) IsAvailable(ctx context.Context) bool {
 	return result.Error == nil
 }
 
-// GetCacheInfo returns information about npm cache
-func (n *NPMManager) GetCacheInfo(ctx context.Context) (*core.CacheInfo, error) {
+// cacheDir returns the expanded npm cache directory
+func (n *NPMManager) cacheDir(ctx context.Context) (string, error) {
 	// Get npm cache directory
 	result := utils.ExecuteCommand(ctx, "npm", "config", "get", "cache")
 	if result.Error != nil {
-		return nil, core.NewManagerError("npm", "get cache path", result.Error)
+		return "", core.NewManagerError("npm", "get cache path", result.Error)
 	}
 
 	cachePath := strings.TrimSpace(result.Stdout)
@@ -54,7 +55,16 @@ func (n *NPMManager) GetCacheInfo(ctx context.Context) (*core.CacheInfo, error)
 	// Expand path if needed
 	expandedPath, err := utils.ExpandPath(cachePath)
 	if err != nil {
-		return nil, core.NewManagerError("npm", "expand cache path", err)
+		return "", core.NewManagerError("npm", "expand cache path", err)
+	}
+	return expandedPath, nil
+}
+
+// GetCacheInfo returns information about npm cache
+func (n *NPMManager) GetCacheInfo(ctx context.Context) (*core.CacheInfo, error) {
+	expandedPath, err := n.cacheDir(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check if cache directory exists
@@ -108,6 +118,64 @@ func (n *NPMManager) ClearCache(ctx context.Context) error {
 	return nil
 }
 
+// ListCacheEntries returns the entries of npm's cacache index, which holds
+// the packuments and tarballs npm has fetched
+func (n *NPMManager) ListCacheEntries(ctx context.Context) ([]core.CacheIndexEntry, error) {
+	dir, err := n.cacheDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ReadCacacheIndex(filepath.Join(dir, "_cacache"))
+	if err != nil {
+		return nil, core.NewManagerError("npm", "read cache index", err)
+	}
+	return entries, nil
+}
+
+// RemoveCacheEntries removes entries from npm's cacache index, leaving the
+// rest of the cache in place
+func (n *NPMManager) RemoveCacheEntries(ctx context.Context, keys []string) (*core.CacheEvictResult, error) {
+	dir, err := n.cacheDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := RemoveCacacheEntries(filepath.Join(dir, "_cacache"), keys)
+	if err != nil {
+		return nil, core.NewManagerError("npm", "remove cache entries", err)
+	}
+
+	n.logger.WithField("entries", len(result.Keys)).WithField("bytes_freed", result.BytesFreed).Info("npm cache entries removed")
+	return result, nil
+}
+
+// ImportTarballs adds package tarballs to npm's cacache, where npm
+// install --offline finds them by integrity. dir is not used.
+func (n *NPMManager) ImportTarballs(ctx context.Context, packages []core.BundledPackage, dir string) (*core.CacheImportResult, error) {
+	cache, err := n.cacheDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Join(cache, "_cacache")
+
+	result := &core.CacheImportResult{Manager: "npm", Target: root}
+	for _, pkg := range packages {
+		added, err := ImportCacacheTarball(root, pkg)
+		switch {
+		case err != nil:
+			result.Failed = append(result.Failed, pkg.Name+"@"+pkg.Version+": "+err.Error())
+		case added:
+			result.Imported++
+		default:
+			result.Skipped++
+		}
+	}
+
+	n.logger.WithField("imported", result.Imported).WithField("skipped", result.Skipped).Info("npm cache seeded")
+	return result, nil
+}
+
 // GetInstalledPackages returns packages installed in a specific project
 func (n *NPMManager) GetInstalledPackages(ctx context.Context, projectPath string) ([]core.Package, error) {
 	// Check if package.json exists
@@ -191,73 +259,107 @@ func (n *NPMManager) GetGlobalPackages(ctx context.Context) ([]core.Package, err
 	return packages, nil
 }
 
-// GetConfig returns the current npm configuration
+// configFiles returns the .npmrc files npm reads from the working directory
+func (n *NPMManager) configFiles() []ConfigFile {
+	return ConfigFiles("npm", ".", false)
+}
+
+// GetConfig returns the effective npm configuration for the working directory
 func (n *NPMManager) GetConfig(ctx context.Context) (*core.Config, error) {
-	config := &core.Config{
-		Manager:  "npm",
-		Settings: make(map[string]string),
+	entries, err := n.ExplainConfig(ctx, ".", nil)
+	if err != nil {
+		return nil, err
 	}
+	return configFromEntries("npm", entries, "cache", "prefix", "userconfig", "globalconfig"), nil
+}
 
-	// Get registry
-	result := utils.ExecuteCommand(ctx, "npm", "config", "get", "registry")
-	if result.Error == nil {
-		config.Registry = strings.TrimSpace(result.Stdout)
-	}
+// ExplainConfig resolves npm's config keys through the command line,
+// npm_config_* variables, the project, user and global .npmrc files and
+// npm's defaults
+func (n *NPMManager) ExplainConfig(ctx context.Context, projectPath string, cli map[string]string) ([]core.ConfigEntry, error) {
+	files := ConfigFiles("npm", projectPath, false)
 
-	// Get proxy
-	result = utils.ExecuteCommand(ctx, "npm", "config", "get", "proxy")
-	if result.Error == nil && result.Stdout != "null" {
-		config.Proxy = strings.TrimSpace(result.Stdout)
+	defaults := map[string]string{
+		"registry": registry.DefaultRegistry,
+		"cache":    n.getDefaultCachePath(),
+		"prefix":   npmPrefix(),
 	}
-
-	// Get other common settings
-	settings := []string{"cache", "prefix", "userconfig", "globalconfig"}
-	for _, setting := range settings {
-		result = utils.ExecuteCommand(ctx, "npm", "config", "get", setting)
-		if result.Error == nil {
-			config.Settings[setting] = strings.TrimSpace(result.Stdout)
+	for _, file := range files {
+		switch file.Level {
+		case LevelUser:
+			defaults["userconfig"] = file.Path
+		case LevelGlobal:
+			defaults["globalconfig"] = file.Path
 		}
 	}
+	builtin := builtinConfig(defaults)
+	addProxyEnvDefaults(builtin)
 
-	return config, nil
+	return explainConfig("npm", files, envConfig("npm", false), builtin, cli), nil
 }
 
-// SetRegistry sets the npm registry URL
+// SetRegistry sets the registry URL in the user .npmrc
 func (n *NPMManager) SetRegistry(ctx context.Context, url string) error {
-	result := utils.ExecuteCommand(ctx, "npm", "config", "set", "registry", url)
-	if result.Error != nil {
-		return core.NewManagerError("npm", "set registry", result.Error)
+	if err := setConfigValue(n.configFiles(), "registry", url); err != nil {
+		return core.NewManagerError("npm", "set registry", err)
 	}
 
 	n.logger.WithField("registry", url).Info("npm registry updated")
 	return nil
 }
 
-// SetProxy sets the npm proxy configuration
-func (n *NPMManager) SetProxy(ctx context.Context, proxy string) error {
-	if proxy == "" {
-		// Remove proxy
-		result := utils.ExecuteCommand(ctx, "npm", "config", "delete", "proxy")
-		if result.Error != nil {
-			return core.NewManagerError("npm", "remove proxy", result.Error)
-		}
-		result = utils.ExecuteCommand(ctx, "npm", "config", "delete", "https-proxy")
-		if result.Error != nil {
-			return core.NewManagerError("npm", "remove https-proxy", result.Error)
-		}
-	} else {
-		// Set proxy
-		result := utils.ExecuteCommand(ctx, "npm", "config", "set", "proxy", proxy)
-		if result.Error != nil {
-			return core.NewManagerError("npm", "set proxy", result.Error)
-		}
-		result = utils.ExecuteCommand(ctx, "npm", "config", "set", "https-proxy", proxy)
-		if result.Error != nil {
-			return core.NewManagerError("npm", "set https-proxy", result.Error)
-		}
+// SetProxy sets proxy, https-proxy and noproxy in the user .npmrc; empty values remove them
+func (n *NPMManager) SetProxy(ctx context.Context, proxy core.ProxySettings) error {
+	if err := setProxyValues(n.configFiles(), proxy); err != nil {
+		return core.NewManagerError("npm", "set proxy", err)
+	}
+
+	n.logger.WithField("proxy", utils.RedactURL(proxy.HTTP)).WithField("https_proxy", utils.RedactURL(proxy.HTTPS)).WithField("no_proxy", proxy.NoProxy).Info("npm proxy updated")
+	return nil
+}
+
+// GetScopedRegistries returns the npm "@scope" -> registry mappings
+func (n *NPMManager) GetScopedRegistries(ctx context.Context) (map[string]string, error) {
+	return scopedRegistriesFromFiles(n.configFiles()), nil
+}
+
+// SetScopedRegistry maps a scope to a registry in the user .npmrc; an empty
+// URL removes the mapping
+func (n *NPMManager) SetScopedRegistry(ctx context.Context, scope string, url string) error {
+	if err := setConfigValue(n.configFiles(), NormalizeScope(scope)+":registry", url); err != nil {
+		return core.NewManagerError("npm", "set scoped registry", err)
+	}
+
+	n.logger.WithField("scope", NormalizeScope(scope)).WithField("registry", url).Info("npm scoped registry updated")
+	return nil
+}
+
+// SetConfigValue sets a key in the user config file; an empty value removes it
+func (n *NPMManager) SetConfigValue(ctx context.Context, key string, value string) error {
+	if err := setConfigValue(n.configFiles(), key, value); err != nil {
+		return core.NewManagerError("npm", "set config", err)
+	}
+
+	n.logger.WithField("key", key).Info("npm config updated")
+	return nil
+}
+
+// GetCredentials returns the registries of the user .npmrc with masked tokens
+func (n *NPMManager) GetCredentials(ctx context.Context) ([]core.Credential, error) {
+	credentials, err := npmrcCredentials("npm")
+	if err != nil {
+		return nil, core.NewManagerError("npm", "read credentials", err)
+	}
+	return credentials, nil
+}
+
+// SetAuthToken stores a registry token in the user .npmrc
+func (n *NPMManager) SetAuthToken(ctx context.Context, registry string, token string, envVar string) error {
+	if err := setNpmrcAuthToken(registry, token, envVar); err != nil {
+		return core.NewManagerError("npm", "set auth token", err)
 	}
 
-	n.logger.WithField("proxy", proxy).Info("npm proxy updated")
+	n.logger.WithField("registry", registry).Info("npm auth token updated")
 	return nil
 }
 
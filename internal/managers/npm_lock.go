@@ -0,0 +1,219 @@
+package managers
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"npm-console/internal/core"
+)
+
+// npmLockfile mirrors the parts of package-lock.json / npm-shrinkwrap.json we read
+type npmLockfile struct {
+	Name            string                       `json:"name"`
+	LockfileVersion int                          `json:"lockfileVersion"`
+	Packages        map[string]npmLockPackage    `json:"packages"`
+	Dependencies    map[string]npmLockDependency `json:"dependencies"`
+}
+
+// npmLockPackage is an entry of the v2/v3 "packages" map
+type npmLockPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Integrity            string            `json:"integrity"`
+	Link                 bool              `json:"link"`
+	Dev                  bool              `json:"dev"`
+	Optional             bool              `json:"optional"`
+	DevOptional          bool              `json:"devOptional"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// npmLockDependency is an entry of the v1 nested "dependencies" map
+type npmLockDependency struct {
+	Version      string                       `json:"version"`
+	Resolved     string                       `json:"resolved"`
+	Integrity    string                       `json:"integrity"`
+	Dev          bool                         `json:"dev"`
+	Optional     bool                         `json:"optional"`
+	Requires     map[string]string            `json:"requires"`
+	Dependencies map[string]npmLockDependency `json:"dependencies"`
+}
+
+// ParseNPMLockfile parses a package-lock.json or npm-shrinkwrap.json file
+func ParseNPMLockfile(lockPath string) (*Lockfile, error) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return nil, core.NewManagerError("npm", "read lockfile", err)
+	}
+
+	var raw npmLockfile
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, core.NewManagerError("npm", "parse lockfile", err)
+	}
+
+	// lockfileVersion 1 only has the nested dependencies map, so flatten it
+	// into the same node_modules path layout used by v2 and v3
+	packages := raw.Packages
+	if len(packages) == 0 {
+		packages, err = flattenNPMLockV1(raw.Dependencies, lockPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lockfile := newLockfile("npm", lockPath, strconv.Itoa(raw.LockfileVersion))
+
+	for key, entry := range packages {
+		if key == "" {
+			lockfile.Importers[RootImporter] = npmImporter(key, entry, packages)
+			continue
+		}
+		if !strings.Contains(key, "node_modules/") {
+			// Workspace package folders are importers, not installed packages
+			lockfile.Importers[key] = npmImporter(key, entry, packages)
+			continue
+		}
+		if entry.Link {
+			continue
+		}
+
+		locked := &LockedPackage{
+			ID:           key,
+			Name:         entry.Name,
+			Version:      entry.Version,
+			Resolved:     entry.Resolved,
+			Integrity:    entry.Integrity,
+			Dev:          entry.Dev,
+			Optional:     entry.Optional || entry.DevOptional,
+			Dependencies: make(map[string]string),
+		}
+		if locked.Name == "" {
+			locked.Name = npmPackageNameFromKey(key)
+		}
+
+		for _, deps := range []map[string]string{entry.Dependencies, entry.OptionalDependencies, entry.PeerDependencies} {
+			for name := range deps {
+				if id := resolveNPMDependency(packages, key, name); id != "" {
+					locked.Dependencies[name] = id
+				}
+			}
+		}
+
+		lockfile.Packages[key] = locked
+	}
+
+	return lockfile, nil
+}
+
+// npmImporter builds the importer for the project or workspace folder at key
+func npmImporter(key string, entry npmLockPackage, packages map[string]npmLockPackage) *LockImporter {
+	importer := &LockImporter{
+		Path:                 key,
+		Dependencies:         make(map[string]string),
+		DevDependencies:      make(map[string]string),
+		OptionalDependencies: make(map[string]string),
+	}
+	if key == "" {
+		importer.Path = RootImporter
+	}
+
+	sections := []struct {
+		deps   map[string]string
+		target map[string]string
+	}{
+		{entry.Dependencies, importer.Dependencies},
+		{entry.DevDependencies, importer.DevDependencies},
+		{entry.OptionalDependencies, importer.OptionalDependencies},
+	}
+	for _, section := range sections {
+		for name := range section.deps {
+			if id := resolveNPMDependency(packages, key, name); id != "" {
+				section.target[name] = id
+			}
+		}
+	}
+
+	return importer
+}
+
+// resolveNPMDependency applies node's module resolution to find which installed
+// copy of name the package at key would load. Workspace links resolve to nothing
+// because the linked folder is tracked as an importer rather than a package.
+func resolveNPMDependency(packages map[string]npmLockPackage, key, name string) string {
+	dir := key
+	for {
+		candidate := "node_modules/" + name
+		if dir != "" {
+			candidate = dir + "/node_modules/" + name
+		}
+
+		if entry, ok := packages[candidate]; ok {
+			if entry.Link {
+				return ""
+			}
+			return candidate
+		}
+
+		if dir == "" {
+			return ""
+		}
+
+		// Move up to the enclosing node_modules folder
+		if idx := strings.LastIndex(dir, "/node_modules/"); idx >= 0 {
+			dir = dir[:idx]
+		} else {
+			dir = ""
+		}
+	}
+}
+
+// npmPackageNameFromKey extracts the package name from a node_modules path key
+func npmPackageNameFromKey(key string) string {
+	idx := strings.LastIndex(key, "node_modules/")
+	if idx < 0 {
+		return path.Base(key)
+	}
+	return key[idx+len("node_modules/"):]
+}
+
+// flattenNPMLockV1 converts a v1 nested dependency map into v2-style path keys
+func flattenNPMLockV1(deps map[string]npmLockDependency, lockPath string) (map[string]npmLockPackage, error) {
+	manifest, err := readManifestDependencies(filepath.Dir(lockPath))
+	if err != nil {
+		return nil, core.NewManagerError("npm", "read package.json", err)
+	}
+
+	packages := map[string]npmLockPackage{
+		"": {
+			Dependencies:         manifest.Dependencies,
+			DevDependencies:      manifest.DevDependencies,
+			OptionalDependencies: manifest.OptionalDependencies,
+		},
+	}
+
+	var walk func(prefix string, deps map[string]npmLockDependency)
+	walk = func(prefix string, deps map[string]npmLockDependency) {
+		for name, dep := range deps {
+			key := prefix + "node_modules/" + name
+			packages[key] = npmLockPackage{
+				Version:      dep.Version,
+				Resolved:     dep.Resolved,
+				Integrity:    dep.Integrity,
+				Dev:          dep.Dev,
+				Optional:     dep.Optional,
+				Dependencies: dep.Requires,
+			}
+			walk(key+"/", dep.Dependencies)
+		}
+	}
+	walk("", deps)
+
+	return packages, nil
+}
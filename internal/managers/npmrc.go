@@ -0,0 +1,176 @@
+package managers
+
+import (
+	"bufio"
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"npm-console/internal/core"
+	"npm-console/pkg/utils"
+)
+
+// RegistrySettings holds what a manager needs to talk to its registries
+type RegistrySettings struct {
+	Registry   string            `json:"registry"`
+	Scopes     map[string]string `json:"scopes,omitempty"`      // "@scope" -> registry URL
+	Tokens     map[string]string `json:"-"`                     // "//host/path/" -> auth token
+	Proxy      string            `json:"proxy,omitempty"`       // proxy for http:// requests
+	HTTPSProxy string            `json:"https_proxy,omitempty"` // proxy for https:// requests
+	NoProxy    []string          `json:"no_proxy,omitempty"`    // hosts reached without a proxy
+}
+
+// envVarPattern matches ${VAR} references, which npm expands in .npmrc values
+var envVarPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// ParseNpmrc parses the ini-style contents of an .npmrc file into key/value
+// pairs, expanding ${VAR} references
+func ParseNpmrc(data []byte) map[string]string {
+	values := parseNpmrcRaw(data)
+	for key, value := range values {
+		values[key] = expandEnv(value)
+	}
+	return values
+}
+
+// parseNpmrcRaw parses an .npmrc without expanding environment references
+func parseNpmrcRaw(data []byte) map[string]string {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		idx := strings.Index(line, "=")
+		if idx <= 0 {
+			continue
+		}
+
+		key := strings.TrimSpace(line[:idx])
+		value := strings.TrimSpace(line[idx+1:])
+		values[key] = strings.Trim(value, `"'`)
+	}
+
+	return values
+}
+
+// LoadRegistrySettings collects the registry, scoped registries, auth tokens and
+// proxy that a manager would use for a project. Project files take precedence
+// over user and global files; yarn Berry settings come from .yarnrc.yml.
+func LoadRegistrySettings(manager, projectPath string) *RegistrySettings {
+	settings := &RegistrySettings{
+		Scopes: make(map[string]string),
+		Tokens: make(map[string]string),
+	}
+
+	berry := manager == "yarn" && yarnrcYmlExists(projectPath)
+	files := ConfigFiles(manager, projectPath, berry)
+
+	// Apply the least specific file first so more specific ones override it
+	for i := len(files) - 1; i >= 0; i-- {
+		values, err := files[i].Read()
+		if err != nil {
+			continue
+		}
+		for key, value := range values {
+			values[key] = expandEnv(value)
+		}
+		applyNpmrc(settings, values)
+	}
+
+	return settings
+}
+
+// UserNpmrcPath returns the user-level .npmrc that npm, pnpm and yarn 1 write to
+func UserNpmrcPath() (string, error) {
+	if userConfig := os.Getenv("NPM_CONFIG_USERCONFIG"); userConfig != "" {
+		return userConfig, nil
+	}
+
+	home, err := utils.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".npmrc"), nil
+}
+
+// setNpmrcValue sets one key of an .npmrc in place, keeping comments and the
+// order of the other lines. An empty value removes the key.
+func setNpmrcValue(path, key, value string) error {
+	line := ""
+	if value != "" {
+		line = key + "=" + value
+	}
+
+	return setConfigLine(path, key, line, func(line string) (string, bool) {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			return "", false
+		}
+		k, _, ok := strings.Cut(trimmed, "=")
+		return strings.TrimSpace(k), ok
+	})
+}
+
+// applyNpmrc copies the registry related keys of an .npmrc into settings
+func applyNpmrc(settings *RegistrySettings, values map[string]string) {
+	for key, value := range values {
+		switch {
+		case key == "registry":
+			settings.Registry = value
+		case key == "proxy":
+			settings.Proxy = value
+		case key == "https-proxy":
+			settings.HTTPSProxy = value
+		case key == "noproxy":
+			settings.NoProxy = core.ParseNoProxy(value)
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			settings.Scopes[strings.TrimSuffix(key, ":registry")] = value
+		case strings.HasPrefix(key, "//") && strings.HasSuffix(key, ":_authToken"):
+			settings.Tokens[strings.TrimSuffix(key, ":_authToken")] = value
+		case key == "_authToken":
+			settings.Tokens[""] = value
+		}
+	}
+}
+
+// expandEnv expands ${VAR} references the way yarn and npm do
+func expandEnv(value string) string {
+	return envVarPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		return os.Getenv(ref[2 : len(ref)-1])
+	})
+}
+
+// NerfDart reduces a registry URL to the "//host/path/" form used to key
+// credentials in .npmrc. Like npm, the last path segment is dropped unless the
+// URL ends in a slash, so https://h/repo maps to //h/ and https://h/repo/ to
+// //h/repo/; credentials, query and fragment are dropped.
+func NerfDart(registryURL string) string {
+	raw := registryURL
+	if !strings.Contains(raw, "://") && !strings.HasPrefix(raw, "//") {
+		raw = "//" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		nerfed := raw[strings.Index(raw, "//"):]
+		return nerfed[:strings.LastIndex(nerfed, "/")+1]
+	}
+
+	dir := u.Path[:strings.LastIndex(u.Path, "/")+1]
+	if dir == "" {
+		dir = "/"
+	}
+	return "//" + strings.ToLower(u.Host) + dir
+}
+
+// NormalizeScope returns a package scope in its "@scope" form
+func NormalizeScope(scope string) string {
+	return "@" + strings.TrimPrefix(strings.TrimSpace(scope), "@")
+}
@@ -2,6 +2,7 @@ package managers
 
 import (
 	"context"
+	"errors"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"npm-console/internal/core"
+	"npm-console/internal/registry"
 	"npm-console/pkg/logger"
 	"npm-console/pkg/utils"
 )
@@ -108,6 +110,38 @@ func (p *PNPMManager) ClearCache(ctx context.Context) error {
 	return nil
 }
 
+// storeAddBatch is the number of tarballs passed to one pnpm store add
+const storeAddBatch = 50
+
+// ImportTarballs adds package tarballs to the pnpm store with pnpm store
+// add, which files them under their integrity for pnpm install --offline.
+// dir is not used.
+func (p *PNPMManager) ImportTarballs(ctx context.Context, packages []core.BundledPackage, dir string) (*core.CacheImportResult, error) {
+	result := &core.CacheImportResult{Manager: "pnpm"}
+	if store := utils.ExecuteCommand(ctx, "pnpm", "store", "path"); store.Error == nil {
+		result.Target = strings.TrimSpace(store.Stdout)
+	}
+
+	for start := 0; start < len(packages); start += storeAddBatch {
+		batch := packages[start:min(start+storeAddBatch, len(packages))]
+		args := []string{"store", "add"}
+		for _, pkg := range batch {
+			args = append(args, pkg.File)
+		}
+
+		if added := utils.ExecuteCommand(ctx, "pnpm", args...); added.Error != nil {
+			for _, pkg := range batch {
+				result.Failed = append(result.Failed, pkg.Name+"@"+pkg.Version+": "+added.Error.Error())
+			}
+			continue
+		}
+		result.Imported += len(batch)
+	}
+
+	p.logger.WithField("imported", result.Imported).Info("pnpm store seeded")
+	return result, nil
+}
+
 // GetInstalledPackages returns packages installed in a specific project
 func (p *PNPMManager) GetInstalledPackages(ctx context.Context, projectPath string) ([]core.Package, error) {
 	// Check if package.json exists
@@ -116,60 +150,18 @@ func (p *PNPMManager) GetInstalledPackages(ctx context.Context, projectPath stri
 		return nil, core.ErrProjectNotFound
 	}
 
-	// Use pnpm list to get installed packages
-	result := utils.ExecuteCommandWithTimeout(30*time.Second, "pnpm", "list", "--json", "--depth=0")
-	if result.Error != nil {
-		// Fallback to reading package.json
-		return p.getPackagesFromPackageJson(packageJsonPath)
-	}
-
-	var pnpmList []struct {
-		Name         string            `json:"name"`
-		Dependencies map[string]struct {
-			Version string `json:"version"`
-			Path    string `json:"path"`
-		} `json:"dependencies"`
-		DevDependencies map[string]struct {
-			Version string `json:"version"`
-			Path    string `json:"path"`
-		} `json:"devDependencies"`
-	}
-
-	if err := json.Unmarshal([]byte(result.Stdout), &pnpmList); err != nil {
-		return nil, core.NewManagerError("pnpm", "parse pnpm list output", err)
-	}
-
-	var packages []core.Package
-	
-	if len(pnpmList) > 0 {
-		item := pnpmList[0]
-		
-		// Add regular dependencies
-		for name, info := range item.Dependencies {
-			pkg := core.Package{
-				Name:     name,
-				Version:  info.Version,
-				Manager:  "pnpm",
-				IsGlobal: false,
-				Path:     info.Path,
-			}
-			packages = append(packages, pkg)
-		}
-
-		// Add dev dependencies
-		for name, info := range item.DevDependencies {
-			pkg := core.Package{
-				Name:     name,
-				Version:  info.Version,
-				Manager:  "pnpm",
-				IsGlobal: false,
-				Path:     info.Path,
-			}
-			packages = append(packages, pkg)
+	// Read resolved versions from pnpm-lock.yaml, which may live at the workspace root
+	lockfile, err := LoadLockfile(projectPath)
+	if err == nil && lockfile.Manager == "pnpm" {
+		if importer := lockfile.Importer(projectPath); importer != nil {
+			return lockfile.ImporterPackages(importer, projectPath), nil
 		}
+	} else if err != nil && !errors.Is(err, core.ErrLockfileNotFound) {
+		p.logger.WithError(err).Warn("Failed to parse lockfile, falling back to package.json")
 	}
 
-	return packages, nil
+	// Fallback to reading package.json
+	return p.getPackagesFromPackageJson(packageJsonPath)
 }
 
 // GetGlobalPackages returns globally installed pnpm packages
@@ -208,79 +200,95 @@ func (p *PNPMManager) GetGlobalPackages(ctx context.Context) ([]core.Package, er
 	return packages, nil
 }
 
-// GetConfig returns the current pnpm configuration
+// configFiles returns the config files pnpm reads from the working directory
+func (p *PNPMManager) configFiles() []ConfigFile {
+	return ConfigFiles("pnpm", ".", false)
+}
+
+// GetConfig returns the effective pnpm configuration for the working directory
 func (p *PNPMManager) GetConfig(ctx context.Context) (*core.Config, error) {
-	config := &core.Config{
-		Manager:  "pnpm",
-		Settings: make(map[string]string),
+	entries, err := p.ExplainConfig(ctx, ".", nil)
+	if err != nil {
+		return nil, err
 	}
+	return configFromEntries("pnpm", entries, "store-dir", "cache-dir", "state-dir", "global-dir"), nil
+}
 
-	// Get registry
-	result := utils.ExecuteCommand(ctx, "pnpm", "config", "get", "registry")
-	if result.Error == nil {
-		config.Registry = strings.TrimSpace(result.Stdout)
-	}
+// ExplainConfig resolves pnpm's config keys through the command line,
+// pnpm_config_* and npm_config_* variables, the project and user .npmrc, the
+// global rc file and pnpm's defaults
+func (p *PNPMManager) ExplainConfig(ctx context.Context, projectPath string, cli map[string]string) ([]core.ConfigEntry, error) {
+	builtin := builtinConfig(map[string]string{
+		"registry":  registry.DefaultRegistry,
+		"store-dir": p.getDefaultStorePath(),
+	})
+	addProxyEnvDefaults(builtin)
+
+	return explainConfig("pnpm", ConfigFiles("pnpm", projectPath, false), envConfig("pnpm", false), builtin, cli), nil
+}
 
-	// Get proxy
-	result = utils.ExecuteCommand(ctx, "pnpm", "config", "get", "proxy")
-	if result.Error == nil && result.Stdout != "undefined" {
-		config.Proxy = strings.TrimSpace(result.Stdout)
+// SetRegistry sets the registry URL in the user .npmrc
+func (p *PNPMManager) SetRegistry(ctx context.Context, url string) error {
+	if err := setConfigValue(p.configFiles(), "registry", url); err != nil {
+		return core.NewManagerError("pnpm", "set registry", err)
 	}
 
-	// Get store directory
-	result = utils.ExecuteCommand(ctx, "pnpm", "store", "path")
-	if result.Error == nil {
-		config.Settings["store-dir"] = strings.TrimSpace(result.Stdout)
+	p.logger.WithField("registry", url).Info("pnpm registry updated")
+	return nil
+}
+
+// SetProxy sets proxy, https-proxy and noproxy in the user .npmrc; empty values remove them
+func (p *PNPMManager) SetProxy(ctx context.Context, proxy core.ProxySettings) error {
+	if err := setProxyValues(p.configFiles(), proxy); err != nil {
+		return core.NewManagerError("pnpm", "set proxy", err)
 	}
 
-	// Get other common settings
-	settings := []string{"cache-dir", "state-dir", "global-dir"}
-	for _, setting := range settings {
-		result = utils.ExecuteCommand(ctx, "pnpm", "config", "get", setting)
-		if result.Error == nil && result.Stdout != "undefined" {
-			config.Settings[setting] = strings.TrimSpace(result.Stdout)
-		}
+	p.logger.WithField("proxy", utils.RedactURL(proxy.HTTP)).WithField("https_proxy", utils.RedactURL(proxy.HTTPS)).WithField("no_proxy", proxy.NoProxy).Info("pnpm proxy updated")
+	return nil
+}
+
+// GetScopedRegistries returns the pnpm "@scope" -> registry mappings
+func (p *PNPMManager) GetScopedRegistries(ctx context.Context) (map[string]string, error) {
+	return scopedRegistriesFromFiles(p.configFiles()), nil
+}
+
+// SetScopedRegistry maps a scope to a registry in the user .npmrc; an empty
+// URL removes the mapping
+func (p *PNPMManager) SetScopedRegistry(ctx context.Context, scope string, url string) error {
+	if err := setConfigValue(p.configFiles(), NormalizeScope(scope)+":registry", url); err != nil {
+		return core.NewManagerError("pnpm", "set scoped registry", err)
 	}
 
-	return config, nil
+	p.logger.WithField("scope", NormalizeScope(scope)).WithField("registry", url).Info("pnpm scoped registry updated")
+	return nil
 }
 
-// SetRegistry sets the pnpm registry URL
-func (p *PNPMManager) SetRegistry(ctx context.Context, url string) error {
-	result := utils.ExecuteCommand(ctx, "pnpm", "config", "set", "registry", url)
-	if result.Error != nil {
-		return core.NewManagerError("pnpm", "set registry", result.Error)
+// SetConfigValue sets a key in the user config file; an empty value removes it
+func (p *PNPMManager) SetConfigValue(ctx context.Context, key string, value string) error {
+	if err := setConfigValue(p.configFiles(), key, value); err != nil {
+		return core.NewManagerError("pnpm", "set config", err)
 	}
 
-	p.logger.WithField("registry", url).Info("pnpm registry updated")
+	p.logger.WithField("key", key).Info("pnpm config updated")
 	return nil
 }
 
-// SetProxy sets the pnpm proxy configuration
-func (p *PNPMManager) SetProxy(ctx context.Context, proxy string) error {
-	if proxy == "" {
-		// Remove proxy
-		result := utils.ExecuteCommand(ctx, "pnpm", "config", "delete", "proxy")
-		if result.Error != nil {
-			return core.NewManagerError("pnpm", "remove proxy", result.Error)
-		}
-		result = utils.ExecuteCommand(ctx, "pnpm", "config", "delete", "https-proxy")
-		if result.Error != nil {
-			return core.NewManagerError("pnpm", "remove https-proxy", result.Error)
-		}
-	} else {
-		// Set proxy
-		result := utils.ExecuteCommand(ctx, "pnpm", "config", "set", "proxy", proxy)
-		if result.Error != nil {
-			return core.NewManagerError("pnpm", "set proxy", result.Error)
-		}
-		result = utils.ExecuteCommand(ctx, "pnpm", "config", "set", "https-proxy", proxy)
-		if result.Error != nil {
-			return core.NewManagerError("pnpm", "set https-proxy", result.Error)
-		}
+// GetCredentials returns the registries of the user .npmrc with masked tokens
+func (p *PNPMManager) GetCredentials(ctx context.Context) ([]core.Credential, error) {
+	credentials, err := npmrcCredentials("pnpm")
+	if err != nil {
+		return nil, core.NewManagerError("pnpm", "read credentials", err)
+	}
+	return credentials, nil
+}
+
+// SetAuthToken stores a registry token in the user .npmrc
+func (p *PNPMManager) SetAuthToken(ctx context.Context, registry string, token string, envVar string) error {
+	if err := setNpmrcAuthToken(registry, token, envVar); err != nil {
+		return core.NewManagerError("pnpm", "set auth token", err)
 	}
 
-	p.logger.WithField("proxy", proxy).Info("pnpm proxy updated")
+	p.logger.WithField("registry", registry).Info("pnpm auth token updated")
 	return nil
 }
 
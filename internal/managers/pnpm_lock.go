@@ -0,0 +1,192 @@
+package managers
+
+import (
+	"os"
+	"strings"
+
+	"npm-console/internal/core"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pnpmLockfile mirrors the parts of pnpm-lock.yaml we read
+type pnpmLockfile struct {
+	LockfileVersion string                  `yaml:"lockfileVersion"`
+	Importers       map[string]pnpmImporter `yaml:"importers"`
+	Packages        map[string]pnpmPackage  `yaml:"packages"`
+	Snapshots       map[string]pnpmSnapshot `yaml:"snapshots"`
+
+	// Single-project lockfiles keep the root importer at the top level
+	pnpmImporter `yaml:",inline"`
+}
+
+// pnpmImporter is the dependency block of a project in pnpm-lock.yaml
+type pnpmImporter struct {
+	Dependencies         map[string]pnpmDependency `yaml:"dependencies"`
+	DevDependencies      map[string]pnpmDependency `yaml:"devDependencies"`
+	OptionalDependencies map[string]pnpmDependency `yaml:"optionalDependencies"`
+}
+
+// pnpmDependency is an importer dependency, either a bare version or a
+// {specifier, version} mapping depending on the lockfile version
+type pnpmDependency struct {
+	Specifier string `yaml:"specifier"`
+	Version   string `yaml:"version"`
+}
+
+// UnmarshalYAML accepts both the scalar and the mapping dependency forms
+func (d *pnpmDependency) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		d.Version = node.Value
+		return nil
+	}
+
+	type plain pnpmDependency
+	return node.Decode((*plain)(d))
+}
+
+// pnpmPackage is an entry of the "packages" map. Up to lockfile v6 it also
+// carries the dependency graph; v9 moves that into "snapshots".
+type pnpmPackage struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Resolution struct {
+		Integrity string `yaml:"integrity"`
+		Tarball   string `yaml:"tarball"`
+	} `yaml:"resolution"`
+	Dev                  bool              `yaml:"dev"`
+	Optional             bool              `yaml:"optional"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// pnpmSnapshot is an entry of the v9 "snapshots" map, one per peer-resolved package
+type pnpmSnapshot struct {
+	Dev                  bool              `yaml:"dev"`
+	Optional             bool              `yaml:"optional"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+// ParsePNPMLockfile parses a pnpm-lock.yaml file (lockfile v6 or v9). Every
+// workspace project listed under "importers" becomes an importer of the Lockfile.
+func ParsePNPMLockfile(lockPath string) (*Lockfile, error) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return nil, core.NewManagerError("pnpm", "read lockfile", err)
+	}
+
+	var raw pnpmLockfile
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, core.NewManagerError("pnpm", "parse lockfile", err)
+	}
+
+	lockfile := newLockfile("pnpm", lockPath, raw.LockfileVersion)
+
+	// v9 splits package metadata ("packages") from the dependency graph
+	// ("snapshots"); older lockfiles keep both in "packages"
+	snapshots := raw.Snapshots
+	if len(snapshots) == 0 {
+		snapshots = make(map[string]pnpmSnapshot, len(raw.Packages))
+		for key, entry := range raw.Packages {
+			snapshots[key] = pnpmSnapshot{
+				Dev:                  entry.Dev,
+				Optional:             entry.Optional,
+				Dependencies:         entry.Dependencies,
+				OptionalDependencies: entry.OptionalDependencies,
+			}
+		}
+	}
+
+	for key, snapshot := range snapshots {
+		id := strings.TrimPrefix(key, "/")
+		name, version := pnpmParsePackageKey(id)
+
+		entry, ok := raw.Packages[key]
+		if !ok {
+			entry = raw.Packages[pnpmStripPeers(key)]
+		}
+		if entry.Name != "" {
+			name = entry.Name
+		}
+		if entry.Version != "" {
+			version = entry.Version
+		}
+
+		locked := &LockedPackage{
+			ID:           id,
+			Name:         name,
+			Version:      version,
+			Resolved:     entry.Resolution.Tarball,
+			Integrity:    entry.Resolution.Integrity,
+			Dev:          snapshot.Dev,
+			Optional:     snapshot.Optional,
+			Dependencies: make(map[string]string),
+		}
+		for _, deps := range []map[string]string{snapshot.Dependencies, snapshot.OptionalDependencies} {
+			for depName, depVersion := range deps {
+				if depID := pnpmPackageID(depName, depVersion); depID != "" {
+					locked.Dependencies[depName] = depID
+				}
+			}
+		}
+
+		lockfile.Packages[id] = locked
+	}
+
+	if len(raw.Importers) == 0 {
+		raw.Importers = map[string]pnpmImporter{RootImporter: raw.pnpmImporter}
+	}
+	for path, importer := range raw.Importers {
+		lockfile.Importers[path] = &LockImporter{
+			Path:                 path,
+			Dependencies:         pnpmImporterDependencies(importer.Dependencies),
+			DevDependencies:      pnpmImporterDependencies(importer.DevDependencies),
+			OptionalDependencies: pnpmImporterDependencies(importer.OptionalDependencies),
+		}
+	}
+
+	return lockfile, nil
+}
+
+// pnpmImporterDependencies maps importer dependencies to lockfile package keys
+func pnpmImporterDependencies(deps map[string]pnpmDependency) map[string]string {
+	resolved := make(map[string]string)
+	for name, dep := range deps {
+		if id := pnpmPackageID(name, dep.Version); id != "" {
+			resolved[name] = id
+		}
+	}
+	return resolved
+}
+
+// pnpmPackageID returns the package key a dependency version points at.
+// Workspace links ("link:") are not packages and yield an empty key.
+func pnpmPackageID(name, version string) string {
+	switch {
+	case version == "" || strings.HasPrefix(version, "link:"):
+		return ""
+	case strings.HasPrefix(version, "/"):
+		// Aliased or non-registry dependency referencing a full package key (v6)
+		return version[1:]
+	case strings.Contains(pnpmStripPeers(version)[1:], "@"):
+		// Aliased dependency referencing a full package key (v9)
+		return version
+	default:
+		return name + "@" + version
+	}
+}
+
+// pnpmParsePackageKey splits a package key such as "@scope/name@1.0.0(peer@2.0.0)"
+// into its name and version, dropping the peer dependency suffix
+func pnpmParsePackageKey(key string) (string, string) {
+	return splitPackageSpec(pnpmStripPeers(key))
+}
+
+// pnpmStripPeers removes the "(peer@version)" suffix from a package key or version
+func pnpmStripPeers(key string) string {
+	if idx := strings.Index(key, "("); idx > 0 {
+		return key[:idx]
+	}
+	return key
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"npm-console/internal/core"
@@ -17,6 +18,9 @@ import (
 // YarnManager implements the PackageManager interface for yarn
 type YarnManager struct {
 	logger *logger.Logger
+
+	versionOnce sync.Once
+	berry       bool // installed yarn is 2 or later
 }
 
 // NewYarnManager creates a new Yarn manager instance
@@ -113,6 +117,35 @@ func (y *YarnManager) ClearCache(ctx context.Context) error {
 	return nil
 }
 
+// ImportTarballs copies package tarballs into a yarn 1 offline mirror: dir,
+// or the configured yarn-offline-mirror. Yarn 2+ caches zips built from
+// the tarballs rather than the tarballs themselves and is not supported.
+func (y *YarnManager) ImportTarballs(ctx context.Context, packages []core.BundledPackage, dir string) (*core.CacheImportResult, error) {
+	if y.isBerry(ctx, ".") {
+		return nil, core.NewManagerError("yarn", "import tarballs", core.ErrNotSupported)
+	}
+
+	if dir == "" {
+		config, err := y.GetConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		dir = config.Settings["yarn-offline-mirror"]
+	}
+	if dir == "" {
+		return nil, core.NewValidationError("dir", dir, "set yarn-offline-mirror or give the mirror directory")
+	}
+
+	mirror, err := utils.ExpandPath(dir)
+	if err != nil {
+		return nil, core.NewManagerError("yarn", "expand mirror path", err)
+	}
+
+	result := ImportMirrorTarballs(mirror, packages)
+	y.logger.WithField("mirror", mirror).WithField("imported", result.Imported).Info("yarn offline mirror seeded")
+	return result, nil
+}
+
 // GetInstalledPackages returns packages installed in a specific project
 func (y *YarnManager) GetInstalledPackages(ctx context.Context, projectPath string) ([]core.Package, error) {
 	// Check if package.json exists
@@ -121,10 +154,15 @@ func (y *YarnManager) GetInstalledPackages(ctx context.Context, projectPath stri
 		return nil, core.ErrProjectNotFound
 	}
 
-	// Try yarn list first
-	result := utils.ExecuteCommandWithTimeout(30*time.Second, "yarn", "list", "--json", "--depth=0")
-	if result.Error == nil {
-		return y.parseYarnListOutput(result.Stdout)
+	// Read resolved versions straight from yarn.lock, which works without yarn installed
+	lockPath := filepath.Join(projectPath, "yarn.lock")
+	if utils.IsFile(lockPath) {
+		lockfile, err := ParseYarnLockfile(lockPath)
+		if err != nil {
+			y.logger.WithError(err).Warn("Failed to parse yarn.lock, falling back to package.json")
+		} else if root := lockfile.Root(); root != nil {
+			return lockfile.ImporterPackages(root, projectPath), nil
+		}
 	}
 
 	// Fallback to reading package.json
@@ -141,73 +179,139 @@ func (y *YarnManager) GetGlobalPackages(ctx context.Context) ([]core.Package, er
 	return y.parseYarnListOutput(result.Stdout)
 }
 
-// GetConfig returns the current yarn configuration
+// configFiles returns the config files yarn reads from the working directory
+func (y *YarnManager) configFiles(ctx context.Context) []ConfigFile {
+	return ConfigFiles("yarn", ".", y.isBerry(ctx, "."))
+}
+
+// GetConfig returns the effective yarn configuration for the working directory
 func (y *YarnManager) GetConfig(ctx context.Context) (*core.Config, error) {
-	config := &core.Config{
-		Manager:  "yarn",
-		Settings: make(map[string]string),
+	entries, err := y.ExplainConfig(ctx, ".", nil)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get registry
-	result := utils.ExecuteCommand(ctx, "yarn", "config", "get", "registry")
-	if result.Error == nil {
-		config.Registry = strings.TrimSpace(result.Stdout)
+	if y.isBerry(ctx, ".") {
+		return configFromEntries("yarn", entries, "cacheFolder", "globalFolder", "enableGlobalCache"), nil
 	}
+	return configFromEntries("yarn", entries, "cache-folder", "global-folder", "yarn-offline-mirror"), nil
+}
 
-	// Get proxy
-	result = utils.ExecuteCommand(ctx, "yarn", "config", "get", "proxy")
-	if result.Error == nil && result.Stdout != "undefined" {
-		config.Proxy = strings.TrimSpace(result.Stdout)
-	}
+// ExplainConfig resolves yarn's config keys through the command line,
+// environment variables, the project and user .yarnrc.yml (yarn 2+) or
+// .yarnrc and .npmrc (yarn 1) files and yarn's defaults
+func (y *YarnManager) ExplainConfig(ctx context.Context, projectPath string, cli map[string]string) ([]core.ConfigEntry, error) {
+	berry := y.isBerry(ctx, projectPath)
 
-	// Get other common settings
-	settings := []string{"cache-folder", "global-folder", "yarn-offline-mirror"}
-	for _, setting := range settings {
-		result = utils.ExecuteCommand(ctx, "yarn", "config", "get", setting)
-		if result.Error == nil && result.Stdout != "undefined" {
-			config.Settings[setting] = strings.TrimSpace(result.Stdout)
-		}
+	defaults := map[string]string{"registry": yarnBerryDefaultRegistry}
+	if !berry {
+		defaults["cache-folder"] = y.getDefaultCachePath()
+	}
+	builtin := builtinConfig(defaults)
+	if !berry {
+		addProxyEnvDefaults(builtin)
 	}
 
-	return config, nil
+	return explainConfig("yarn", ConfigFiles("yarn", projectPath, berry), envConfig("yarn", berry), builtin, cli), nil
 }
 
-// SetRegistry sets the yarn registry URL
+// SetRegistry sets the registry URL in the user .yarnrc.yml or .yarnrc
 func (y *YarnManager) SetRegistry(ctx context.Context, url string) error {
-	result := utils.ExecuteCommand(ctx, "yarn", "config", "set", "registry", url)
-	if result.Error != nil {
-		return core.NewManagerError("yarn", "set registry", result.Error)
+	if err := setConfigValue(y.configFiles(ctx), "registry", url); err != nil {
+		return core.NewManagerError("yarn", "set registry", err)
 	}
 
 	y.logger.WithField("registry", url).Info("yarn registry updated")
 	return nil
 }
 
-// SetProxy sets the yarn proxy configuration
-func (y *YarnManager) SetProxy(ctx context.Context, proxy string) error {
-	if proxy == "" {
-		// Remove proxy
-		result := utils.ExecuteCommand(ctx, "yarn", "config", "delete", "proxy")
-		if result.Error != nil {
-			return core.NewManagerError("yarn", "remove proxy", result.Error)
-		}
-		result = utils.ExecuteCommand(ctx, "yarn", "config", "delete", "https-proxy")
+// SetProxy sets the http and https proxies and the hosts bypassing them in
+// the user .yarnrc.yml or .yarnrc; empty values remove them. Yarn 2+ has no
+// bypass list, so bypassed hosts get networkSettings without proxies.
+func (y *YarnManager) SetProxy(ctx context.Context, proxy core.ProxySettings) error {
+	if err := setProxyValues(y.configFiles(ctx), proxy); err != nil {
+		return core.NewManagerError("yarn", "set proxy", err)
+	}
+
+	y.logger.WithField("proxy", utils.RedactURL(proxy.HTTP)).WithField("https_proxy", utils.RedactURL(proxy.HTTPS)).WithField("no_proxy", proxy.NoProxy).Info("yarn proxy updated")
+	return nil
+}
+
+// GetScopedRegistries returns the yarn "@scope" -> registry mappings. Yarn
+// Berry keeps them under npmScopes in .yarnrc.yml; yarn 1 uses .npmrc style keys.
+func (y *YarnManager) GetScopedRegistries(ctx context.Context) (map[string]string, error) {
+	return scopedRegistriesFromFiles(y.configFiles(ctx)), nil
+}
+
+// SetScopedRegistry maps a scope to a registry; an empty URL removes the mapping
+func (y *YarnManager) SetScopedRegistry(ctx context.Context, scope string, url string) error {
+	if err := setConfigValue(y.configFiles(ctx), NormalizeScope(scope)+":registry", url); err != nil {
+		return core.NewManagerError("yarn", "set scoped registry", err)
+	}
+
+	y.logger.WithField("scope", NormalizeScope(scope)).WithField("registry", url).Info("yarn scoped registry updated")
+	return nil
+}
+
+// SetConfigValue sets a key in the user config file; an empty value removes it
+func (y *YarnManager) SetConfigValue(ctx context.Context, key string, value string) error {
+	if err := setConfigValue(y.configFiles(ctx), key, value); err != nil {
+		return core.NewManagerError("yarn", "set config", err)
+	}
+
+	y.logger.WithField("key", key).Info("yarn config updated")
+	return nil
+}
+
+// isBerry reports whether yarn 2 or later is used for a project. A
+// .yarnrc.yml decides without running yarn; otherwise the installed version is
+// checked once.
+func (y *YarnManager) isBerry(ctx context.Context, projectPath string) bool {
+	if yarnrcYmlExists(projectPath) {
+		return true
+	}
+
+	y.versionOnce.Do(func() {
+		result := utils.ExecuteCommand(ctx, "yarn", "--version")
 		if result.Error != nil {
-			return core.NewManagerError("yarn", "remove https-proxy", result.Error)
+			return
 		}
+		major, _, _ := strings.Cut(strings.TrimSpace(result.Stdout), ".")
+		y.berry = major != "" && major != "0" && major != "1"
+	})
+	return y.berry
+}
+
+// GetCredentials returns the registries of the user .yarnrc.yml (yarn 2+) or
+// .npmrc (yarn 1) with masked tokens
+func (y *YarnManager) GetCredentials(ctx context.Context) ([]core.Credential, error) {
+	var credentials []core.Credential
+	var err error
+	if y.isBerry(ctx, ".") {
+		credentials, err = yarnrcCredentials()
 	} else {
-		// Set proxy
-		result := utils.ExecuteCommand(ctx, "yarn", "config", "set", "proxy", proxy)
-		if result.Error != nil {
-			return core.NewManagerError("yarn", "set proxy", result.Error)
-		}
-		result = utils.ExecuteCommand(ctx, "yarn", "config", "set", "https-proxy", proxy)
-		if result.Error != nil {
-			return core.NewManagerError("yarn", "set https-proxy", result.Error)
-		}
+		credentials, err = npmrcCredentials("yarn")
+	}
+	if err != nil {
+		return nil, core.NewManagerError("yarn", "read credentials", err)
+	}
+	return credentials, nil
+}
+
+// SetAuthToken stores a registry token in the user .yarnrc.yml (yarn 2+) or
+// .npmrc (yarn 1)
+func (y *YarnManager) SetAuthToken(ctx context.Context, registry string, token string, envVar string) error {
+	var err error
+	if y.isBerry(ctx, ".") {
+		err = setYarnrcAuthToken(registry, token, envVar)
+	} else {
+		err = setNpmrcAuthToken(registry, token, envVar)
+	}
+	if err != nil {
+		return core.NewManagerError("yarn", "set auth token", err)
 	}
 
-	y.logger.WithField("proxy", proxy).Info("yarn proxy updated")
+	y.logger.WithField("registry", registry).Info("yarn auth token updated")
 	return nil
 }
 
@@ -0,0 +1,298 @@
+package managers
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"npm-console/internal/core"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yarnLockEntry is a single block of a classic (v1) yarn.lock file
+type yarnLockEntry struct {
+	descriptors  []string
+	version      string
+	resolved     string
+	integrity    string
+	dependencies map[string]string
+	optional     map[string]string
+}
+
+// yarnBerryEntry is a single entry of a Yarn Berry (v2+) yarn.lock file
+type yarnBerryEntry struct {
+	Version      string            `yaml:"version"`
+	Resolution   string            `yaml:"resolution"`
+	Dependencies map[string]string `yaml:"dependencies"`
+	Checksum     string            `yaml:"checksum"`
+}
+
+// ParseYarnLockfile parses a yarn.lock file in either the classic v1 format
+// or the YAML based format written by Yarn Berry
+func ParseYarnLockfile(lockPath string) (*Lockfile, error) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return nil, core.NewManagerError("yarn", "read lockfile", err)
+	}
+
+	if bytes.Contains(data, []byte("__metadata:")) {
+		return parseYarnBerryLockfile(lockPath, data)
+	}
+
+	return parseYarnV1Lockfile("yarn", lockPath, data)
+}
+
+// parseYarnV1Lockfile builds a Lockfile from classic yarn.lock content. It is also
+// used for bun.lockb, which bun can print in the same format.
+func parseYarnV1Lockfile(manager, lockPath string, data []byte) (*Lockfile, error) {
+	entries, err := parseYarnV1Entries(data)
+	if err != nil {
+		return nil, core.NewManagerError(manager, "parse lockfile", err)
+	}
+
+	lockfile := newLockfile(manager, lockPath, "1")
+
+	// Every descriptor ("name@range") of a block resolves to the same package
+	descriptors := make(map[string]string)
+	for _, entry := range entries {
+		if len(entry.descriptors) == 0 {
+			continue
+		}
+		name, _ := splitPackageSpec(entry.descriptors[0])
+		id := name + "@" + entry.version
+
+		if _, exists := lockfile.Packages[id]; !exists {
+			lockfile.Packages[id] = &LockedPackage{
+				ID:           id,
+				Name:         name,
+				Version:      entry.version,
+				Resolved:     entry.resolved,
+				Integrity:    entry.integrity,
+				Dependencies: make(map[string]string),
+			}
+		}
+		for _, descriptor := range entry.descriptors {
+			descriptors[descriptor] = id
+		}
+	}
+
+	for _, entry := range entries {
+		if len(entry.descriptors) == 0 {
+			continue
+		}
+		locked := lockfile.Packages[descriptors[entry.descriptors[0]]]
+		for _, deps := range []map[string]string{entry.dependencies, entry.optional} {
+			for name, spec := range deps {
+				if id, ok := descriptors[name+"@"+spec]; ok {
+					locked.Dependencies[name] = id
+				}
+			}
+		}
+	}
+
+	// yarn.lock does not record the project's own dependencies, so resolve
+	// the package.json ranges against the lockfile descriptors
+	manifest, err := readManifestDependencies(filepath.Dir(lockPath))
+	if err != nil {
+		return nil, core.NewManagerError(manager, "read package.json", err)
+	}
+	lockfile.Importers[RootImporter] = &LockImporter{
+		Path:                 RootImporter,
+		Dependencies:         resolveYarnDescriptors(descriptors, manifest.Dependencies),
+		DevDependencies:      resolveYarnDescriptors(descriptors, manifest.DevDependencies),
+		OptionalDependencies: resolveYarnDescriptors(descriptors, manifest.OptionalDependencies),
+	}
+
+	return lockfile, nil
+}
+
+// parseYarnBerryLockfile parses the YAML lockfile written by Yarn Berry. Entries
+// are keyed by their resolution locator ("name@npm:1.2.3") and workspaces become
+// importers keyed by their folder relative to the lockfile.
+func parseYarnBerryLockfile(lockPath string, data []byte) (*Lockfile, error) {
+	var raw map[string]yarnBerryEntry
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, core.NewManagerError("yarn", "parse lockfile", err)
+	}
+
+	lockfile := newLockfile("yarn", lockPath, raw["__metadata"].Version)
+	delete(raw, "__metadata")
+
+	descriptors := make(map[string]string)
+	for key, entry := range raw {
+		for _, descriptor := range strings.Split(key, ",") {
+			descriptors[strings.TrimSpace(descriptor)] = entry.Resolution
+		}
+	}
+
+	workspaces := make(map[string]yarnBerryEntry)
+	for _, entry := range raw {
+		name, reference := splitPackageSpec(entry.Resolution)
+		if strings.HasPrefix(reference, "workspace:") {
+			workspaces[strings.TrimPrefix(reference, "workspace:")] = entry
+			continue
+		}
+
+		locked := &LockedPackage{
+			ID:           entry.Resolution,
+			Name:         name,
+			Version:      entry.Version,
+			Resolved:     entry.Resolution,
+			Integrity:    entry.Checksum,
+			Dependencies: resolveYarnBerryDependencies(descriptors, entry.Dependencies),
+		}
+		lockfile.Packages[locked.ID] = locked
+	}
+
+	// Berry records all workspace dependencies together, so use each
+	// workspace's package.json to tell dev and optional dependencies apart
+	for path, entry := range workspaces {
+		resolved := resolveYarnBerryDependencies(descriptors, entry.Dependencies)
+		importer := &LockImporter{
+			Path:                 path,
+			Dependencies:         make(map[string]string),
+			DevDependencies:      make(map[string]string),
+			OptionalDependencies: make(map[string]string),
+		}
+
+		manifest, err := readManifestDependencies(filepath.Join(filepath.Dir(lockPath), filepath.FromSlash(path)))
+		if err != nil {
+			manifest = &manifestDependencies{}
+		}
+		for name, id := range resolved {
+			switch {
+			case manifest.DevDependencies[name] != "":
+				importer.DevDependencies[name] = id
+			case manifest.OptionalDependencies[name] != "":
+				importer.OptionalDependencies[name] = id
+			default:
+				importer.Dependencies[name] = id
+			}
+		}
+
+		lockfile.Importers[path] = importer
+	}
+
+	return lockfile, nil
+}
+
+// resolveYarnBerryDependencies maps Berry dependency ranges to resolution locators.
+// Older Berry lockfiles omit the default "npm:" protocol from dependency ranges.
+func resolveYarnBerryDependencies(descriptors map[string]string, deps map[string]string) map[string]string {
+	resolved := make(map[string]string)
+	for name, spec := range deps {
+		if id, ok := descriptors[name+"@"+spec]; ok {
+			resolved[name] = id
+		} else if id, ok := descriptors[name+"@npm:"+spec]; ok {
+			resolved[name] = id
+		}
+	}
+	return resolved
+}
+
+// resolveYarnDescriptors maps package.json ranges to lockfile package keys
+func resolveYarnDescriptors(descriptors map[string]string, deps map[string]string) map[string]string {
+	resolved := make(map[string]string)
+	for name, spec := range deps {
+		if id, ok := descriptors[name+"@"+spec]; ok {
+			resolved[name] = id
+		}
+	}
+	return resolved
+}
+
+// parseYarnV1Entries splits a classic yarn.lock into its blocks
+func parseYarnV1Entries(data []byte) ([]*yarnLockEntry, error) {
+	var entries []*yarnLockEntry
+	var current *yarnLockEntry
+	var section map[string]string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indent := len(line) - len(trimmed)
+		switch {
+		case indent == 0:
+			// Block header: one or more comma separated descriptors
+			current = &yarnLockEntry{
+				dependencies: make(map[string]string),
+				optional:     make(map[string]string),
+			}
+			for _, descriptor := range strings.Split(strings.TrimSuffix(trimmed, ":"), ",") {
+				current.descriptors = append(current.descriptors, yarnUnquote(strings.TrimSpace(descriptor)))
+			}
+			entries = append(entries, current)
+			section = nil
+
+		case current == nil:
+			continue
+
+		case indent <= 2:
+			key, value := splitYarnLine(trimmed)
+			section = nil
+			switch key {
+			case "version":
+				current.version = value
+			case "resolved":
+				current.resolved = value
+			case "integrity":
+				current.integrity = value
+			case "dependencies":
+				section = current.dependencies
+			case "optionalDependencies":
+				section = current.optional
+			}
+
+		default:
+			if section != nil {
+				key, value := splitYarnLine(trimmed)
+				section[key] = value
+			}
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// splitYarnLine splits a yarn.lock body line into its (unquoted) key and value
+func splitYarnLine(line string) (string, string) {
+	var key, rest string
+	if strings.HasPrefix(line, `"`) {
+		end := strings.Index(line[1:], `"`)
+		if end < 0 {
+			return yarnUnquote(line), ""
+		}
+		key, rest = line[1:end+1], line[end+2:]
+	} else {
+		idx := strings.IndexAny(line, " :")
+		if idx < 0 {
+			return line, ""
+		}
+		key, rest = line[:idx], line[idx:]
+	}
+
+	rest = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), ":"))
+	return key, yarnUnquote(rest)
+}
+
+// yarnUnquote removes surrounding double quotes from a yarn.lock token
+func yarnUnquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return value[1 : len(value)-1]
+	}
+	return value
+}
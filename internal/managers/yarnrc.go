@@ -0,0 +1,457 @@
+package managers
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"npm-console/internal/core"
+	"npm-console/pkg/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YarnrcPath returns the user-level .yarnrc.yml read by yarn Berry
+func YarnrcPath() (string, error) {
+	home, err := utils.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".yarnrc.yml"), nil
+}
+
+// yarnrc mirrors the registry related parts of a yarn Berry .yarnrc.yml
+type yarnrc struct {
+	NpmRegistryServer string `yaml:"npmRegistryServer"`
+	NpmAuthToken      string `yaml:"npmAuthToken"`
+	HTTPSProxy        string `yaml:"httpsProxy"`
+	HTTPProxy         string `yaml:"httpProxy"`
+	NpmScopes         map[string]struct {
+		NpmRegistryServer string `yaml:"npmRegistryServer"`
+		NpmAuthToken      string `yaml:"npmAuthToken"`
+	} `yaml:"npmScopes"`
+	NpmRegistries map[string]struct {
+		NpmAuthToken string `yaml:"npmAuthToken"`
+	} `yaml:"npmRegistries"`
+}
+
+// yarnrcKeys maps top-level .yarnrc.yml settings to npm's key names
+var yarnrcKeys = map[string]string{
+	"npmRegistryServer": "registry",
+	"npmAuthToken":      "_authToken",
+	"httpProxy":         "proxy",
+	"httpsProxy":        "https-proxy",
+}
+
+// yarnrcKey returns the .yarnrc.yml name of an npm key
+func yarnrcKey(key string) string {
+	for name, npmKey := range yarnrcKeys {
+		if npmKey == key {
+			return name
+		}
+	}
+	return key
+}
+
+// yarnrcYmlExists reports whether a project or the user has a .yarnrc.yml,
+// which only yarn 2+ reads
+func yarnrcYmlExists(projectPath string) bool {
+	if projectPath != "" && utils.IsFile(filepath.Join(projectPath, ".yarnrc.yml")) {
+		return true
+	}
+	path, err := YarnrcPath()
+	return err == nil && utils.IsFile(path)
+}
+
+// readYarnrcValues flattens a .yarnrc.yml into npm style keys: npmScopes
+// become "@scope:registry" and registry tokens "//host/path/:_authToken"
+func readYarnrcValues(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		return values, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("not a YAML mapping")
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i].Value, root.Content[i+1]
+		switch {
+		case key == "npmScopes" && value.Kind == yaml.MappingNode:
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				scope := value.Content[j+1]
+				server := yamlScalar(scope, "npmRegistryServer")
+				if server == "" {
+					continue
+				}
+				values[NormalizeScope(value.Content[j].Value)+":registry"] = server
+				if token := yamlScalar(scope, "npmAuthToken"); token != "" {
+					values[NerfDart(server)+":_authToken"] = token
+				}
+			}
+		case key == "networkSettings" && value.Kind == yaml.MappingNode:
+			if hosts := yarnrcNoProxy(value); len(hosts) > 0 {
+				values["noproxy"] = strings.Join(hosts, ",")
+			}
+		case key == "npmRegistries" && value.Kind == yaml.MappingNode:
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				if token := yamlScalar(value.Content[j+1], "npmAuthToken"); token != "" {
+					values[NerfDart(value.Content[j].Value)+":_authToken"] = token
+				}
+			}
+		case value.Kind == yaml.ScalarNode:
+			if npmKey, ok := yarnrcKeys[key]; ok {
+				key = npmKey
+			}
+			values[key] = value.Value
+		}
+	}
+
+	return values, nil
+}
+
+// setYarnrcValue sets an npm style key in a .yarnrc.yml; an empty value
+// removes it
+func setYarnrcValue(path, key, value string) error {
+	return editYarnrc(path, func(root *yaml.Node) {
+		switch {
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			setYarnrcScope(root, strings.TrimSuffix(key, ":registry"), value)
+		case strings.HasPrefix(key, "//") && strings.HasSuffix(key, ":_authToken"):
+			setYarnrcToken(root, strings.TrimSuffix(key, ":_authToken"), value)
+		case key == "noproxy":
+			setYarnrcNoProxy(root, value)
+		case value == "":
+			yamlDelete(root, yarnrcKey(key))
+		default:
+			yamlSet(root, yarnrcKey(key), value)
+		}
+	})
+}
+
+// setYarnrcScope sets npmScopes.<scope>.npmRegistryServer. An empty URL
+// removes the scope.
+func setYarnrcScope(root *yaml.Node, scope, url string) {
+	name := strings.TrimPrefix(NormalizeScope(scope), "@")
+
+	scopes := yamlMapping(root, "npmScopes", url != "")
+	if scopes == nil {
+		return
+	}
+
+	if url == "" {
+		yamlDelete(scopes, name)
+		if len(scopes.Content) == 0 {
+			yamlDelete(root, "npmScopes")
+		}
+		return
+	}
+	yamlSet(yamlMapping(scopes, name, true), "npmRegistryServer", url)
+}
+
+// setYarnrcToken sets the token of a nerf-darted registry: npmAuthToken for
+// the default registry, otherwise under npmRegistries. An empty token
+// removes it.
+func setYarnrcToken(root *yaml.Node, nerfed, token string) {
+	defaultRegistry := yarnBerryDefaultRegistry
+	if server := yamlScalar(root, "npmRegistryServer"); server != "" {
+		defaultRegistry = server
+	}
+
+	if nerfed == NerfDart(defaultRegistry) {
+		if token == "" {
+			yamlDelete(root, "npmAuthToken")
+		} else {
+			yamlSet(root, "npmAuthToken", token)
+		}
+		return
+	}
+
+	registries := yamlMapping(root, "npmRegistries", token != "")
+	if registries == nil {
+		return
+	}
+
+	// Reuse the key of an existing entry for the same registry
+	key := "https:" + strings.TrimSuffix(nerfed, "/")
+	for i := 0; i+1 < len(registries.Content); i += 2 {
+		if NerfDart(registries.Content[i].Value) == nerfed {
+			key = registries.Content[i].Value
+		}
+	}
+
+	if token == "" {
+		if entry := yamlMapping(registries, key, false); entry != nil {
+			yamlDelete(entry, "npmAuthToken")
+			if len(entry.Content) == 0 {
+				yamlDelete(registries, key)
+			}
+		}
+		if len(registries.Content) == 0 {
+			yamlDelete(root, "npmRegistries")
+		}
+		return
+	}
+	yamlSet(yamlMapping(registries, key, true), "npmAuthToken", token)
+}
+
+// yarnrcNoProxy returns the hosts whose networkSettings disable both proxies,
+// folding the "*.host" glob written for subdomains into "host"
+func yarnrcNoProxy(settings *yaml.Node) []string {
+	var hosts []string
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(settings.Content); i += 2 {
+		if !isYarnrcBypass(settings.Content[i+1]) {
+			continue
+		}
+		host := settings.Content[i].Value
+		if host != "*" {
+			host = strings.TrimPrefix(host, "*.")
+		}
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// setYarnrcNoProxy turns a NO_PROXY style list into networkSettings entries
+// with empty httpProxy and httpsProxy, which yarn 2+ matches against the
+// hostname of each request. The entries of the previous list are replaced.
+func setYarnrcNoProxy(root *yaml.Node, noProxy string) {
+	hosts := core.ParseNoProxy(noProxy)
+	settings := yamlMapping(root, "networkSettings", len(hosts) > 0)
+	if settings == nil {
+		return
+	}
+
+	for i := 0; i+1 < len(settings.Content); {
+		entry := settings.Content[i+1]
+		if isYarnrcBypass(entry) {
+			yamlDelete(entry, "httpProxy")
+			yamlDelete(entry, "httpsProxy")
+			if len(entry.Content) == 0 {
+				settings.Content = append(settings.Content[:i], settings.Content[i+2:]...)
+				continue
+			}
+		}
+		i += 2
+	}
+
+	for _, host := range hosts {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.TrimPrefix(strings.TrimPrefix(host, "*"), ".")
+
+		globs := []string{"*"}
+		if host != "" {
+			globs = []string{host, "*." + host}
+		}
+		for _, glob := range globs {
+			entry := yamlMapping(settings, glob, true)
+			for _, key := range []string{"httpProxy", "httpsProxy"} {
+				yamlSet(entry, key, "")
+				yamlLookup(entry, key).Style = yaml.DoubleQuotedStyle
+			}
+		}
+	}
+
+	if len(settings.Content) == 0 {
+		yamlDelete(root, "networkSettings")
+	}
+}
+
+// isYarnrcBypass reports whether a networkSettings entry sets both proxies
+// to an empty string, which makes yarn connect directly
+func isYarnrcBypass(entry *yaml.Node) bool {
+	if entry.Kind != yaml.MappingNode {
+		return false
+	}
+	for _, key := range []string{"httpProxy", "httpsProxy"} {
+		value := yamlLookup(entry, key)
+		if value == nil || value.Kind != yaml.ScalarNode || value.Value != "" || value.ShortTag() == "!!null" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseYarnClassic parses the `key "value"` lines of a yarn 1 .yarnrc
+func parseYarnClassic(data []byte) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, value, ok := yarnClassicEntry(line); ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// yarnClassicEntry splits a .yarnrc line into its key and value
+func yarnClassicEntry(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+
+	key, rest := yarnClassicToken(line)
+	key = strings.TrimSuffix(key, ":")
+	value, _ := yarnClassicToken(rest)
+	return key, value, key != ""
+}
+
+// yarnClassicToken returns the first, optionally quoted, token of s and the rest
+func yarnClassicToken(s string) (string, string) {
+	if strings.HasPrefix(s, `"`) {
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '"' {
+				token, err := strconv.Unquote(s[:i+1])
+				if err != nil {
+					token = s[1:i]
+				}
+				return token, strings.TrimSpace(s[i+1:])
+			}
+		}
+	}
+
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], strings.TrimSpace(s[i+1:])
+	}
+	return s, ""
+}
+
+// setYarnClassicValue sets one key of a yarn 1 .yarnrc in place; an empty
+// value removes it
+func setYarnClassicValue(path, key, value string) error {
+	line := ""
+	if value != "" {
+		name := key
+		if strings.ContainsAny(name, "@:/ ") {
+			name = strconv.Quote(name)
+		}
+		line = name + " " + strconv.Quote(value)
+	}
+
+	return setConfigLine(path, key, line, func(line string) (string, bool) {
+		k, _, ok := yarnClassicEntry(line)
+		return k, ok
+	})
+}
+
+// editYarnrc applies edit to the root mapping of a .yarnrc.yml and writes it
+// back. The YAML tree is edited in place so comments and key order survive.
+func editYarnrc(path string, edit func(root *yaml.Node)) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a YAML mapping", path)
+	}
+
+	edit(root)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	encoder.Close()
+
+	if err := utils.MakeDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, buf.Bytes(), 0600)
+}
+
+// yamlLookup returns the value stored under key in a mapping
+func yamlLookup(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlScalar returns the scalar stored under key in a mapping, or ""
+func yamlScalar(mapping *yaml.Node, key string) string {
+	if value := yamlLookup(mapping, key); value != nil && value.Kind == yaml.ScalarNode {
+		return value.Value
+	}
+	return ""
+}
+
+// yamlMapping returns the mapping stored under key, optionally creating it
+func yamlMapping(mapping *yaml.Node, key string, create bool) *yaml.Node {
+	if value := yamlLookup(mapping, key); value != nil {
+		if value.Kind != yaml.MappingNode {
+			if !create {
+				return nil
+			}
+			*value = yaml.Node{Kind: yaml.MappingNode}
+		}
+		return value
+	}
+
+	if !create {
+		return nil
+	}
+
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
+
+// yamlSet sets a scalar value in a mapping
+func yamlSet(mapping *yaml.Node, key, value string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1].Kind = yaml.ScalarNode
+			mapping.Content[i+1].Tag = ""
+			mapping.Content[i+1].Value = value
+			return
+		}
+	}
+
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: value},
+	)
+}
+
+// yamlDelete removes a key from a mapping
+func yamlDelete(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package managers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetYarnrcScope(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".yarnrc.yml")
+
+	os.WriteFile(path, []byte(`# registry settings
+npmRegistryServer: "https://registry.npmjs.org"
+`), 0644)
+
+	if err := setYarnrcValue(path, "@corp:registry", "https://npm.corp.local"); err != nil {
+		t.Fatalf("setYarnrcValue() error = %v", err)
+	}
+
+	settings := LoadRegistrySettings("yarn", "")
+	if settings.Scopes["@corp"] != "https://npm.corp.local" {
+		t.Errorf("Scopes = %v", settings.Scopes)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "# registry settings") {
+		t.Errorf(".yarnrc.yml lost its comment:\n%s", data)
+	}
+
+	if err := setYarnrcValue(path, "@corp:registry", ""); err != nil {
+		t.Fatalf("setYarnrcValue() error = %v", err)
+	}
+
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "npmScopes") {
+		t.Errorf("npmScopes was not removed:\n%s", data)
+	}
+}
+
+func TestSetYarnrcNoProxy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".yarnrc.yml")
+	os.WriteFile(path, []byte(`networkSettings:
+  "registry.corp.local":
+    caFilePath: /etc/ssl/corp.pem
+`), 0644)
+
+	if err := setYarnrcValue(path, "noproxy", "registry.corp.local, .internal"); err != nil {
+		t.Fatalf("setYarnrcValue() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	values, err := readYarnrcValues(data)
+	if err != nil {
+		t.Fatalf("readYarnrcValues() error = %v", err)
+	}
+	if values["noproxy"] != "registry.corp.local,internal" {
+		t.Errorf("noproxy = %q, want registry.corp.local,internal", values["noproxy"])
+	}
+
+	if err := setYarnrcValue(path, "noproxy", ""); err != nil {
+		t.Fatalf("setYarnrcValue() error = %v", err)
+	}
+
+	data, _ = os.ReadFile(path)
+	content := string(data)
+	if strings.Contains(content, "httpProxy") || strings.Contains(content, "internal") {
+		t.Errorf("bypass entries were not removed:\n%s", content)
+	}
+	if !strings.Contains(content, "caFilePath") {
+		t.Errorf("other network settings were lost:\n%s", content)
+	}
+}
@@ -0,0 +1,116 @@
+package pac
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// builtin is a PAC helper function
+type builtin func(s *Script, args []any) (any, error)
+
+// builtins are the helper functions PAC files may call. The date and time
+// functions are left out: a proxy that depends on the clock cannot be
+// written to a config file.
+var builtins = map[string]builtin{
+	"isPlainHostName": func(s *Script, args []any) (any, error) {
+		return !strings.Contains(stringArg(args, 0), "."), nil
+	},
+	"dnsDomainIs": func(s *Script, args []any) (any, error) {
+		host, domain := strings.ToLower(stringArg(args, 0)), strings.ToLower(stringArg(args, 1))
+		return strings.HasSuffix(host, domain), nil
+	},
+	"localHostOrDomainIs": func(s *Script, args []any) (any, error) {
+		host, hostdom := strings.ToLower(stringArg(args, 0)), strings.ToLower(stringArg(args, 1))
+		if host == hostdom {
+			return true, nil
+		}
+		return !strings.Contains(host, ".") && strings.HasPrefix(hostdom, host+"."), nil
+	},
+	"isResolvable": func(s *Script, args []any) (any, error) {
+		return s.resolve(stringArg(args, 0)) != "", nil
+	},
+	"dnsResolve": func(s *Script, args []any) (any, error) {
+		if ip := s.resolve(stringArg(args, 0)); ip != "" {
+			return ip, nil
+		}
+		return nil, nil
+	},
+	"myIpAddress": func(s *Script, args []any) (any, error) {
+		return s.MyIPAddress(), nil
+	},
+	"isInNet": func(s *Script, args []any) (any, error) {
+		ip := net.ParseIP(s.resolve(stringArg(args, 0))).To4()
+		pattern := net.ParseIP(stringArg(args, 1)).To4()
+		mask := net.ParseIP(stringArg(args, 2)).To4()
+		if ip == nil || pattern == nil || mask == nil {
+			return false, nil
+		}
+		return ip.Mask(net.IPMask(mask)).Equal(pattern.Mask(net.IPMask(mask))), nil
+	},
+	"dnsDomainLevels": func(s *Script, args []any) (any, error) {
+		return float64(strings.Count(stringArg(args, 0), ".")), nil
+	},
+	"shExpMatch": func(s *Script, args []any) (any, error) {
+		re, err := shExpRegexp(stringArg(args, 1))
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(stringArg(args, 0)), nil
+	},
+	"convert_addr": func(s *Script, args []any) (any, error) {
+		ip := net.ParseIP(stringArg(args, 0)).To4()
+		if ip == nil {
+			return float64(0), nil
+		}
+		return float64(uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])), nil
+	},
+	"alert": func(s *Script, args []any) (any, error) {
+		return nil, nil
+	},
+}
+
+// resolve returns an IP address as is and looks up anything else
+func (s *Script) resolve(host string) string {
+	if host == "" {
+		return ""
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return s.LookupHost(host)
+}
+
+// stringArg returns an argument as a string, with null and missing
+// arguments as ""
+func stringArg(args []any, i int) string {
+	if i < len(args) && args[i] != nil {
+		return toString(args[i])
+	}
+	return ""
+}
+
+// shExpRegexp compiles a shell expression, where * matches any run of
+// characters and ? any single character, "/" included
+func shExpRegexp(shexp string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range shexp {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid shell expression %q: %w", shexp, err)
+	}
+	return re, nil
+}
@@ -0,0 +1,544 @@
+// Package pac evaluates proxy auto-config (PAC) files. It implements the part
+// of JavaScript PAC files are written in: function and variable declarations,
+// if/else, return, string, number and boolean expressions, a few string
+// methods and the standard PAC helper functions. Loops and regular
+// expressions are not supported.
+package pac
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxCallDepth bounds recursion between the functions of a script
+const maxCallDepth = 64
+
+// Script is a parsed PAC file
+type Script struct {
+	body  []stmt
+	funcs map[string]*function
+
+	// LookupHost resolves a host name to an IP address, or returns "" when it
+	// cannot be resolved. It defaults to a DNS lookup.
+	LookupHost func(host string) string
+	// MyIPAddress returns the address of this machine, used by myIpAddress()
+	MyIPAddress func() string
+}
+
+// Parse parses the source of a PAC file, which must declare FindProxyForURL
+func Parse(src string) (*Script, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, funcs: make(map[string]*function)}
+	var body []stmt
+	for p.peek().kind != tokEOF {
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, s)
+	}
+
+	if _, ok := p.funcs["FindProxyForURL"]; !ok {
+		return nil, fmt.Errorf("FindProxyForURL is not defined")
+	}
+
+	return &Script{body: body, funcs: p.funcs, LookupHost: lookupHost, MyIPAddress: myIPAddress}, nil
+}
+
+// ParseFile reads and parses a PAC file
+func ParseFile(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	script, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return script, nil
+}
+
+// FindProxyForURL runs the script's FindProxyForURL for a URL and returns its
+// result, e.g. "PROXY proxy.corp.local:8080; DIRECT"
+func (s *Script) FindProxyForURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	e := &evaluator{script: s, globals: &scope{vars: make(map[string]any)}}
+	if _, _, err := e.execBlock(s.body, e.globals); err != nil {
+		return "", err
+	}
+
+	result, err := e.call(s.funcs["FindProxyForURL"], []any{rawURL, u.Hostname()})
+	if err != nil {
+		return "", err
+	}
+	str, ok := result.(string)
+	if !ok {
+		return "", fmt.Errorf("FindProxyForURL returned %s, not a string", toString(result))
+	}
+	return str, nil
+}
+
+// scope holds the variables of a function call, or the globals
+type scope struct {
+	vars   map[string]any
+	parent *scope
+}
+
+func (sc *scope) lookup(name string) (any, bool) {
+	for ; sc != nil; sc = sc.parent {
+		if value, ok := sc.vars[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// assign sets an existing variable, or creates a global as JavaScript does
+func (sc *scope) assign(name string, value any) {
+	for s := sc; ; s = s.parent {
+		if _, ok := s.vars[name]; ok || s.parent == nil {
+			s.vars[name] = value
+			return
+		}
+	}
+}
+
+type evaluator struct {
+	script  *Script
+	globals *scope
+	depth   int
+}
+
+func (e *evaluator) call(fn *function, args []any) (any, error) {
+	if e.depth >= maxCallDepth {
+		return nil, fmt.Errorf("%s: too much recursion", fn.name)
+	}
+	e.depth++
+	defer func() { e.depth-- }()
+
+	sc := &scope{vars: make(map[string]any), parent: e.globals}
+	for i, param := range fn.params {
+		var arg any
+		if i < len(args) {
+			arg = args[i]
+		}
+		sc.vars[param] = arg
+	}
+
+	_, value, err := e.execBlock(fn.body, sc)
+	return value, err
+}
+
+// execBlock runs statements until one returns, reporting whether one did
+func (e *evaluator) execBlock(body []stmt, sc *scope) (bool, any, error) {
+	for _, s := range body {
+		if returned, value, err := e.exec(s, sc); returned || err != nil {
+			return returned, value, err
+		}
+	}
+	return false, nil, nil
+}
+
+func (e *evaluator) exec(s stmt, sc *scope) (bool, any, error) {
+	switch s := s.(type) {
+	case blockStmt:
+		return e.execBlock(s.body, sc)
+	case varStmt:
+		for i, name := range s.names {
+			var value any
+			if s.values[i] != nil {
+				var err error
+				if value, err = e.eval(s.values[i], sc); err != nil {
+					return false, nil, err
+				}
+			}
+			sc.vars[name] = value
+		}
+	case ifStmt:
+		cond, err := e.eval(s.cond, sc)
+		if err != nil {
+			return false, nil, err
+		}
+		if truthy(cond) {
+			return e.exec(s.then, sc)
+		}
+		if s.els != nil {
+			return e.exec(s.els, sc)
+		}
+	case returnStmt:
+		if s.value == nil {
+			return true, nil, nil
+		}
+		value, err := e.eval(s.value, sc)
+		return true, value, err
+	case exprStmt:
+		_, err := e.eval(s.x, sc)
+		return false, nil, err
+	}
+	return false, nil, nil
+}
+
+func (e *evaluator) eval(x expr, sc *scope) (any, error) {
+	switch x := x.(type) {
+	case literal:
+		return x.value, nil
+	case ident:
+		if value, ok := sc.lookup(x.name); ok {
+			return value, nil
+		}
+		return nil, fmt.Errorf("line %d: %s is not defined", x.line, x.name)
+	case assignExpr:
+		value, err := e.eval(x.value, sc)
+		if err != nil {
+			return nil, err
+		}
+		if x.op == "+=" {
+			current, _ := sc.lookup(x.name)
+			value = add(current, value)
+		}
+		sc.assign(x.name, value)
+		return value, nil
+	case condExpr:
+		cond, err := e.eval(x.cond, sc)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(cond) {
+			return e.eval(x.then, sc)
+		}
+		return e.eval(x.els, sc)
+	case unaryExpr:
+		value, err := e.eval(x.x, sc)
+		if err != nil {
+			return nil, err
+		}
+		switch x.op {
+		case "!":
+			return !truthy(value), nil
+		case "-":
+			return -toNumber(value), nil
+		default:
+			return toNumber(value), nil
+		}
+	case binaryExpr:
+		return e.evalBinary(x, sc)
+	case memberExpr:
+		object, err := e.eval(x.object, sc)
+		if err != nil {
+			return nil, err
+		}
+		if str, ok := object.(string); ok && x.name == "length" {
+			return float64(len(str)), nil
+		}
+		return nil, fmt.Errorf("property %s of %s is not supported", x.name, toString(object))
+	case callExpr:
+		return e.evalCall(x, sc)
+	}
+	return nil, fmt.Errorf("unsupported expression %T", x)
+}
+
+func (e *evaluator) evalBinary(x binaryExpr, sc *scope) (any, error) {
+	left, err := e.eval(x.left, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || only evaluate their right side when needed and return an operand
+	switch x.op {
+	case "&&":
+		if !truthy(left) {
+			return left, nil
+		}
+		return e.eval(x.right, sc)
+	case "||":
+		if truthy(left) {
+			return left, nil
+		}
+		return e.eval(x.right, sc)
+	}
+
+	right, err := e.eval(x.right, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x.op {
+	case "===":
+		return left == right, nil
+	case "!==":
+		return left != right, nil
+	case "==":
+		return looseEqual(left, right), nil
+	case "!=":
+		return !looseEqual(left, right), nil
+	case "+":
+		return add(left, right), nil
+	case "-":
+		return toNumber(left) - toNumber(right), nil
+	case "*":
+		return toNumber(left) * toNumber(right), nil
+	case "/":
+		return toNumber(left) / toNumber(right), nil
+	case "%":
+		return math.Mod(toNumber(left), toNumber(right)), nil
+	}
+
+	// Relational operators compare strings as strings, anything else as numbers
+	ls, lok := left.(string)
+	rs, rok := right.(string)
+	if lok && rok {
+		return compare(x.op, strings.Compare(ls, rs), 0), nil
+	}
+	l, r := toNumber(left), toNumber(right)
+	if math.IsNaN(l) || math.IsNaN(r) {
+		return false, nil
+	}
+	switch {
+	case l < r:
+		return compare(x.op, -1, 0), nil
+	case l > r:
+		return compare(x.op, 1, 0), nil
+	}
+	return compare(x.op, 0, 0), nil
+}
+
+func compare(op string, a, b int) bool {
+	switch op {
+	case "<":
+		return a < b
+	case ">":
+		return a > b
+	case "<=":
+		return a <= b
+	default:
+		return a >= b
+	}
+}
+
+func (e *evaluator) evalCall(x callExpr, sc *scope) (any, error) {
+	args := make([]any, len(x.args))
+	for i, arg := range x.args {
+		value, err := e.eval(arg, sc)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	switch callee := x.callee.(type) {
+	case ident:
+		if fn, ok := e.script.funcs[callee.name]; ok {
+			return e.call(fn, args)
+		}
+		if builtin, ok := builtins[callee.name]; ok {
+			return builtin(e.script, args)
+		}
+		return nil, fmt.Errorf("line %d: function %s is not supported", x.line, callee.name)
+	case memberExpr:
+		object, err := e.eval(callee.object, sc)
+		if err != nil {
+			return nil, err
+		}
+		str, ok := object.(string)
+		if !ok {
+			return nil, fmt.Errorf("line %d: %s.%s() needs a string", x.line, toString(object), callee.name)
+		}
+		return stringMethod(str, callee.name, args, x.line)
+	}
+	return nil, fmt.Errorf("line %d: expression is not a function", x.line)
+}
+
+// stringMethod calls one of the String methods PAC files commonly use
+func stringMethod(s, name string, args []any, line int) (any, error) {
+	arg := func(i int) any {
+		if i < len(args) {
+			return args[i]
+		}
+		return nil
+	}
+
+	switch name {
+	case "toLowerCase":
+		return strings.ToLower(s), nil
+	case "toUpperCase":
+		return strings.ToUpper(s), nil
+	case "indexOf":
+		return float64(strings.Index(s, toString(arg(0)))), nil
+	case "lastIndexOf":
+		return float64(strings.LastIndex(s, toString(arg(0)))), nil
+	case "startsWith":
+		return strings.HasPrefix(s, toString(arg(0))), nil
+	case "endsWith":
+		return strings.HasSuffix(s, toString(arg(0))), nil
+	case "substring":
+		start := clampIndex(toNumber(arg(0)), len(s))
+		end := len(s)
+		if arg(1) != nil {
+			end = clampIndex(toNumber(arg(1)), len(s))
+		}
+		if start > end {
+			start, end = end, start
+		}
+		return s[start:end], nil
+	}
+	return nil, fmt.Errorf("line %d: string method %s is not supported", line, name)
+}
+
+func clampIndex(n float64, length int) int {
+	switch {
+	case math.IsNaN(n) || n < 0:
+		return 0
+	case n > float64(length):
+		return length
+	}
+	return int(n)
+}
+
+func truthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	}
+	return true
+}
+
+func toString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+func toNumber(value any) float64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case float64:
+		return v
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return 0
+		}
+		if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return n
+		}
+	}
+	return math.NaN()
+}
+
+func add(left, right any) any {
+	_, ls := left.(string)
+	_, rs := right.(string)
+	if ls || rs {
+		return toString(left) + toString(right)
+	}
+	return toNumber(left) + toNumber(right)
+}
+
+// looseEqual implements == for the values a PAC file works with
+func looseEqual(left, right any) bool {
+	if left == nil || right == nil {
+		return left == right
+	}
+	if _, ok := left.(string); ok {
+		if _, ok := right.(string); ok {
+			return left == right
+		}
+	}
+	return toNumber(left) == toNumber(right)
+}
+
+// Directive is one entry of a FindProxyForURL result
+type Directive struct {
+	Type string // DIRECT, PROXY, HTTP, HTTPS, SOCKS, SOCKS4 or SOCKS5
+	Host string // host:port of the proxy, empty for DIRECT
+}
+
+// ParseResult splits a FindProxyForURL result such as
+// "PROXY a.corp:8080; PROXY b.corp:8080; DIRECT" into its directives
+func ParseResult(result string) []Directive {
+	var directives []Directive
+	for _, part := range strings.Split(result, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		d := Directive{Type: strings.ToUpper(fields[0])}
+		if len(fields) > 1 {
+			d.Host = fields[1]
+		}
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+// ProxyURL returns the URL package managers use for the directive's proxy,
+// or "" for DIRECT and for SOCKS proxies, which they cannot use
+func (d Directive) ProxyURL() string {
+	switch d.Type {
+	case "PROXY", "HTTP":
+		return "http://" + d.Host
+	case "HTTPS":
+		return "https://" + d.Host
+	}
+	return ""
+}
+
+// lookupHost resolves a host to its first IPv4 address, or its first address
+func lookupHost(host string) string {
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) == 0 {
+		return ""
+	}
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return ip.String()
+		}
+	}
+	return ips[0].String()
+}
+
+// myIPAddress returns the local address used for outgoing connections. No
+// packets are sent: connecting a UDP socket only picks a route.
+func myIPAddress() string {
+	conn, err := net.Dial("udp", "192.0.2.1:80")
+	if err != nil {
+		return "127.0.0.1"
+	}
+	defer conn.Close()
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		return addr.IP.String()
+	}
+	return "127.0.0.1"
+}
@@ -0,0 +1,101 @@
+package pac
+
+import (
+	"strings"
+	"testing"
+)
+
+const corpPAC = `// Corporate proxy configuration
+function FindProxyForURL(url, host) {
+	host = host.toLowerCase();
+	var proxy = "PROXY proxy.corp.local:8080";
+
+	if (isPlainHostName(host) || dnsDomainIs(host, ".corp.local")) {
+		return "DIRECT";
+	}
+	if (isInNet(dnsResolve(host), "10.0.0.0", "255.0.0.0"))
+		return "DIRECT";
+	if (shExpMatch(url, "http://*") && url.indexOf("mirror") >= 0) {
+		return "HTTPS secure.corp.local:443";
+	}
+	return isSlow(host) ? "SOCKS socks.corp.local:1080; " + proxy : proxy + "; DIRECT";
+}
+
+/* Hosts that go through the SOCKS gateway first */
+function isSlow(host) {
+	return localHostOrDomainIs(host, "registry.yarnpkg.com");
+}
+`
+
+func TestFindProxyForURL(t *testing.T) {
+	script, err := Parse(corpPAC)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	script.LookupHost = func(host string) string {
+		if host == "build.internal" {
+			return "10.1.2.3"
+		}
+		return "203.0.113.7"
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://registry.npmjs.org/", "PROXY proxy.corp.local:8080; DIRECT"},
+		{"https://npm.CORP.local/", "DIRECT"},
+		{"http://localhost:4873/", "DIRECT"},
+		{"https://build.internal/npm/", "DIRECT"},
+		{"http://npm-mirror.example.com/", "HTTPS secure.corp.local:443"},
+		{"https://registry.yarnpkg.com/", "SOCKS socks.corp.local:1080; PROXY proxy.corp.local:8080"},
+	}
+	for _, tt := range tests {
+		got, err := script.FindProxyForURL(tt.url)
+		if err != nil {
+			t.Errorf("FindProxyForURL(%q) error = %v", tt.url, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("FindProxyForURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing function": `function Other(url, host) { return "DIRECT"; }`,
+		"loop":             `function FindProxyForURL(url, host) { for (;;) {} }`,
+		"unterminated":     `function FindProxyForURL(url, host) { return "DIRECT; }`,
+		"unbalanced":       `function FindProxyForURL(url, host) { if (host == "a" { return "DIRECT"; } }`,
+	}
+	for name, src := range tests {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("%s: Parse() succeeded, want an error", name)
+		}
+	}
+
+	script, err := Parse(`function FindProxyForURL(url, host) { return timeRange(8, 18) ? "DIRECT" : "PROXY p:1"; }`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if _, err := script.FindProxyForURL("https://registry.npmjs.org/"); err == nil || !strings.Contains(err.Error(), "timeRange") {
+		t.Errorf("FindProxyForURL() error = %v, want timeRange to be unsupported", err)
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	directives := ParseResult("PROXY a.corp:8080;  https b.corp:443 ; SOCKS5 c.corp:1080; DIRECT")
+	want := []string{"http://a.corp:8080", "https://b.corp:443", "", ""}
+	if len(directives) != len(want) {
+		t.Fatalf("ParseResult() = %v", directives)
+	}
+	for i, d := range directives {
+		if got := d.ProxyURL(); got != want[i] {
+			t.Errorf("directive %d (%s) ProxyURL() = %q, want %q", i, d.Type, got, want[i])
+		}
+	}
+	if directives[3].Type != "DIRECT" {
+		t.Errorf("last directive = %+v, want DIRECT", directives[3])
+	}
+}
@@ -0,0 +1,571 @@
+package pac
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string // identifier, punctuator or decoded string literal
+	num  float64
+	line int
+}
+
+// punctuators are matched longest first
+var punctuators = []string{
+	"===", "!==",
+	"==", "!=", "<=", ">=", "&&", "||", "+=",
+	"{", "}", "(", ")", "[", "]", ";", ",", ".", "!", "?", ":", "+", "-", "*", "/", "%", "<", ">", "=",
+}
+
+// unsupported are the JavaScript keywords PAC files may use that this
+// evaluator does not implement
+var unsupported = map[string]bool{
+	"for": true, "while": true, "do": true, "switch": true, "try": true,
+	"throw": true, "new": true, "break": true, "continue": true,
+}
+
+func tokenize(src string) ([]token, error) {
+	src = strings.TrimPrefix(src, "\ufeff")
+
+	var tokens []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"' || c == '\'':
+			s, n, err := readString(src[i:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			tokens = append(tokens, token{kind: tokString, text: s, line: line})
+			i += n
+		case isDigit(c):
+			j := i
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			num, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid number %q", line, src[i:j])
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[i:j], num: num, line: line})
+			i = j
+		case isIdentStart(c):
+			j := i
+			for j < len(src) && (isIdentStart(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[i:j], line: line})
+			i = j
+		default:
+			punct := ""
+			for _, p := range punctuators {
+				if strings.HasPrefix(src[i:], p) {
+					punct = p
+					break
+				}
+			}
+			if punct == "" {
+				return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+			}
+			tokens = append(tokens, token{kind: tokPunct, text: punct, line: line})
+			i += len(punct)
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, line: line}), nil
+}
+
+// readString decodes the quoted string at the start of s and returns it with
+// the number of bytes it took
+func readString(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case quote:
+			return b.String(), i + 1, nil
+		case '\n':
+			return "", 0, fmt.Errorf("unterminated string")
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Statements
+type (
+	varStmt struct {
+		names  []string
+		values []expr // nil when a name is declared without a value
+	}
+	ifStmt struct {
+		cond      expr
+		then, els stmt
+	}
+	blockStmt struct {
+		body []stmt
+	}
+	returnStmt struct {
+		value expr // nil for a bare return
+	}
+	exprStmt struct {
+		x expr
+	}
+)
+
+type stmt interface{}
+
+// Expressions
+type (
+	literal struct {
+		value any
+	}
+	ident struct {
+		name string
+		line int
+	}
+	callExpr struct {
+		callee expr
+		args   []expr
+		line   int
+	}
+	memberExpr struct {
+		object expr
+		name   string
+	}
+	unaryExpr struct {
+		op string
+		x  expr
+	}
+	binaryExpr struct {
+		op          string
+		left, right expr
+	}
+	condExpr struct {
+		cond, then, els expr
+	}
+	assignExpr struct {
+		name  string
+		op    string
+		value expr
+	}
+)
+
+type expr interface{}
+
+// function is a function declared in the script
+type function struct {
+	name   string
+	params []string
+	body   []stmt
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	funcs  map[string]*function
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is reports whether the next token is the given punctuator or keyword
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return (t.kind == tokPunct || t.kind == tokIdent) && t.text == text
+}
+
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf("expected %q", text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	t := p.peek()
+	found := t.text
+	if t.kind == tokEOF {
+		found = "end of file"
+	}
+	return fmt.Errorf("line %d: %s, found %q", t.line, fmt.Sprintf(format, args...), found)
+}
+
+func (p *parser) identifier() (string, error) {
+	t := p.peek()
+	if t.kind != tokIdent {
+		return "", p.errorf("expected a name")
+	}
+	p.next()
+	return t.text, nil
+}
+
+func (p *parser) statement() (stmt, error) {
+	t := p.peek()
+	if t.kind == tokIdent && unsupported[t.text] {
+		return nil, fmt.Errorf("line %d: %q statements are not supported", t.line, t.text)
+	}
+
+	switch {
+	case p.accept(";"):
+		return blockStmt{}, nil
+	case p.is("{"):
+		body, err := p.block()
+		return blockStmt{body: body}, err
+	case p.accept("function"):
+		return blockStmt{}, p.function()
+	case p.accept("var"), p.accept("let"), p.accept("const"):
+		return p.varStatement()
+	case p.accept("if"):
+		return p.ifStatement()
+	case p.accept("return"):
+		if p.accept(";") || p.is("}") {
+			return returnStmt{}, nil
+		}
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		p.accept(";")
+		return returnStmt{value: value}, nil
+	}
+
+	x, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	p.accept(";")
+	return exprStmt{x: x}, nil
+}
+
+func (p *parser) block() ([]stmt, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var body []stmt
+	for !p.accept("}") {
+		if p.peek().kind == tokEOF {
+			return nil, p.errorf("expected %q", "}")
+		}
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, s)
+	}
+	return body, nil
+}
+
+// function parses a function declaration and registers it with the script
+func (p *parser) function() error {
+	name, err := p.identifier()
+	if err != nil {
+		return err
+	}
+	if err := p.expect("("); err != nil {
+		return err
+	}
+
+	fn := &function{name: name}
+	for !p.accept(")") {
+		if len(fn.params) > 0 {
+			if err := p.expect(","); err != nil {
+				return err
+			}
+		}
+		param, err := p.identifier()
+		if err != nil {
+			return err
+		}
+		fn.params = append(fn.params, param)
+	}
+
+	if fn.body, err = p.block(); err != nil {
+		return err
+	}
+	p.funcs[name] = fn
+	return nil
+}
+
+func (p *parser) varStatement() (stmt, error) {
+	var s varStmt
+	for {
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		var value expr
+		if p.accept("=") {
+			if value, err = p.assignment(); err != nil {
+				return nil, err
+			}
+		}
+		s.names = append(s.names, name)
+		s.values = append(s.values, value)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.accept(";")
+	return s, nil
+}
+
+func (p *parser) ifStatement() (stmt, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	cond, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	s := ifStmt{cond: cond}
+	if s.then, err = p.statement(); err != nil {
+		return nil, err
+	}
+	if p.accept("else") {
+		if s.els, err = p.statement(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) expression() (expr, error) {
+	return p.assignment()
+}
+
+func (p *parser) assignment() (expr, error) {
+	if t := p.peek(); t.kind == tokIdent {
+		if op := p.tokens[p.pos+1]; op.kind == tokPunct && (op.text == "=" || op.text == "+=") {
+			p.pos += 2
+			value, err := p.assignment()
+			if err != nil {
+				return nil, err
+			}
+			return assignExpr{name: t.text, op: op.text, value: value}, nil
+		}
+	}
+	return p.conditional()
+}
+
+func (p *parser) conditional() (expr, error) {
+	cond, err := p.binary(0)
+	if err != nil || !p.accept("?") {
+		return cond, err
+	}
+	then, err := p.assignment()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	els, err := p.assignment()
+	if err != nil {
+		return nil, err
+	}
+	return condExpr{cond: cond, then: then, els: els}, nil
+}
+
+// binaryLevels lists the binary operators from the lowest precedence up
+var binaryLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "===", "!=="},
+	{"<", ">", "<=", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) binary(level int) (expr, error) {
+	if level == len(binaryLevels) {
+		return p.unary()
+	}
+
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokPunct || !contains(binaryLevels[level], t.text) {
+			return left, nil
+		}
+		p.next()
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) unary() (expr, error) {
+	for _, op := range []string{"!", "-", "+"} {
+		if p.accept(op) {
+			x, err := p.unary()
+			if err != nil {
+				return nil, err
+			}
+			return unaryExpr{op: op, x: x}, nil
+		}
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (expr, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.accept("."):
+			name, err := p.identifier()
+			if err != nil {
+				return nil, err
+			}
+			x = memberExpr{object: x, name: name}
+		case p.is("("):
+			line := p.next().line
+			var args []expr
+			for !p.accept(")") {
+				if len(args) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				arg, err := p.assignment()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+			}
+			x = callExpr{callee: x, args: args, line: line}
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) primary() (expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokString:
+		p.next()
+		return literal{value: t.text}, nil
+	case tokNumber:
+		p.next()
+		return literal{value: t.num}, nil
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			p.next()
+			return literal{value: t.text == "true"}, nil
+		case "null", "undefined":
+			p.next()
+			return literal{}, nil
+		case "function", "var", "let", "const", "if", "else", "return":
+			return nil, p.errorf("unexpected keyword")
+		}
+		if unsupported[t.text] {
+			return nil, fmt.Errorf("line %d: %q is not supported", t.line, t.text)
+		}
+		p.next()
+		return ident{name: t.text, line: t.line}, nil
+	}
+
+	if p.accept("(") {
+		x, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	}
+	return nil, p.errorf("expected an expression")
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"npm-console/internal/core"
+)
+
+// PresetMirrors are well-known public npm registries and mirrors
+var PresetMirrors = []core.RegistryMirror{
+	{Name: "npmjs", URL: "https://registry.npmjs.org/"},
+	{Name: "yarn", URL: "https://registry.yarnpkg.com/"},
+	{Name: "npmmirror", URL: "https://registry.npmmirror.com/"},
+	{Name: "tencent", URL: "https://mirrors.cloud.tencent.com/npm/"},
+	{Name: "huawei", URL: "https://repo.huaweicloud.com/repository/npm/"},
+}
+
+// Benchmark probes every mirror concurrently, rounds times each, and returns
+// the results ranked by success rate and then by average latency. opts carries
+// the proxy to use; its Registry is replaced by each mirror, which only gets
+// the credentials configured for its own host.
+func Benchmark(ctx context.Context, mirrors []core.RegistryMirror, rounds int, opts Options) []core.RegistryBenchResult {
+	if rounds < 1 {
+		rounds = 1
+	}
+
+	results := make([]core.RegistryBenchResult, len(mirrors))
+	var wg sync.WaitGroup
+
+	for i, mirror := range mirrors {
+		wg.Add(1)
+		go func(i int, mirror core.RegistryMirror) {
+			defer wg.Done()
+
+			mirrorOpts := opts.WithRegistry(mirror.URL)
+			mirrorOpts.CacheDir = ""
+			client := NewClientWithOptions(mirrorOpts)
+
+			result := core.RegistryBenchResult{Name: mirror.Name, URL: client.BaseURL()}
+			var total int64
+			for round := 0; round < rounds; round++ {
+				if ctx.Err() != nil {
+					break
+				}
+
+				probe := client.Probe(ctx, ProbePackage)
+				result.Attempts++
+				if !probe.Success {
+					result.Error = probe.Error
+					continue
+				}
+
+				result.Successes++
+				total += probe.LatencyMs
+				if result.Successes == 1 || probe.LatencyMs < result.MinLatencyMs {
+					result.MinLatencyMs = probe.LatencyMs
+				}
+				if probe.LatencyMs > result.MaxLatencyMs {
+					result.MaxLatencyMs = probe.LatencyMs
+				}
+			}
+
+			if result.Attempts > 0 {
+				result.SuccessRate = float64(result.Successes) / float64(result.Attempts)
+			}
+			if result.Successes > 0 {
+				result.AvgLatencyMs = total / int64(result.Successes)
+			}
+			results[i] = result
+		}(i, mirror)
+	}
+
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].SuccessRate != results[j].SuccessRate {
+			return results[i].SuccessRate > results[j].SuccessRate
+		}
+		return results[i].AvgLatencyMs < results[j].AvgLatencyMs
+	})
+
+	return results
+}
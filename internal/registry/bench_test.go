@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"npm-console/internal/core"
+)
+
+func TestBenchmark(t *testing.T) {
+	stub := func(delay time.Duration, status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			w.WriteHeader(status)
+			w.Write([]byte(`{"name": "npm", "versions": {}}`))
+		}))
+	}
+
+	slow := stub(50*time.Millisecond, http.StatusOK)
+	defer slow.Close()
+	fast := stub(0, http.StatusOK)
+	defer fast.Close()
+	broken := stub(0, http.StatusInternalServerError)
+	defer broken.Close()
+
+	results := Benchmark(context.Background(), []core.RegistryMirror{
+		{Name: "broken", URL: broken.URL},
+		{Name: "slow", URL: slow.URL},
+		{Name: "fast", URL: fast.URL},
+	}, 2, Options{})
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	order := []string{results[0].Name, results[1].Name, results[2].Name}
+	if order[0] != "fast" || order[1] != "slow" || order[2] != "broken" {
+		t.Errorf("Unexpected ranking: %v", order)
+	}
+	if results[0].Attempts != 2 || results[0].SuccessRate != 1 {
+		t.Errorf("Unexpected fast result: %+v", results[0])
+	}
+	if results[2].Successes != 0 || results[2].Error == "" {
+		t.Errorf("Unexpected broken result: %+v", results[2])
+	}
+}
+
+func TestBenchmarkCredentials(t *testing.T) {
+	var leaked []string
+	var mu sync.Mutex
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			mu.Lock()
+			leaked = append(leaked, auth)
+			mu.Unlock()
+		}
+		w.Write([]byte(`{"name": "npm", "versions": {}}`))
+	}))
+	defer mirror.Close()
+
+	results := Benchmark(context.Background(), []core.RegistryMirror{{Name: "mirror", URL: mirror.URL}}, 2, Options{
+		Registry: "https://npm.corp.local/",
+		Tokens:   map[string]string{"//npm.corp.local/": "secret", "": "legacy"},
+	})
+
+	if len(results) != 1 || results[0].Successes != 2 {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if len(leaked) > 0 {
+		t.Errorf("mirror received credentials: %v", leaked)
+	}
+}
+
+func TestBenchmarkMinLatency(t *testing.T) {
+	var requests int
+	var mu sync.Mutex
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if r.URL.Path != "/-/ping" {
+			requests++
+		}
+		slow := requests > 1
+		mu.Unlock()
+		// Only the first packument fetch is instant
+		if slow {
+			time.Sleep(30 * time.Millisecond)
+		}
+		w.Write([]byte(`{"name": "npm", "versions": {}}`))
+	}))
+	defer mirror.Close()
+
+	results := Benchmark(context.Background(), []core.RegistryMirror{{Name: "mirror", URL: mirror.URL}}, 2, Options{})
+
+	if len(results) != 1 || results[0].Successes != 2 {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if results[0].MinLatencyMs >= results[0].MaxLatencyMs {
+		t.Errorf("MinLatencyMs = %d, want the instant probe below MaxLatencyMs %d", results[0].MinLatencyMs, results[0].MaxLatencyMs)
+	}
+}
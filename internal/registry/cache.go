@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"npm-console/pkg/logger"
+	"npm-console/pkg/utils"
+)
+
+// cacheEntry is a registry document stored with the ETag it was served with
+type cacheEntry struct {
+	ETag string `json:"etag"`
+	Body []byte `json:"body"`
+}
+
+// etagCache keeps registry documents in memory and, when a directory is
+// configured, on disk so they can be revalidated across runs. Documents may
+// have been fetched with the user's credentials, so only the user can read
+// them.
+type etagCache struct {
+	dir     string
+	entries map[string]*cacheEntry
+	mu      sync.Mutex
+	logger  *logger.Logger
+}
+
+// newETagCache creates a cache persisted in dir (memory only if dir is empty)
+func newETagCache(dir string, log *logger.Logger) *etagCache {
+	return &etagCache{
+		dir:     dir,
+		entries: make(map[string]*cacheEntry),
+		logger:  log,
+	}
+}
+
+// get returns the cached entry for key, or nil
+func (c *etagCache) get(key string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[key]; ok {
+		return entry
+	}
+	if c.dir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	c.entries[key] = &entry
+
+	return &entry
+}
+
+// put stores an entry; failures to persist it are only logged since the cache is optional
+func (c *etagCache) put(key string, entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = entry
+	if c.dir == "" {
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		c.logger.WithError(err).WithField("dir", c.dir).Warn("Failed to create registry cache directory")
+		return
+	}
+	// Tighten a directory created by an older version
+	if err := os.Chmod(c.dir, 0700); err != nil {
+		c.logger.WithError(err).WithField("dir", c.dir).Warn("Failed to restrict registry cache directory")
+	}
+	if err := utils.WriteFileAtomic(c.path(key), data, 0600); err != nil {
+		c.logger.WithError(err).WithField("dir", c.dir).Warn("Failed to persist registry document")
+	}
+}
+
+// path returns the file an entry is persisted in
+func (c *etagCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,37 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"npm-console/pkg/logger"
+)
+
+func TestETagCachePermissions(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "registry")
+	os.Mkdir(dir, 0755)
+
+	cache := newETagCache(dir, logger.GetDefault())
+	cache.put("https://npm.corp.local/@corp%2flib", &cacheEntry{ETag: `"v1"`, Body: []byte(`{}`)})
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("cache directory mode = %o, want 700", perm)
+	}
+	info, err = os.Stat(cache.path("https://npm.corp.local/@corp%2flib"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("cached document mode = %o, want 600", perm)
+	}
+
+	reloaded := newETagCache(dir, logger.GetDefault())
+	if entry := reloaded.get("https://npm.corp.local/@corp%2flib"); entry == nil || entry.ETag != `"v1"` {
+		t.Errorf("get() = %+v, want the persisted entry", entry)
+	}
+}
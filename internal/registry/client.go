@@ -0,0 +1,306 @@
+// Package registry implements a small client for the npm registry HTTP API
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"npm-console/internal/core"
+	"npm-console/pkg/logger"
+)
+
+// DefaultRegistry is the public npm registry
+const DefaultRegistry = "https://registry.npmjs.org/"
+
+// Client talks to an npm compatible registry
+type Client struct {
+	baseURL    string
+	scopes     map[string]string
+	tokens     map[string]string
+	httpClient *http.Client
+	cache      *etagCache
+	logger     *logger.Logger
+}
+
+// Options configures a registry client
+type Options struct {
+	Registry   string            // default registry URL (DefaultRegistry if empty)
+	Scopes     map[string]string // "@scope" -> registry URL
+	Tokens     map[string]string // "//host/path/" -> bearer token
+	Proxy      string            // proxy for http://, and https:// when HTTPSProxy is empty; the environment's proxy is used if both are empty
+	HTTPSProxy string            // proxy for https:// requests
+	NoProxy    []string          // hosts and domains that bypass Proxy and HTTPSProxy
+	CacheDir   string            // directory for ETag cached documents; memory only if empty
+	Timeout    time.Duration
+}
+
+// Packument is the registry document describing every version of a package
+type Packument struct {
+	Name        string                       `json:"name"`
+	Description string                       `json:"description,omitempty"`
+	DistTags    map[string]string            `json:"dist-tags"`
+	Versions    map[string]*PackumentVersion `json:"versions"`
+	Time        map[string]string            `json:"time,omitempty"`
+	Modified    string                       `json:"modified,omitempty"`
+	License     json.RawMessage              `json:"license,omitempty"`
+	Homepage    string                       `json:"homepage,omitempty"`
+	Repository  json.RawMessage              `json:"repository,omitempty"`
+	Keywords    []string                     `json:"keywords,omitempty"`
+	Author      json.RawMessage              `json:"author,omitempty"`
+}
+
+// PackumentVersion is the manifest of a single published version
+type PackumentVersion struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Description          string            `json:"description,omitempty"`
+	Deprecated           string            `json:"deprecated,omitempty"`
+	Dependencies         map[string]string `json:"dependencies,omitempty"`
+	DevDependencies      map[string]string `json:"devDependencies,omitempty"`
+	OptionalDependencies map[string]string `json:"optionalDependencies,omitempty"`
+	PeerDependencies     map[string]string `json:"peerDependencies,omitempty"`
+	Engines              json.RawMessage   `json:"engines,omitempty"`
+	License              json.RawMessage   `json:"license,omitempty"`
+	Homepage             string            `json:"homepage,omitempty"`
+	Repository           json.RawMessage   `json:"repository,omitempty"`
+	Keywords             json.RawMessage   `json:"keywords,omitempty"`
+	Author               json.RawMessage   `json:"author,omitempty"`
+	Scripts              map[string]string `json:"scripts,omitempty"`
+	Dist                 Dist              `json:"dist"`
+}
+
+// Dist holds the tarball location and checksums of a version
+type Dist struct {
+	Tarball      string `json:"tarball"`
+	Shasum       string `json:"shasum,omitempty"`
+	Integrity    string `json:"integrity,omitempty"`
+	FileCount    int    `json:"fileCount,omitempty"`
+	UnpackedSize int64  `json:"unpackedSize,omitempty"`
+}
+
+// Accept headers for the two packument formats
+const (
+	acceptAbbreviated = "application/vnd.npm.install-v1+json; q=1.0, application/json; q=0.8"
+	acceptFull        = "application/json"
+)
+
+// NewClient creates a client for the registry at baseURL (DefaultRegistry if empty)
+func NewClient(baseURL string) *Client {
+	return NewClientWithOptions(Options{Registry: baseURL})
+}
+
+// NewClientWithOptions creates a client with scoped registries, credentials and a proxy
+func NewClientWithOptions(opts Options) *Client {
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.Proxy != "" || opts.HTTPSProxy != "" {
+		transport.Proxy = proxyFunc(opts.Proxy, opts.HTTPSProxy, opts.NoProxy)
+	}
+
+	scopes := make(map[string]string, len(opts.Scopes))
+	for scope, registry := range opts.Scopes {
+		scopes[scope] = normalizeURL(registry)
+	}
+
+	log := logger.GetDefault().WithField("component", "registry")
+	return &Client{
+		baseURL: normalizeURL(opts.Registry),
+		scopes:  scopes,
+		tokens:  opts.Tokens,
+		httpClient: &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: transport,
+		},
+		cache:  newETagCache(opts.CacheDir, log),
+		logger: log,
+	}
+}
+
+// normalizeURL defaults an empty registry and makes sure it ends with a slash
+func normalizeURL(registry string) string {
+	if registry == "" {
+		registry = DefaultRegistry
+	}
+	if !strings.HasSuffix(registry, "/") {
+		registry += "/"
+	}
+	return registry
+}
+
+// BaseURL returns the registry URL the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// RegistryFor returns the registry serving a package, honouring scoped registries
+func (c *Client) RegistryFor(name string) string {
+	if strings.HasPrefix(name, "@") {
+		if idx := strings.Index(name, "/"); idx > 0 {
+			if registry, ok := c.scopes[name[:idx]]; ok {
+				return registry
+			}
+		}
+	}
+	return c.baseURL
+}
+
+// GetPackument fetches the abbreviated packument of a package
+func (c *Client) GetPackument(ctx context.Context, name string) (*Packument, error) {
+	return c.getPackument(ctx, name, acceptAbbreviated)
+}
+
+// GetFullPackument fetches the complete packument of a package, including
+// licenses, repositories, keywords and the other fields left out of the
+// abbreviated document
+func (c *Client) GetFullPackument(ctx context.Context, name string) (*Packument, error) {
+	return c.getPackument(ctx, name, acceptFull)
+}
+
+// getPackument fetches and decodes a packument in the requested format
+func (c *Client) getPackument(ctx context.Context, name, accept string) (*Packument, error) {
+	if name == "" {
+		return nil, core.NewValidationError("name", name, "package name cannot be empty")
+	}
+
+	data, err := c.get(ctx, c.RegistryFor(name)+EscapeName(name), accept)
+	if err != nil {
+		if errors.Is(err, core.ErrPackageNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to fetch %s: %w", name, err)
+	}
+
+	var packument Packument
+	if err := json.Unmarshal(data, &packument); err != nil {
+		return nil, fmt.Errorf("failed to decode packument for %s: %w", name, err)
+	}
+
+	c.logger.Debug("Fetched packument", "package", name, "versions", len(packument.Versions))
+
+	return &packument, nil
+}
+
+// get performs an authenticated GET, revalidating cached documents with their ETag
+func (c *Client) get(ctx context.Context, rawURL, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	if token := c.tokenFor(rawURL); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	cacheKey := accept + " " + rawURL
+	cached := c.cache.get(cacheKey)
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		c.logger.Debug("Registry document not modified", "url", rawURL)
+		return cached.Body, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, core.ErrPackageNotFound
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("registry returned %s: %w", resp.Status, core.ErrPermissionDenied)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("registry returned %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		c.cache.put(cacheKey, &cacheEntry{ETag: etag, Body: body})
+	}
+
+	return body, nil
+}
+
+// tokenFor returns the auth token for a URL. Credentials are matched on the
+// longest "//host/path/" prefix, like npm does, so a token is only ever sent
+// to the host it was configured for.
+func (c *Client) tokenFor(rawURL string) string {
+	nerfed := nerf(rawURL)
+
+	best, token := -1, ""
+	for prefix, value := range c.tokens {
+		if prefix != "" && strings.HasPrefix(nerfed, prefix) && len(prefix) > best {
+			best, token = len(prefix), value
+		}
+	}
+
+	return token
+}
+
+// nerf strips the scheme from a URL, leaving the "//host/path" form
+// credentials are keyed by
+func nerf(rawURL string) string {
+	if idx := strings.Index(rawURL, "://"); idx >= 0 {
+		return rawURL[idx+1:]
+	}
+	return rawURL
+}
+
+// WithRegistry returns a copy of the options that talks to registryURL
+// instead, keeping only the credentials configured for its host
+func (o Options) WithRegistry(registryURL string) Options {
+	host := nerf(normalizeURL(registryURL))
+	if !strings.HasPrefix(host, "//") {
+		host = "//" + host
+	}
+	if idx := strings.Index(host[2:], "/"); idx >= 0 {
+		host = host[:idx+3]
+	}
+
+	tokens := make(map[string]string)
+	for prefix, token := range o.Tokens {
+		if strings.HasPrefix(prefix, host) {
+			tokens[prefix] = token
+		}
+	}
+
+	o.Registry = registryURL
+	o.Tokens = tokens
+	return o
+}
+
+// VersionList returns the published version numbers of a packument
+func (p *Packument) VersionList() []string {
+	versions := make([]string, 0, len(p.Versions))
+	for version := range p.Versions {
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// EscapeName escapes a package name for use in a registry URL path.
+// Scoped names keep their "@" but the slash is encoded ("@scope%2fname").
+func EscapeName(name string) string {
+	if strings.HasPrefix(name, "@") {
+		if idx := strings.Index(name, "/"); idx > 0 {
+			return name[:idx] + "%2f" + url.PathEscape(name[idx+1:])
+		}
+	}
+	return url.PathEscape(name)
+}
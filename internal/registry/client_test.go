@@ -0,0 +1,149 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"npm-console/internal/core"
+)
+
+func TestEscapeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"lodash", "lodash"},
+		{"@babel/core", "@babel%2fcore"},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeName(tt.name); got != tt.expected {
+			t.Errorf("EscapeName(%q) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestGetPackument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/@scope%2fpkg":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{
+				"name": "@scope/pkg",
+				"dist-tags": {"latest": "1.1.0", "next": "2.0.0-rc.1"},
+				"versions": {
+					"1.0.0": {"name": "@scope/pkg", "version": "1.0.0", "dist": {"tarball": "http://x/pkg-1.0.0.tgz"}},
+					"1.1.0": {"name": "@scope/pkg", "version": "1.1.0", "dist": {"tarball": "http://x/pkg-1.1.0.tgz"}},
+					"2.0.0-rc.1": {"name": "@scope/pkg", "version": "2.0.0-rc.1", "dist": {"tarball": "http://x/pkg-2.0.0-rc.1.tgz"}}
+				}
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	packument, err := client.GetPackument(context.Background(), "@scope/pkg")
+	if err != nil {
+		t.Fatalf("GetPackument() error = %v", err)
+	}
+	if packument.DistTags["latest"] != "1.1.0" {
+		t.Errorf("latest = %s, want 1.1.0", packument.DistTags["latest"])
+	}
+	if len(packument.VersionList()) != 3 {
+		t.Errorf("Expected 3 versions, got %d", len(packument.VersionList()))
+	}
+
+	if _, err := client.GetPackument(context.Background(), "missing"); !errors.Is(err, core.ErrPackageNotFound) {
+		t.Errorf("Expected ErrPackageNotFound, got %v", err)
+	}
+}
+
+func TestScopedRegistryAuthAndETag(t *testing.T) {
+	requests := 0
+	scoped := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{
+			"name": "@corp/lib",
+			"dist-tags": {"latest": "1.0.0"},
+			"license": {"type": "MIT"},
+			"repository": {"type": "git", "url": "git+https://git.corp/lib.git"},
+			"versions": {"1.0.0": {"name": "@corp/lib", "version": "1.0.0", "engines": {"node": ">=18"}}}
+		}`))
+	}))
+	defer scoped.Close()
+
+	client := NewClientWithOptions(Options{
+		Registry: "http://127.0.0.1:1/",
+		Scopes:   map[string]string{"@corp": scoped.URL},
+		Tokens:   map[string]string{"//" + scoped.Listener.Addr().String() + "/": "secret"},
+	})
+
+	if got := client.RegistryFor("@corp/lib"); got != scoped.URL+"/" {
+		t.Errorf("RegistryFor() = %s, want %s/", got, scoped.URL)
+	}
+
+	for i := 0; i < 2; i++ {
+		packument, err := client.GetFullPackument(context.Background(), "@corp/lib")
+		if err != nil {
+			t.Fatalf("GetFullPackument() error = %v", err)
+		}
+		if LicenseName(packument.License) != "MIT" {
+			t.Errorf("license = %s, want MIT", LicenseName(packument.License))
+		}
+		if RepositoryURL(packument.Repository) != "git+https://git.corp/lib.git" {
+			t.Errorf("repository = %s", RepositoryURL(packument.Repository))
+		}
+		if Engines(packument.Manifest("").Engines)["node"] != ">=18" {
+			t.Errorf("engines not decoded")
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/-/v1/search" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		if query.Get("text") != "react" || query.Get("size") != "2" || query.Get("from") != "2" || query.Get("popularity") != "1" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{
+			"objects": [
+				{"package": {"name": "react", "version": "18.3.1", "links": {"homepage": "https://react.dev"}}, "score": {"final": 0.9, "detail": {"quality": 0.8, "popularity": 1, "maintenance": 0.7}}}
+			],
+			"total": 3
+		}`))
+	}))
+	defer server.Close()
+
+	response, err := NewClient(server.URL).Search(context.Background(), "react", SearchOptions{Size: 2, From: 2, Popularity: 1})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if response.Total != 3 || len(response.Objects) != 1 {
+		t.Fatalf("Unexpected response: %+v", response)
+	}
+	if response.Objects[0].Score.Detail.Popularity != 1 {
+		t.Errorf("popularity = %v, want 1", response.Objects[0].Score.Detail.Popularity)
+	}
+}
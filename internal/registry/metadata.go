@@ -0,0 +1,114 @@
+package registry
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Manifest returns the manifest of a version, falling back to the latest
+// dist-tag when version is empty. It returns nil if the version is unknown.
+func (p *Packument) Manifest(version string) *PackumentVersion {
+	if version == "" {
+		version = p.DistTags["latest"]
+	}
+	if tagged, ok := p.DistTags[version]; ok {
+		version = tagged
+	}
+	return p.Versions[version]
+}
+
+// LicenseName returns the SPDX expression of a license field, which older
+// packages publish as {"type": "MIT"} or a list of such objects
+func LicenseName(raw json.RawMessage) string {
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		return name
+	}
+
+	var object struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(raw, &object) == nil && object.Type != "" {
+		return object.Type
+	}
+
+	var list []struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(raw, &list) == nil {
+		var names []string
+		for _, item := range list {
+			if item.Type != "" {
+				names = append(names, item.Type)
+			}
+		}
+		if len(names) > 1 {
+			return "(" + strings.Join(names, " OR ") + ")"
+		}
+		return strings.Join(names, "")
+	}
+
+	return ""
+}
+
+// RepositoryURL returns the URL of a repository field, either a string or {"url": ...}
+func RepositoryURL(raw json.RawMessage) string {
+	var repository string
+	if json.Unmarshal(raw, &repository) == nil {
+		return repository
+	}
+
+	var object struct {
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(raw, &object) == nil {
+		return object.URL
+	}
+
+	return ""
+}
+
+// PersonName formats an author field, either a string or {"name", "email"}
+func PersonName(raw json.RawMessage) string {
+	var person string
+	if json.Unmarshal(raw, &person) == nil {
+		return person
+	}
+
+	var object struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(raw, &object) == nil && object.Name != "" {
+		if object.Email != "" {
+			return object.Name + " <" + object.Email + ">"
+		}
+		return object.Name
+	}
+
+	return ""
+}
+
+// Keywords returns a keywords field, which some packages publish as a single string
+func Keywords(raw json.RawMessage) []string {
+	var keywords []string
+	if json.Unmarshal(raw, &keywords) == nil {
+		return keywords
+	}
+
+	var keyword string
+	if json.Unmarshal(raw, &keyword) == nil && keyword != "" {
+		return strings.FieldsFunc(keyword, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+
+	return nil
+}
+
+// Engines returns an engines field; the legacy array form is ignored
+func Engines(raw json.RawMessage) map[string]string {
+	var engines map[string]string
+	if json.Unmarshal(raw, &engines) == nil {
+		return engines
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package registry
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"npm-console/internal/core"
+)
+
+// ProbePackage is the packument requested to check that a registry serves packages
+const ProbePackage = "npm"
+
+// Probe checks that the registry answers /-/ping and serves a known packument,
+// measuring latency, TLS parameters and HTTP status. Failures are reported in
+// the result rather than as an error.
+func (c *Client) Probe(ctx context.Context, packageName string) *core.RegistryTestResult {
+	if packageName == "" {
+		packageName = ProbePackage
+	}
+
+	result := &core.RegistryTestResult{
+		Registry: c.baseURL,
+	}
+
+	// Not every mirror implements /-/ping, so it only informs the result
+	if ping, err := c.probe(ctx, c.baseURL+"-/ping"); err == nil {
+		result.PingStatus = ping.StatusCode
+		result.PingLatencyMs = ping.LatencyMs
+	}
+
+	packument, err := c.probe(ctx, c.RegistryFor(packageName)+EscapeName(packageName))
+	if err != nil {
+		result.Error = err.Error()
+		// A proxy rejecting CONNECT surfaces as an error carrying the status text
+		result.AuthFailed = strings.Contains(err.Error(), http.StatusText(http.StatusProxyAuthRequired))
+		return result
+	}
+
+	result.StatusCode = packument.StatusCode
+	result.LatencyMs = packument.LatencyMs
+	result.TLS = packument.TLS
+
+	switch packument.StatusCode {
+	case http.StatusOK:
+		result.Success = true
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusProxyAuthRequired:
+		result.AuthFailed = true
+		result.Error = fmt.Sprintf("authentication failed: %s", http.StatusText(packument.StatusCode))
+	default:
+		result.Error = fmt.Sprintf("unexpected status fetching %s: %d %s", packageName, packument.StatusCode, http.StatusText(packument.StatusCode))
+	}
+
+	return result
+}
+
+// probeResponse holds the measurements of a single probe request
+type probeResponse struct {
+	StatusCode int
+	LatencyMs  int64
+	TLS        *core.TLSInfo
+}
+
+// probe performs an uncached GET and measures it
+func (c *Client) probe(ctx context.Context, rawURL string) (*probeResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", acceptAbbreviated)
+	if token := c.tokenFor(rawURL); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Include the body in the latency, as an installer would
+	io.Copy(io.Discard, resp.Body)
+
+	return &probeResponse{
+		StatusCode: resp.StatusCode,
+		LatencyMs:  time.Since(start).Milliseconds(),
+		TLS:        tlsInfo(resp.TLS),
+	}, nil
+}
+
+// tlsInfo summarises a TLS connection state
+func tlsInfo(state *tls.ConnectionState) *core.TLSInfo {
+	if state == nil {
+		return nil
+	}
+
+	info := &core.TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+	}
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		info.Subject = cert.Subject.CommonName
+		info.Issuer = cert.Issuer.CommonName
+		info.NotAfter = cert.NotAfter
+	}
+
+	return info
+}
@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/-/ping":
+			w.Write([]byte(`{}`))
+		case "/npm":
+			w.Write([]byte(`{"name": "npm", "versions": {}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(Options{Registry: server.URL})
+	client.httpClient = server.Client()
+
+	result := client.Probe(context.Background(), "")
+	if !result.Success {
+		t.Fatalf("Probe() failed: %s", result.Error)
+	}
+	if result.StatusCode != http.StatusOK || result.PingStatus != http.StatusOK {
+		t.Errorf("Unexpected statuses: %d, %d", result.StatusCode, result.PingStatus)
+	}
+	if result.TLS == nil || result.TLS.Version == "" {
+		t.Error("Expected TLS details")
+	}
+}
+
+func TestProbeAuthFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	result := NewClient(server.URL).Probe(context.Background(), "")
+	if result.Success || !result.AuthFailed {
+		t.Errorf("Expected an auth failure, got %+v", result)
+	}
+}
+
+func TestProbeThroughProxy(t *testing.T) {
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Requests for plain http targets reach the proxy with an absolute URL
+		proxied = r.URL.Host == "registry.invalid"
+		w.Write([]byte(`{"name": "npm", "versions": {}}`))
+	}))
+	defer proxy.Close()
+
+	result := NewClientWithOptions(Options{Registry: "http://registry.invalid/", Proxy: proxy.URL}).Probe(context.Background(), "")
+	if !result.Success {
+		t.Fatalf("Probe() failed: %s", result.Error)
+	}
+	if !proxied {
+		t.Error("Expected the request to go through the proxy")
+	}
+}
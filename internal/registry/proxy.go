@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// proxyFunc returns a transport proxy function that sends http:// requests
+// through httpProxy and https:// requests through httpsProxy, falling back
+// to httpProxy the way npm does. Hosts matching noProxy are reached directly.
+func proxyFunc(httpProxy, httpsProxy string, noProxy []string) func(*http.Request) (*url.URL, error) {
+	if httpsProxy == "" {
+		httpsProxy = httpProxy
+	}
+	proxies := map[string]*url.URL{
+		"http":  parseProxy(httpProxy),
+		"https": parseProxy(httpsProxy),
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		proxy := proxies[req.URL.Scheme]
+		host := req.URL.Host
+		if req.URL.Port() == "" {
+			host = net.JoinHostPort(req.URL.Hostname(), map[string]string{"http": "80", "https": "443"}[req.URL.Scheme])
+		}
+		if proxy == nil || BypassProxy(host, noProxy) {
+			return nil, nil
+		}
+		return proxy, nil
+	}
+}
+
+// parseProxy parses a proxy URL, returning nil for an empty or invalid one
+func parseProxy(rawURL string) *url.URL {
+	if rawURL == "" {
+		return nil
+	}
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil || proxyURL.Host == "" {
+		return nil
+	}
+	return proxyURL
+}
+
+// BypassProxy reports whether a host, with an optional port, matches a
+// NO_PROXY style list. Entries match the host itself and its subdomains, a
+// leading "." or "*." is ignored, an entry with a port only matches that
+// port, and "*" matches every host.
+func BypassProxy(host string, noProxy []string) bool {
+	hostname, port := host, ""
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		hostname, port = h, p
+	}
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "*" {
+			return true
+		}
+
+		entryPort := ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			entry, entryPort = h, p
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+
+		entry = strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		if entry == "" {
+			continue
+		}
+		if hostname == entry || strings.HasSuffix(hostname, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProxyFor returns the proxy the client options use for a URL, or "" when it
+// is reached directly. The environment's proxy is not considered.
+func (o Options) ProxyFor(rawURL string) string {
+	target, err := url.Parse(rawURL)
+	if err != nil || target.Host == "" {
+		return ""
+	}
+
+	proxy, _ := proxyFunc(o.Proxy, o.HTTPSProxy, o.NoProxy)(&http.Request{URL: target})
+	if proxy == nil {
+		return ""
+	}
+	return proxy.String()
+}
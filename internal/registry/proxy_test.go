@@ -0,0 +1,106 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBypassProxy(t *testing.T) {
+	noProxy := []string{"corp.local", ".internal.example", "*.svc", "mirror.example:8443", "10.0.0.5"}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"corp.local:443", true},
+		{"npm.corp.local:443", true},
+		{"CORP.LOCAL.:443", true},
+		{"notcorp.local:443", false},
+		{"internal.example:80", true},
+		{"registry.internal.example:443", true},
+		{"api.svc:80", true},
+		{"mirror.example:8443", true},
+		{"mirror.example:443", false},
+		{"10.0.0.5:4873", true},
+		{"10.0.0.50:4873", false},
+		{"registry.npmjs.org:443", false},
+	}
+	for _, tt := range tests {
+		if got := BypassProxy(tt.host, noProxy); got != tt.want {
+			t.Errorf("BypassProxy(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+
+	if !BypassProxy("registry.npmjs.org:443", []string{"*"}) {
+		t.Error(`"*" should bypass every host`)
+	}
+}
+
+// countingProxy returns a proxy server that counts the requests it receives
+// and answers them as a registry would
+func countingProxy(t *testing.T) (*httptest.Server, *int32) {
+	var hits int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.Method == http.MethodConnect {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"name": "npm", "versions": {}}`))
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy, &hits
+}
+
+func TestNoProxyGoesDirect(t *testing.T) {
+	proxy, hits := countingProxy(t)
+
+	var direct int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&direct, 1)
+		w.Write([]byte(`{"name": "npm", "versions": {}}`))
+	}))
+	defer server.Close()
+
+	// The registry is on 127.0.0.1, which the bypass list excludes
+	client := NewClientWithOptions(Options{Registry: server.URL, Proxy: proxy.URL, NoProxy: []string{"127.0.0.1"}})
+	if result := client.Probe(context.Background(), ""); !result.Success {
+		t.Fatalf("Probe() failed: %s", result.Error)
+	}
+	if atomic.LoadInt32(&direct) == 0 || atomic.LoadInt32(hits) != 0 {
+		t.Errorf("bypassed registry: %d direct requests, %d proxied, want direct only", direct, *hits)
+	}
+
+	// Without the bypass entry the same registry is reached through the proxy
+	atomic.StoreInt32(&direct, 0)
+	client = NewClientWithOptions(Options{Registry: server.URL, Proxy: proxy.URL, NoProxy: []string{"corp.local"}})
+	if result := client.Probe(context.Background(), ""); !result.Success {
+		t.Fatalf("Probe() failed: %s", result.Error)
+	}
+	if atomic.LoadInt32(&direct) != 0 || atomic.LoadInt32(hits) == 0 {
+		t.Errorf("proxied registry: %d direct requests, %d proxied, want proxied only", direct, *hits)
+	}
+}
+
+func TestSeparateHTTPSProxy(t *testing.T) {
+	httpProxy, httpHits := countingProxy(t)
+	httpsProxy, httpsHits := countingProxy(t)
+
+	opts := Options{Registry: "https://registry.invalid/", Proxy: httpProxy.URL, HTTPSProxy: httpsProxy.URL}
+	NewClientWithOptions(opts).Probe(context.Background(), "")
+	if atomic.LoadInt32(httpsHits) == 0 || atomic.LoadInt32(httpHits) != 0 {
+		t.Errorf("https registry: %d requests to the https proxy, %d to the http proxy", *httpsHits, *httpHits)
+	}
+
+	atomic.StoreInt32(httpsHits, 0)
+	opts.Registry = "http://registry.invalid/"
+	if result := NewClientWithOptions(opts).Probe(context.Background(), ""); !result.Success {
+		t.Fatalf("Probe() failed: %s", result.Error)
+	}
+	if atomic.LoadInt32(httpHits) == 0 || atomic.LoadInt32(httpsHits) != 0 {
+		t.Errorf("http registry: %d requests to the http proxy, %d to the https proxy", *httpHits, *httpsHits)
+	}
+}
@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// SearchOptions controls a /-/v1/search query. Weights range from 0 to 1 and
+// are only sent when set.
+type SearchOptions struct {
+	Size        int
+	From        int
+	Quality     float64
+	Popularity  float64
+	Maintenance float64
+}
+
+// SearchResponse is the body returned by /-/v1/search
+type SearchResponse struct {
+	Objects []SearchObject `json:"objects"`
+	Total   int            `json:"total"`
+	Time    string         `json:"time"`
+}
+
+// SearchObject is a single search hit
+type SearchObject struct {
+	Package     SearchPackage `json:"package"`
+	Score       SearchScore   `json:"score"`
+	SearchScore float64       `json:"searchScore"`
+}
+
+// SearchPackage is the package summary of a search hit
+type SearchPackage struct {
+	Name        string            `json:"name"`
+	Scope       string            `json:"scope,omitempty"`
+	Version     string            `json:"version"`
+	Description string            `json:"description,omitempty"`
+	Keywords    []string          `json:"keywords,omitempty"`
+	Date        string            `json:"date,omitempty"`
+	Links       map[string]string `json:"links,omitempty"`
+	Publisher   struct {
+		Username string `json:"username"`
+	} `json:"publisher"`
+}
+
+// SearchScore holds the final and per-aspect scores of a search hit
+type SearchScore struct {
+	Final  float64 `json:"final"`
+	Detail struct {
+		Quality     float64 `json:"quality"`
+		Popularity  float64 `json:"popularity"`
+		Maintenance float64 `json:"maintenance"`
+	} `json:"detail"`
+}
+
+// maxSearchSize is the largest page the public registry accepts
+const maxSearchSize = 250
+
+// Search queries the registry's /-/v1/search endpoint
+func (c *Client) Search(ctx context.Context, text string, opts SearchOptions) (*SearchResponse, error) {
+	if text == "" {
+		return nil, fmt.Errorf("search text cannot be empty")
+	}
+
+	params := url.Values{}
+	params.Set("text", text)
+	if opts.Size > 0 {
+		if opts.Size > maxSearchSize {
+			opts.Size = maxSearchSize
+		}
+		params.Set("size", strconv.Itoa(opts.Size))
+	}
+	if opts.From > 0 {
+		params.Set("from", strconv.Itoa(opts.From))
+	}
+	for name, weight := range map[string]float64{
+		"quality":     opts.Quality,
+		"popularity":  opts.Popularity,
+		"maintenance": opts.Maintenance,
+	} {
+		if weight > 0 {
+			params.Set(name, strconv.FormatFloat(weight, 'f', -1, 64))
+		}
+	}
+
+	data, err := c.get(ctx, c.baseURL+"-/v1/search?"+params.Encode(), acceptFull)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search registry: %w", err)
+	}
+
+	var response SearchResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode search results: %w", err)
+	}
+
+	c.logger.Debug("Searched registry", "text", text, "results", len(response.Objects), "total", response.Total)
+
+	return &response, nil
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"npm-console/internal/core"
+	"npm-console/internal/managers"
+)
+
+const (
+	// bundleFormatVersion is the version of the offline cache bundle layout
+	bundleFormatVersion = 1
+
+	// bundleManifest is the name of the manifest in a bundle; the tarballs
+	// are kept under bundleTarballs
+	bundleManifest = "manifest.json"
+	bundleTarballs = "tarballs/"
+)
+
+// ExportCacheBundle gathers the tarballs the lockfile of the project at
+// projectPath needs from the local caches into a gzipped tar archive at
+// archivePath, with a manifest listing them and the packages not found
+func (s *CacheService) ExportCacheBundle(ctx context.Context, projectPath string, archivePath string) (*core.CacheExportResult, error) {
+	if archivePath == "" {
+		return nil, core.NewValidationError("archive", archivePath, "archive path cannot be empty")
+	}
+
+	lockfile, err := managers.LoadLockfile(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only npm's cacache and yarn 1 keep the tarballs themselves
+	cached, _ := s.cachedPackages(ctx)
+	byIntegrity := make(map[string][]core.CachedPackage)
+	byVersion := make(map[string][]core.CachedPackage)
+	for _, pkg := range cached {
+		if managers.CachedTarball(pkg) == "" {
+			continue
+		}
+		if pkg.Integrity != "" {
+			byIntegrity[pkg.Integrity] = append(byIntegrity[pkg.Integrity], pkg)
+		}
+		byVersion[pkg.Name+"@"+pkg.Version] = append(byVersion[pkg.Name+"@"+pkg.Version], pkg)
+	}
+
+	manifest := &core.CacheBundleManifest{
+		FormatVersion: bundleFormatVersion,
+		Project:       projectPath,
+		Lockfile:      lockfile.Path,
+		CreatedAt:     time.Now(),
+	}
+	files := make(map[string]string)
+	for _, pkg := range lockedTarballs(lockfile) {
+		var candidates []core.CachedPackage
+		candidates = append(candidates, byIntegrity[managers.SHA512Integrity(pkg.Integrity)]...)
+		candidates = append(candidates, byVersion[pkg.Name+"@"+pkg.Version]...)
+
+		found := false
+		for _, candidate := range candidates {
+			tarball := managers.CachedTarball(candidate)
+			if !matchesLockfile(tarball, pkg.Integrity) {
+				continue
+			}
+			integrity, err := managers.TarballIntegrity(tarball)
+			if err != nil {
+				continue
+			}
+			info, err := os.Stat(tarball)
+			if err != nil {
+				continue
+			}
+
+			pkg.Integrity = integrity
+			pkg.File = bundleFileName(pkg)
+			pkg.Size = info.Size()
+			pkg.Source = candidate.Manager
+			files[pkg.File] = tarball
+			found = true
+			break
+		}
+
+		if found {
+			manifest.Packages = append(manifest.Packages, pkg)
+		} else {
+			manifest.Missing = append(manifest.Missing, pkg)
+		}
+	}
+
+	if err := writeCacheBundle(archivePath, manifest, files); err != nil {
+		return nil, fmt.Errorf("failed to write cache bundle: %w", err)
+	}
+
+	result := &core.CacheExportResult{Archive: archivePath, Manifest: manifest}
+	if info, err := os.Stat(archivePath); err == nil {
+		result.Size = info.Size()
+	}
+
+	s.logger.WithField("archive", archivePath).WithField("packages", len(manifest.Packages)).WithField("missing", len(manifest.Missing)).Info("Cache bundle exported")
+	return result, nil
+}
+
+// ImportCacheBundle seeds a manager's cache with the tarballs of an offline
+// cache bundle. dir is the offline mirror for managers that install from
+// one, yarn 1; it defaults to the manager's configured mirror.
+func (s *CacheService) ImportCacheBundle(ctx context.Context, archivePath string, managerName string, dir string) (*core.CacheImportResult, error) {
+	if err := s.factory.ValidateManager(managerName); err != nil {
+		return nil, err
+	}
+
+	manager, err := s.factory.GetManager(managerName)
+	if err != nil {
+		return nil, err
+	}
+	if !manager.IsAvailable(ctx) {
+		return nil, core.NewManagerError(managerName, "import cache bundle", core.ErrManagerNotAvailable)
+	}
+	importer, ok := manager.(core.CacheImporter)
+	if !ok {
+		return nil, core.NewManagerError(managerName, "import cache bundle", core.ErrNotSupported)
+	}
+
+	tmp, err := os.MkdirTemp("", "npm-console-bundle-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	manifest, err := readCacheBundle(archivePath, tmp)
+	if err != nil {
+		return nil, core.NewValidationError("archive", archivePath, err.Error())
+	}
+
+	packages, failed := verifiedTarballs(manifest.Packages)
+	result, err := importer.ImportTarballs(ctx, packages, dir)
+	if err != nil {
+		return nil, err
+	}
+	result.Failed = append(failed, result.Failed...)
+
+	s.logger.WithField("manager", managerName).WithField("archive", archivePath).WithField("imported", result.Imported).Info("Cache bundle imported")
+	return result, nil
+}
+
+// verifiedTarballs splits a bundle's packages into those whose tarball
+// matches the integrity the manifest records and failure messages for the
+// rest. Tarballs damaged in transit, or recorded without a usable integrity,
+// are not imported.
+func verifiedTarballs(bundled []core.BundledPackage) ([]core.BundledPackage, []string) {
+	var packages []core.BundledPackage
+	var failed []string
+	for _, pkg := range bundled {
+		switch {
+		case pkg.File == "":
+			failed = append(failed, pkg.Name+"@"+pkg.Version+": tarball is missing from the bundle")
+		case !managers.VerifyTarball(pkg.File, pkg.Integrity):
+			failed = append(failed, pkg.Name+"@"+pkg.Version+": tarball does not match its integrity")
+		default:
+			packages = append(packages, pkg)
+		}
+	}
+	return packages, failed
+}
+
+// matchesLockfile reports whether a cached tarball matches the integrity a
+// lockfile records for it. A checksum that is not a subresource integrity
+// string, such as a yarn 2+ checksum, cannot be checked and is accepted.
+func matchesLockfile(tarball, integrity string) bool {
+	if !strings.Contains(integrity, "-") {
+		return true
+	}
+	return managers.VerifyTarball(tarball, integrity)
+}
+
+// lockedTarballs returns the registry packages of a lockfile, one per name
+// and version, sorted. Workspace members, links and local paths have no
+// tarball to bundle.
+func lockedTarballs(lockfile *managers.Lockfile) []core.BundledPackage {
+	seen := make(map[string]bool)
+	var packages []core.BundledPackage
+	for _, locked := range lockfile.Packages {
+		if locked.Name == "" || locked.Version == "" || !isRegistryResolution(locked) {
+			continue
+		}
+		id := locked.Name + "@" + locked.Version
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		packages = append(packages, core.BundledPackage{
+			Name:      locked.Name,
+			Version:   locked.Version,
+			Resolved:  locked.Resolved,
+			Integrity: locked.Integrity,
+		})
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+	return packages
+}
+
+// isRegistryResolution reports whether a locked package comes from a
+// registry: a tarball URL, a yarn 2+ npm: resolution, bun's name@version, or
+// nothing as pnpm records for the default registry. Local paths, links,
+// workspaces and git repos have no tarball to bundle.
+func isRegistryResolution(locked *managers.LockedPackage) bool {
+	resolved := locked.Resolved
+	return resolved == "" ||
+		strings.HasPrefix(resolved, "http://") ||
+		strings.HasPrefix(resolved, "https://") ||
+		strings.Contains(resolved, "@npm:") ||
+		resolved == locked.Name+"@"+locked.Version
+}
+
+// bundleFileName names a package's tarball in a bundle. Package names
+// cannot contain '+', so the scope separator is replaced by it.
+func bundleFileName(pkg core.BundledPackage) string {
+	return bundleTarballs + strings.ReplaceAll(pkg.Name, "/", "+") + "-" + pkg.Version + ".tgz"
+}
+
+// writeCacheBundle writes the manifest and the files, given by their name
+// in the bundle, to a gzipped tar archive
+func writeCacheBundle(archivePath string, manifest *core.CacheBundleManifest, files map[string]string) (err error) {
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(archivePath)
+		}
+	}()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: bundleManifest, Mode: 0644, Size: int64(len(data)), ModTime: manifest.CreatedAt}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	for _, pkg := range manifest.Packages {
+		if err := addBundleFile(tw, pkg.File, files[pkg.File]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// addBundleFile copies the file at src into a bundle as name
+func addBundleFile(tw *tar.Writer, name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: name, Mode: 0644, Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// readCacheBundle extracts the tarballs of a bundle into dir and returns its
+// manifest, with each package's File set to its extracted tarball or
+// cleared when the bundle lacks it
+func readCacheBundle(archivePath, dir string) (*core.CacheBundleManifest, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("not a cache bundle: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest *core.CacheBundleManifest
+	extracted := make(map[string]string)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("not a cache bundle: %w", err)
+		}
+
+		switch {
+		case header.Name == bundleManifest:
+			manifest = &core.CacheBundleManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+			}
+		case strings.HasPrefix(header.Name, bundleTarballs) && header.Typeflag == tar.TypeReg:
+			// Only the base name is used, so entries cannot escape dir
+			dst := filepath.Join(dir, path.Base(header.Name))
+			if err := extractBundleFile(tr, dst); err != nil {
+				return nil, err
+			}
+			extracted[header.Name] = dst
+		}
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("not a cache bundle: %s is missing", bundleManifest)
+	}
+	if manifest.FormatVersion > bundleFormatVersion {
+		return nil, fmt.Errorf("bundle format %d is newer than this version of npm-console supports", manifest.FormatVersion)
+	}
+	for i := range manifest.Packages {
+		manifest.Packages[i].File = extracted[manifest.Packages[i].File]
+	}
+	return manifest, nil
+}
+
+// extractBundleFile writes the current entry of a bundle to dst
+func extractBundleFile(r io.Reader, dst string) error {
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"npm-console/internal/core"
+	"npm-console/internal/managers"
+)
+
+func TestLockedTarballs(t *testing.T) {
+	lockfile := &managers.Lockfile{Packages: map[string]*managers.LockedPackage{
+		"node_modules/lodash":                {Name: "lodash", Version: "4.17.21", Resolved: "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz"},
+		"node_modules/a/node_modules/lodash": {Name: "lodash", Version: "4.17.21", Resolved: "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz"},
+		"react@npm:18.2.0":                   {Name: "react", Version: "18.2.0", Resolved: "react@npm:18.2.0"},
+		"/vue@3.4.0":                         {Name: "vue", Version: "3.4.0"},
+		"packages/app":                       {Name: "app", Version: "1.0.0", Resolved: "packages/app"},
+		"ui@workspace:packages/ui":           {Name: "ui", Version: "0.0.0", Resolved: "ui@workspace:packages/ui"},
+	}}
+
+	packages := lockedTarballs(lockfile)
+	var got []string
+	for _, pkg := range packages {
+		got = append(got, pkg.Name+"@"+pkg.Version)
+	}
+	want := []string{"lodash@4.17.21", "react@18.2.0", "vue@3.4.0"}
+	if len(got) != len(want) {
+		t.Fatalf("lockedTarballs() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("lockedTarballs()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCacheBundleRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	tarball := filepath.Join(dir, "lodash.tgz")
+	os.WriteFile(tarball, []byte("lodash tarball"), 0644)
+	integrity, _ := managers.TarballIntegrity(tarball)
+
+	pkg := core.BundledPackage{Name: "@scope/lodash", Version: "4.17.21", Integrity: integrity, Source: "npm"}
+	pkg.File = bundleFileName(pkg)
+	manifest := &core.CacheBundleManifest{
+		FormatVersion: bundleFormatVersion,
+		CreatedAt:     time.Now(),
+		Packages:      []core.BundledPackage{pkg},
+		Missing:       []core.BundledPackage{{Name: "react", Version: "18.2.0"}},
+	}
+
+	archive := filepath.Join(dir, "bundle.tgz")
+	if err := writeCacheBundle(archive, manifest, map[string]string{pkg.File: tarball}); err != nil {
+		t.Fatalf("writeCacheBundle() error = %v", err)
+	}
+
+	extracted := t.TempDir()
+	read, err := readCacheBundle(archive, extracted)
+	if err != nil {
+		t.Fatalf("readCacheBundle() error = %v", err)
+	}
+	if len(read.Packages) != 1 || len(read.Missing) != 1 {
+		t.Fatalf("readCacheBundle() = %+v", read)
+	}
+	got := read.Packages[0]
+	if filepath.Dir(got.File) != extracted || !managers.VerifyTarball(got.File, got.Integrity) {
+		t.Errorf("extracted package = %+v, want an intact tarball in %s", got, extracted)
+	}
+
+	if _, err := readCacheBundle(tarball, extracted); err == nil {
+		t.Error("readCacheBundle() of a file that is not a bundle succeeded")
+	}
+}
+
+func TestVerifiedTarballs(t *testing.T) {
+	dir := t.TempDir()
+	tarball := filepath.Join(dir, "lodash.tgz")
+	os.WriteFile(tarball, []byte("lodash tarball"), 0644)
+	integrity, _ := managers.TarballIntegrity(tarball)
+	other := filepath.Join(dir, "react.tgz")
+	os.WriteFile(other, []byte("react tarball"), 0644)
+	otherIntegrity, _ := managers.TarballIntegrity(other)
+
+	bundled := []core.BundledPackage{
+		{Name: "lodash", Version: "4.17.21", File: tarball, Integrity: integrity},
+		{Name: "empty", Version: "1.0.0", File: tarball},
+		{Name: "checksum", Version: "1.0.0", File: tarball, Integrity: "10c0/0123456789abcdef"},
+		{Name: "corrupt", Version: "1.0.0", File: tarball, Integrity: otherIntegrity},
+		{Name: "missing", Version: "1.0.0", Integrity: integrity},
+	}
+	packages, failed := verifiedTarballs(bundled)
+	if len(packages) != 1 || packages[0].Name != "lodash" {
+		t.Errorf("verifiedTarballs() = %+v, want only lodash", packages)
+	}
+	if len(failed) != 4 {
+		t.Errorf("verifiedTarballs() failed = %v, want empty, checksum, corrupt and missing", failed)
+	}
+
+	// Lockfile checksums that are not SRI strings still match when exporting
+	if !matchesLockfile(tarball, "10c0/0123456789abcdef") || matchesLockfile(tarball, otherIntegrity) {
+		t.Error("matchesLockfile() should accept a yarn checksum and reject another tarball's integrity")
+	}
+}
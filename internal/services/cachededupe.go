@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"npm-console/internal/core"
+	"npm-console/internal/managers"
+)
+
+// FindCacheDuplicates lists the package versions cached by more than one
+// available manager. Reclaimable bytes are those a dedupe keeping keep's
+// copies would free, mismatched ones included; with keep empty, those freed
+// by keeping the largest copy.
+func (s *CacheService) FindCacheDuplicates(ctx context.Context, keep string) (*core.CacheDuplicateReport, error) {
+	if keep != "" {
+		if err := s.factory.ValidateManager(keep); err != nil {
+			return nil, err
+		}
+	}
+
+	packages, _ := s.cachedPackages(ctx)
+	report := &core.CacheDuplicateReport{Keep: keep, Duplicates: findDuplicates(packages, keep)}
+	for _, duplicate := range report.Duplicates {
+		report.Reclaimable += duplicate.Reclaimable
+	}
+	return report, nil
+}
+
+// DedupeCaches removes the copies other managers hold of every package
+// version keep's cache also holds. Package versions whose copies do not all
+// record the same tarball integrity are skipped unless mismatched is set.
+// With dryRun nothing is removed.
+func (s *CacheService) DedupeCaches(ctx context.Context, keep string, mismatched, dryRun bool) (*core.CacheDedupeResult, error) {
+	if keep == "" {
+		return nil, core.NewValidationError("keep", keep, "name the manager whose copies to keep")
+	}
+	if err := s.factory.ValidateManager(keep); err != nil {
+		return nil, err
+	}
+
+	packages, roots := s.cachedPackages(ctx)
+	if _, ok := roots[keep]; !ok {
+		return nil, core.NewManagerError(keep, "dedupe caches", core.ErrManagerNotAvailable)
+	}
+
+	return s.dedupe(findDuplicates(packages, keep), roots, keep, mismatched, dryRun)
+}
+
+// dedupe removes the copies of duplicates held by managers other than keep
+// from the caches under roots
+func (s *CacheService) dedupe(duplicates []core.CacheDuplicate, roots map[string]string, keep string, mismatched, dryRun bool) (*core.CacheDedupeResult, error) {
+	result := &core.CacheDedupeResult{Keep: keep, DryRun: dryRun}
+	remove := make(map[string][]core.CachedPackage)
+	for _, duplicate := range duplicates {
+		var others []core.CachedPackage
+		kept := false
+		for _, pkg := range duplicate.Copies {
+			if pkg.Manager == keep {
+				kept = true
+			} else {
+				others = append(others, pkg)
+			}
+		}
+		// Package versions keep does not hold are left alone
+		if !kept {
+			continue
+		}
+		// So are those whose copies may not be the same tarball
+		if !duplicate.SameContent && !mismatched {
+			result.Skipped = append(result.Skipped, duplicate)
+			continue
+		}
+		for _, pkg := range others {
+			remove[pkg.Manager] = append(remove[pkg.Manager], pkg)
+		}
+	}
+
+	for _, name := range sortedKeys(roots) {
+		copies := remove[name]
+		if len(copies) == 0 {
+			continue
+		}
+		result.Removed = append(result.Removed, copies...)
+
+		if dryRun {
+			for _, pkg := range copies {
+				result.BytesFreed += pkg.Size
+			}
+			continue
+		}
+
+		freed, err := managers.RemoveCachedPackages(name, roots[name], copies)
+		result.BytesFreed += freed
+		if err != nil {
+			return nil, core.NewManagerError(name, "dedupe cache", err)
+		}
+	}
+
+	if !dryRun && len(result.Removed) > 0 {
+		s.logger.WithField("keep", keep).WithField("removed", len(result.Removed)).WithField("bytes_freed", result.BytesFreed).Info("Caches deduplicated")
+	}
+	return result, nil
+}
+
+// cachedPackages lists the package versions in every available manager's
+// cache, with the cache root of each manager that could be read
+func (s *CacheService) cachedPackages(ctx context.Context) ([]core.CachedPackage, map[string]string) {
+	var packages []core.CachedPackage
+	roots := make(map[string]string)
+	for _, name := range sortedManagerNames(s.factory.GetAvailableManagers(ctx)) {
+		info, err := s.GetCacheInfo(ctx, name)
+		if err != nil {
+			s.logger.WithError(err).WithField("manager", name).Warn("Failed to get cache info")
+			continue
+		}
+		cached, err := managers.CachedPackages(name, info.Path)
+		if err != nil {
+			s.logger.WithError(err).WithField("manager", name).Warn("Failed to list cached packages")
+			continue
+		}
+		roots[name] = info.Path
+		packages = append(packages, cached...)
+	}
+	return packages, roots
+}
+
+// findDuplicates groups cached packages by name and version and returns
+// the groups held by more than one manager, most reclaimable first. Copies
+// whose name is unknown, as in older pnpm stores, are matched to the others
+// by their tarball integrity.
+func findDuplicates(packages []core.CachedPackage, keep string) []core.CacheDuplicate {
+	byIntegrity := make(map[string]core.CachedPackage)
+	for _, pkg := range packages {
+		if pkg.Name != "" && pkg.Integrity != "" {
+			byIntegrity[pkg.Integrity] = pkg
+		}
+	}
+
+	groups := make(map[string]*core.CacheDuplicate)
+	var order []string
+	for _, pkg := range packages {
+		if pkg.Name == "" {
+			named, ok := byIntegrity[pkg.Integrity]
+			if pkg.Integrity == "" || !ok {
+				continue
+			}
+			pkg.Name, pkg.Version = named.Name, named.Version
+		}
+
+		id := pkg.Name + "@" + pkg.Version
+		group, ok := groups[id]
+		if !ok {
+			group = &core.CacheDuplicate{Name: pkg.Name, Version: pkg.Version}
+			groups[id] = group
+			order = append(order, id)
+		}
+		group.Copies = append(group.Copies, pkg)
+	}
+
+	var duplicates []core.CacheDuplicate
+	for _, id := range order {
+		group := groups[id]
+		held := make(map[string]bool)
+		for _, pkg := range group.Copies {
+			held[pkg.Manager] = true
+		}
+		if len(held) < 2 {
+			continue
+		}
+
+		group.SameContent = true
+		var total, largest, others int64
+		for _, pkg := range group.Copies {
+			if pkg.Integrity == "" || pkg.Integrity != group.Copies[0].Integrity {
+				group.SameContent = false
+			}
+			total += pkg.Size
+			largest = max(largest, pkg.Size)
+			if pkg.Manager != keep {
+				others += pkg.Size
+			}
+		}
+
+		switch {
+		case keep == "":
+			group.Reclaimable = total - largest
+		case held[keep]:
+			group.Reclaimable = others
+		}
+		duplicates = append(duplicates, *group)
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Reclaimable > duplicates[j].Reclaimable
+	})
+	return duplicates
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"npm-console/internal/core"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	packages := []core.CachedPackage{
+		{Manager: "npm", Name: "lodash", Version: "4.17.21", Integrity: "sha512-lodash", Size: 300},
+		{Manager: "pnpm", Integrity: "sha512-lodash", Size: 500}, // older store, no name recorded
+		{Manager: "yarn", Name: "lodash", Version: "4.17.21", Size: 200},
+		{Manager: "npm", Name: "react", Version: "18.2.0", Integrity: "sha512-react", Size: 100},
+		{Manager: "bun", Name: "react", Version: "18.2.0", Size: 400},
+		{Manager: "npm", Name: "vue", Version: "3.4.0", Size: 50},
+		{Manager: "npm", Name: "vue", Version: "3.4.0", Size: 50}, // same manager, two registries
+	}
+
+	duplicates := findDuplicates(packages, "")
+	if len(duplicates) != 2 {
+		t.Fatalf("findDuplicates() = %+v, want lodash and react", duplicates)
+	}
+	lodash := duplicates[0]
+	if lodash.Name != "lodash" || len(lodash.Copies) != 3 || lodash.Reclaimable != 500 || lodash.SameContent {
+		t.Errorf("lodash = %+v, want 3 copies, 500 reclaimable, mixed content", lodash)
+	}
+	if duplicates[1].Name != "react" || duplicates[1].Reclaimable != 100 {
+		t.Errorf("react = %+v, want 100 reclaimable", duplicates[1])
+	}
+
+	// Keeping pnpm only reclaims what pnpm also holds
+	duplicates = findDuplicates(packages, "pnpm")
+	for _, duplicate := range duplicates {
+		want := int64(0)
+		if duplicate.Name == "lodash" {
+			want = 500
+		}
+		if duplicate.Reclaimable != want {
+			t.Errorf("%s reclaimable keeping pnpm = %d, want %d", duplicate.Name, duplicate.Reclaimable, want)
+		}
+	}
+
+	same := findDuplicates(packages[:2], "")
+	if len(same) != 1 || !same[0].SameContent {
+		t.Errorf("findDuplicates(npm, pnpm) = %+v, want the same content", same)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	roots := map[string]string{"npm": t.TempDir(), "yarn": t.TempDir()}
+	cached := func(manager, name, integrity string) core.CachedPackage {
+		path := filepath.Join(roots[manager], name+".tgz")
+		os.WriteFile(path, []byte(name), 0644)
+		return core.CachedPackage{Manager: manager, Name: name, Version: "1.0.0", Integrity: integrity, Path: path, Size: int64(len(name))}
+	}
+	packages := []core.CachedPackage{
+		cached("npm", "lodash", "sha512-lodash"),
+		cached("yarn", "lodash", "sha512-lodash"),
+		cached("npm", "react", "sha512-react"),
+		cached("yarn", "react", "sha512-other"),
+	}
+	s := NewCacheService()
+
+	result, err := s.dedupe(findDuplicates(packages, "npm"), roots, "npm", false, false)
+	if err != nil {
+		t.Fatalf("dedupe() error = %v", err)
+	}
+	if len(result.Removed) != 1 || result.Removed[0].Name != "lodash" || result.BytesFreed != 6 {
+		t.Errorf("dedupe() removed %+v, freed %d, want yarn's lodash", result.Removed, result.BytesFreed)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0].Name != "react" {
+		t.Errorf("dedupe() skipped %+v, want react", result.Skipped)
+	}
+	for _, pkg := range packages {
+		_, err := os.Stat(pkg.Path)
+		want := pkg.Manager == "yarn" && pkg.Name == "lodash"
+		if removed := os.IsNotExist(err); removed != want {
+			t.Errorf("%s's %s removed = %v, want %v", pkg.Manager, pkg.Name, removed, want)
+		}
+	}
+
+	// Copies whose integrity differs are only removed when asked for
+	result, err = s.dedupe(findDuplicates(packages[2:], "npm"), roots, "npm", true, false)
+	if err != nil {
+		t.Fatalf("dedupe(mismatched) error = %v", err)
+	}
+	if len(result.Removed) != 1 || len(result.Skipped) != 0 {
+		t.Errorf("dedupe(mismatched) = %+v, want yarn's react removed", result)
+	}
+	if _, err := os.Stat(packages[3].Path); !os.IsNotExist(err) {
+		t.Errorf("yarn's react still cached: %v", err)
+	}
+	if _, err := os.Stat(packages[2].Path); err != nil {
+		t.Errorf("npm's react was removed: %v", err)
+	}
+}
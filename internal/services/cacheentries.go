@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"npm-console/internal/core"
+)
+
+// ListCacheEntries returns the entries of a manager's cache index whose key
+// or package name contains query, ignoring case; an empty query lists all
+func (s *CacheService) ListCacheEntries(ctx context.Context, managerName string, query string) ([]core.CacheIndexEntry, error) {
+	browser, err := s.cacheBrowser(ctx, managerName)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := browser.ListCacheEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+	if query == "" {
+		return entries, nil
+	}
+
+	var matched []core.CacheIndexEntry
+	for _, entry := range entries {
+		if strings.Contains(strings.ToLower(entry.Key), query) || strings.Contains(strings.ToLower(entry.Package), query) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched, nil
+}
+
+// EvictCacheEntries removes one entry from a manager's cache index by key,
+// or every entry of a package: its packument and all its tarballs
+func (s *CacheService) EvictCacheEntries(ctx context.Context, managerName string, key string, packageName string) (*core.CacheEvictResult, error) {
+	if (key == "") == (packageName == "") {
+		return nil, core.NewValidationError("key", key, "give either a cache key or a package name")
+	}
+
+	browser, err := s.cacheBrowser(ctx, managerName)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := browser.ListCacheEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		if entry.Key == key || (packageName != "" && entry.Package == packageName) {
+			keys = append(keys, entry.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, core.NewManagerError(managerName, "evict cache entries", core.ErrCacheEntryNotFound)
+	}
+
+	result, err := browser.RemoveCacheEntries(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	result.Manager = managerName
+	return result, nil
+}
+
+// cacheBrowser returns a manager whose cache index can be browsed
+func (s *CacheService) cacheBrowser(ctx context.Context, managerName string) (core.CacheBrowser, error) {
+	if err := s.factory.ValidateManager(managerName); err != nil {
+		return nil, err
+	}
+
+	manager, err := s.factory.GetManager(managerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !manager.IsAvailable(ctx) {
+		return nil, core.NewManagerError(managerName, "cache entries", core.ErrManagerNotAvailable)
+	}
+
+	browser, ok := manager.(core.CacheBrowser)
+	if !ok {
+		return nil, core.NewManagerError(managerName, "cache entries", core.ErrNotSupported)
+	}
+	return browser, nil
+}
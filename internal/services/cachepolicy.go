@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"npm-console/internal/core"
+	"npm-console/internal/managers"
+	"npm-console/pkg/config"
+)
+
+// defaultScanInterval is used when the cache config leaves it empty
+const defaultScanInterval = time.Hour
+
+// CachePolicy limits the size and age of each package manager's cache
+type CachePolicy struct {
+	MaxSize      int64         // bytes per manager cache, 0 for no limit
+	MaxAge       time.Duration // entries not modified for longer are evicted, 0 for no limit
+	AutoClean    bool          // enforce the policy on a schedule in web mode
+	ScanInterval time.Duration // time between scheduled runs
+}
+
+// ParseCachePolicy parses the limits of the cache config. Sizes take
+// binary units ("10GB", "512MiB", "1.5G") and ages take days and weeks as
+// well as Go durations ("30d", "2w", "12h").
+func ParseCachePolicy(cfg config.CacheConfig) (*CachePolicy, error) {
+	maxSize, err := parseSize(cfg.MaxSize)
+	if err != nil {
+		return nil, core.NewValidationError("max_size", cfg.MaxSize, err.Error())
+	}
+	maxAge, err := parseAge(cfg.MaxAge)
+	if err != nil {
+		return nil, core.NewValidationError("max_age", cfg.MaxAge, err.Error())
+	}
+	interval, err := parseAge(cfg.ScanInterval)
+	if err != nil {
+		return nil, core.NewValidationError("scan_interval", cfg.ScanInterval, err.Error())
+	}
+	if interval == 0 {
+		interval = defaultScanInterval
+	}
+
+	return &CachePolicy{MaxSize: maxSize, MaxAge: maxAge, AutoClean: cfg.AutoClean, ScanInterval: interval}, nil
+}
+
+// IsEmpty reports whether the policy sets no limit
+func (p *CachePolicy) IsEmpty() bool {
+	return p.MaxSize == 0 && p.MaxAge == 0
+}
+
+var sizeUnits = map[string]int64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+}
+
+// parseSize parses a size such as "10GB" into bytes; "" means no limit
+func parseSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(value)
+	}
+	n, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size, use e.g. 500MB or 10GB")
+	}
+	unit, ok := sizeUnits[strings.ToLower(strings.TrimSpace(value[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q", value[i:])
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// parseAge parses a duration that may use d (days) and w (weeks); "" means
+// no limit
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(value, suffix); ok {
+			days, err := strconv.ParseFloat(n, 64)
+			if err != nil || days < 0 {
+				return 0, fmt.Errorf("invalid duration, use e.g. 30d, 2w or 12h")
+			}
+			return time.Duration(days * float64(unit)), nil
+		}
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration, use e.g. 30d, 2w or 12h")
+	}
+	return d, nil
+}
+
+// PruneCache enforces a cache policy on one manager's cache, or on every
+// available manager's when managerName is empty. Entries older than the max
+// age are evicted, then the oldest remaining ones until the cache fits the
+// max size. With dryRun nothing is removed.
+func (s *CacheService) PruneCache(ctx context.Context, managerName string, policy *CachePolicy, dryRun bool) ([]core.CachePruneResult, error) {
+	if policy == nil || policy.IsEmpty() {
+		return nil, core.NewValidationError("policy", "", "no max size or max age to enforce")
+	}
+
+	names := []string{managerName}
+	if managerName == "" {
+		names = sortedManagerNames(s.factory.GetAvailableManagers(ctx))
+	}
+
+	var results []core.CachePruneResult
+	for _, name := range names {
+		result, err := s.pruneCache(ctx, name, policy, dryRun)
+		if err != nil {
+			if managerName != "" {
+				return nil, err
+			}
+			// One broken cache should not stop the others from being pruned
+			s.logger.WithError(err).WithField("manager", name).Warn("Failed to prune cache")
+			continue
+		}
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// pruneCache enforces the policy on one manager's cache
+func (s *CacheService) pruneCache(ctx context.Context, managerName string, policy *CachePolicy, dryRun bool) (*core.CachePruneResult, error) {
+	info, err := s.GetCacheInfo(ctx, managerName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.pruneCacheDir(managerName, info.Path, policy, dryRun)
+	if err != nil {
+		return nil, core.NewManagerError(managerName, "prune cache", err)
+	}
+	return result, nil
+}
+
+// pruneCacheDir evicts the entries of the cache at root the policy rejects
+func (s *CacheService) pruneCacheDir(manager, root string, policy *CachePolicy, dryRun bool) (*core.CachePruneResult, error) {
+	entries, err := managers.CacheEntries(manager, root)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &core.CachePruneResult{Manager: manager, Path: root, DryRun: dryRun}
+	for _, entry := range entries {
+		result.SizeBefore += entry.Size
+	}
+
+	// Oldest first
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime.Before(entries[j].ModTime)
+	})
+
+	size := result.SizeBefore
+	cutoff := time.Now().Add(-policy.MaxAge)
+	var evict []core.CacheEntry
+	for _, entry := range entries {
+		expired := policy.MaxAge > 0 && entry.ModTime.Before(cutoff)
+		if !expired && (policy.MaxSize == 0 || size <= policy.MaxSize) {
+			break
+		}
+
+		evict = append(evict, entry)
+		size -= entry.Size
+		result.Removed++
+		result.BytesFreed += entry.Size
+		if expired {
+			result.Expired++
+		}
+	}
+	result.SizeAfter = size
+
+	// Entries are evicted together so that shared content is accounted for
+	if !dryRun && len(evict) > 0 {
+		freed, err := managers.EvictCacheEntries(manager, root, evict)
+		if err != nil {
+			return nil, err
+		}
+		result.BytesFreed = freed
+		result.SizeAfter = result.SizeBefore - freed
+	}
+
+	if result.Removed > 0 && !dryRun {
+		s.logger.WithField("manager", manager).WithField("removed", result.Removed).WithField("bytes_freed", result.BytesFreed).Info("Cache pruned")
+	}
+	return result, nil
+}
+
+// RunCachePolicy enforces the policy on every available manager's cache
+// each scan interval until ctx is done
+func (s *CacheService) RunCachePolicy(ctx context.Context, policy *CachePolicy) {
+	ticker := time.NewTicker(policy.ScanInterval)
+	defer ticker.Stop()
+
+	s.logger.WithField("interval", policy.ScanInterval.String()).Info("Cache auto-clean scheduled")
+	for {
+		if _, err := s.PruneCache(ctx, "", policy, false); err != nil {
+			s.logger.WithError(err).Warn("Scheduled cache prune failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"npm-console/pkg/config"
+)
+
+func TestParseCachePolicy(t *testing.T) {
+	policy, err := ParseCachePolicy(config.CacheConfig{MaxSize: "1.5GB", MaxAge: "2w", ScanInterval: "30m"})
+	if err != nil {
+		t.Fatalf("ParseCachePolicy() error = %v", err)
+	}
+	if policy.MaxSize != 3<<29 || policy.MaxAge != 14*24*time.Hour || policy.ScanInterval != 30*time.Minute {
+		t.Errorf("policy = %+v", policy)
+	}
+
+	policy, err = ParseCachePolicy(config.CacheConfig{MaxSize: "512mib"})
+	if err != nil || policy.MaxSize != 512<<20 || policy.MaxAge != 0 || policy.ScanInterval != defaultScanInterval {
+		t.Errorf("ParseCachePolicy() = %+v, %v", policy, err)
+	}
+
+	for _, cfg := range []config.CacheConfig{{MaxSize: "10 parsecs"}, {MaxSize: "lots"}, {MaxAge: "30 days"}, {ScanInterval: "-1h"}} {
+		if _, err := ParseCachePolicy(cfg); err == nil {
+			t.Errorf("ParseCachePolicy(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
+
+func TestPruneCacheDir(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+
+	// bun keeps one directory per package, evicted as a whole
+	write := func(name string, size int, age time.Duration) {
+		dir := filepath.Join(root, name)
+		os.MkdirAll(filepath.Join(dir, "lib"), 0755)
+		file := filepath.Join(dir, "lib", "index.js")
+		os.WriteFile(file, make([]byte, size), 0644)
+		for _, path := range []string{file, filepath.Join(dir, "lib"), dir} {
+			os.Chtimes(path, now.Add(-age), now.Add(-age))
+		}
+	}
+	write("ancient@1.0.0@@@1", 100, 90*24*time.Hour)
+	write("old@1.0.0@@@1", 400, 10*24*time.Hour)
+	write("recent@1.0.0@@@1", 300, 5*24*time.Hour)
+	write("new@1.0.0@@@1", 200, time.Hour)
+
+	s := NewCacheService()
+	policy := &CachePolicy{MaxSize: 600, MaxAge: 30 * 24 * time.Hour}
+
+	result, err := s.pruneCacheDir("bun", root, policy, true)
+	if err != nil {
+		t.Fatalf("pruneCacheDir() error = %v", err)
+	}
+	if result.Removed != 2 || result.Expired != 1 || result.SizeBefore != 1000 || result.SizeAfter != 500 {
+		t.Errorf("dry run result = %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(root, "ancient@1.0.0@@@1")); err != nil {
+		t.Error("dry run removed an entry")
+	}
+
+	if _, err := s.pruneCacheDir("bun", root, policy, false); err != nil {
+		t.Fatalf("pruneCacheDir() error = %v", err)
+	}
+	for name, kept := range map[string]bool{"ancient@1.0.0@@@1": false, "old@1.0.0@@@1": false, "recent@1.0.0@@@1": true, "new@1.0.0@@@1": true} {
+		if _, err := os.Stat(filepath.Join(root, name)); (err == nil) != kept {
+			t.Errorf("%s kept = %v, want %v", name, err == nil, kept)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"npm-console/internal/core"
+	"npm-console/internal/managers"
+)
+
+// VerifyCache checks one manager's cache, or every available manager's
+// that records content hashes when managerName is empty, for corrupt,
+// missing and orphaned entries. With repair the bad entries are deleted so
+// the next install fetches them again.
+func (s *CacheService) VerifyCache(ctx context.Context, managerName string, repair bool) ([]core.CacheVerifyResult, error) {
+	names := []string{managerName}
+	if managerName == "" {
+		names = sortedManagerNames(s.factory.GetAvailableManagers(ctx))
+	}
+
+	var results []core.CacheVerifyResult
+	for _, name := range names {
+		result, err := s.verifyCache(ctx, name, repair)
+		if err != nil {
+			if managerName != "" {
+				return nil, err
+			}
+			if !errors.Is(err, core.ErrNotSupported) {
+				s.logger.WithError(err).WithField("manager", name).Warn("Failed to verify cache")
+			}
+			continue
+		}
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// verifyCache checks, and with repair fixes, one manager's cache
+func (s *CacheService) verifyCache(ctx context.Context, managerName string, repair bool) (*core.CacheVerifyResult, error) {
+	info, err := s.GetCacheInfo(ctx, managerName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := managers.VerifyCache(managerName, info.Path)
+	if err != nil {
+		return nil, core.NewManagerError(managerName, "verify cache", err)
+	}
+
+	if repair && len(result.Issues) > 0 {
+		if err := managers.RepairCache(result); err != nil {
+			return nil, core.NewManagerError(managerName, "repair cache", err)
+		}
+		s.logger.WithField("manager", managerName).WithField("repaired", result.Repaired).WithField("bytes_freed", result.BytesFreed).Info("Cache repaired")
+	}
+	return result, nil
+}
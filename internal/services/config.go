@@ -4,28 +4,48 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"npm-console/internal/core"
 	"npm-console/internal/managers"
+	"npm-console/internal/registry"
+	"npm-console/pkg/config"
 	"npm-console/pkg/logger"
+	"npm-console/pkg/utils"
 )
 
 // ConfigService implements configuration management functionality
 type ConfigService struct {
-	factory *managers.ManagerFactory
-	logger  *logger.Logger
+	factory   *managers.ManagerFactory
+	logger    *logger.Logger
+	snapshots *SnapshotStore
 }
 
 // NewConfigService creates a new config service
 func NewConfigService() *ConfigService {
 	return &ConfigService{
-		factory: managers.GetGlobalFactory(),
-		logger:  logger.GetDefault().WithField("service", "config"),
+		factory:   managers.GetGlobalFactory(),
+		logger:    logger.GetDefault().WithField("service", "config"),
+		snapshots: NewSnapshotStore(snapshotDir(config.DefaultConfig().App.DataDir)),
 	}
 }
 
+// SetDataDir sets the npm-console data directory that config snapshots are
+// kept in
+func (s *ConfigService) SetDataDir(dataDir string) {
+	s.snapshots = NewSnapshotStore(snapshotDir(dataDir))
+}
+
+// snapshotDir returns the snapshot directory inside a data directory
+func snapshotDir(dataDir string) string {
+	return filepath.Join(dataDir, "snapshots")
+}
+
 // GetAllConfigs returns configuration for all available package managers
 func (s *ConfigService) GetAllConfigs(ctx context.Context) ([]core.Config, error) {
 	availableManagers := s.factory.GetAvailableManagers(ctx)
@@ -50,6 +70,7 @@ func (s *ConfigService) GetAllConfigs(ctx context.Context) ([]core.Config, error
 				return
 			}
 			
+			redactConfig(config)
 			mu.Lock()
 			configs = append(configs, *config)
 			mu.Unlock()
@@ -84,7 +105,29 @@ func (s *ConfigService) GetConfig(ctx context.Context, managerName string) (*cor
 		return nil, core.NewManagerError(managerName, "get config", core.ErrManagerNotAvailable)
 	}
 	
-	return manager.GetConfig(ctx)
+	config, err := manager.GetConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	redactConfig(config)
+	return config, nil
+}
+
+// redactConfig masks credentials in a manager configuration: auth settings
+// and passwords embedded in registry or proxy URLs
+func redactConfig(config *core.Config) {
+	config.Registry = utils.RedactURL(config.Registry)
+	config.Proxy = utils.RedactURL(config.Proxy)
+	config.HTTPSProxy = utils.RedactURL(config.HTTPSProxy)
+	
+	for key, value := range config.Settings {
+		if utils.IsSecretKey(key) {
+			config.Settings[key] = utils.MaskSecret(value)
+		} else {
+			config.Settings[key] = utils.RedactURL(value)
+		}
+	}
 }
 
 // SetRegistry sets the registry URL for a specific manager
@@ -102,6 +145,10 @@ func (s *ConfigService) SetRegistry(ctx context.Context, managerName string, reg
 		return core.NewManagerError(managerName, "set registry", core.ErrManagerNotAvailable)
 	}
 	
+	if err := s.snapshotBefore(fmt.Sprintf("set registry for %s to %s", managerName, registryURL)); err != nil {
+		return err
+	}
+	
 	err = manager.SetRegistry(ctx, registryURL)
 	if err != nil {
 		return err
@@ -117,38 +164,28 @@ func (s *ConfigService) SetRegistryForAll(ctx context.Context, registryURL strin
 		return err
 	}
 	
+	if err := s.snapshotBefore("set registry for all managers to " + registryURL); err != nil {
+		return err
+	}
+	
 	availableManagers := s.factory.GetAvailableManagers(ctx)
 	
-	var mu sync.Mutex
-	var wg sync.WaitGroup
 	var errors []error
 	var successCount int
 
-	// Set registry concurrently for all managers
-	for name, manager := range availableManagers {
-		wg.Add(1)
-		go func(name string, mgr core.PackageManager) {
-			defer wg.Done()
-			
-			err := mgr.SetRegistry(ctx, registryURL)
-			if err != nil {
-				s.logger.WithError(err).WithField("manager", name).Error("Failed to set registry")
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("failed to set registry for %s: %w", name, err))
-				mu.Unlock()
-				return
-			}
-			
-			mu.Lock()
-			successCount++
-			mu.Unlock()
-			
-			s.logger.WithField("manager", name).WithField("registry", registryURL).Info("Registry updated")
-		}(name, manager)
+	// Managers share config files (npm and pnpm both write ~/.npmrc), so they
+	// are updated one after another
+	for _, name := range sortedManagerNames(availableManagers) {
+		if err := availableManagers[name].SetRegistry(ctx, registryURL); err != nil {
+			s.logger.WithError(err).WithField("manager", name).Error("Failed to set registry")
+			errors = append(errors, fmt.Errorf("failed to set registry for %s: %w", name, err))
+			continue
+		}
+		
+		successCount++
+		s.logger.WithField("manager", name).WithField("registry", registryURL).Info("Registry updated")
 	}
 	
-	wg.Wait()
-	
 	s.logger.WithField("success_count", successCount).WithField("total_managers", len(availableManagers)).Info("Registry update completed")
 	
 	// Return error if any registry setting failed
@@ -159,12 +196,11 @@ func (s *ConfigService) SetRegistryForAll(ctx context.Context, registryURL strin
 	return nil
 }
 
-// SetProxy sets the proxy configuration for a specific manager
-func (s *ConfigService) SetProxy(ctx context.Context, managerName string, proxyURL string) error {
-	if proxyURL != "" {
-		if err := s.ValidateProxyURL(proxyURL); err != nil {
-			return err
-		}
+// SetProxy sets the HTTP and HTTPS proxies and the bypass list of a specific
+// manager; empty settings remove them
+func (s *ConfigService) SetProxy(ctx context.Context, managerName string, proxy core.ProxySettings) error {
+	if err := s.ValidateProxySettings(proxy); err != nil {
+		return err
 	}
 	
 	manager, err := s.factory.GetManager(managerName)
@@ -176,64 +212,56 @@ func (s *ConfigService) SetProxy(ctx context.Context, managerName string, proxyU
 		return core.NewManagerError(managerName, "set proxy", core.ErrManagerNotAvailable)
 	}
 	
-	err = manager.SetProxy(ctx, proxyURL)
+	if err := s.snapshotBefore(fmt.Sprintf("set proxy for %s", managerName)); err != nil {
+		return err
+	}
+	
+	err = manager.SetProxy(ctx, proxy)
 	if err != nil {
 		return err
 	}
 	
-	if proxyURL == "" {
+	if proxy.HTTP == "" && proxy.HTTPS == "" {
 		s.logger.WithField("manager", managerName).Info("Proxy removed")
 	} else {
-		s.logger.WithField("manager", managerName).WithField("proxy", proxyURL).Info("Proxy updated")
+		s.logger.WithField("manager", managerName).WithField("proxy", utils.RedactURL(proxy.HTTP)).WithField("https_proxy", utils.RedactURL(proxy.HTTPS)).Info("Proxy updated")
 	}
 	
 	return nil
 }
 
-// SetProxyForAll sets the proxy configuration for all available managers
-func (s *ConfigService) SetProxyForAll(ctx context.Context, proxyURL string) error {
-	if proxyURL != "" {
-		if err := s.ValidateProxyURL(proxyURL); err != nil {
-			return err
-		}
+// SetProxyForAll sets the proxies and bypass list of all available managers
+func (s *ConfigService) SetProxyForAll(ctx context.Context, proxy core.ProxySettings) error {
+	if err := s.ValidateProxySettings(proxy); err != nil {
+		return err
+	}
+	
+	if err := s.snapshotBefore("set proxy for all managers"); err != nil {
+		return err
 	}
 	
 	availableManagers := s.factory.GetAvailableManagers(ctx)
 	
-	var mu sync.Mutex
-	var wg sync.WaitGroup
 	var errors []error
 	var successCount int
 
-	// Set proxy concurrently for all managers
-	for name, manager := range availableManagers {
-		wg.Add(1)
-		go func(name string, mgr core.PackageManager) {
-			defer wg.Done()
-			
-			err := mgr.SetProxy(ctx, proxyURL)
-			if err != nil {
-				s.logger.WithError(err).WithField("manager", name).Error("Failed to set proxy")
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("failed to set proxy for %s: %w", name, err))
-				mu.Unlock()
-				return
-			}
-			
-			mu.Lock()
-			successCount++
-			mu.Unlock()
-			
-			if proxyURL == "" {
-				s.logger.WithField("manager", name).Info("Proxy removed")
-			} else {
-				s.logger.WithField("manager", name).WithField("proxy", proxyURL).Info("Proxy updated")
-			}
-		}(name, manager)
+	// Managers share config files (npm and pnpm both write ~/.npmrc), so they
+	// are updated one after another
+	for _, name := range sortedManagerNames(availableManagers) {
+		if err := availableManagers[name].SetProxy(ctx, proxy); err != nil {
+			s.logger.WithError(err).WithField("manager", name).Error("Failed to set proxy")
+			errors = append(errors, fmt.Errorf("failed to set proxy for %s: %w", name, err))
+			continue
+		}
+		
+		successCount++
+		if proxy.HTTP == "" && proxy.HTTPS == "" {
+			s.logger.WithField("manager", name).Info("Proxy removed")
+		} else {
+			s.logger.WithField("manager", name).WithField("proxy", utils.RedactURL(proxy.HTTP)).WithField("https_proxy", utils.RedactURL(proxy.HTTPS)).Info("Proxy updated")
+		}
 	}
 	
-	wg.Wait()
-	
 	s.logger.WithField("success_count", successCount).WithField("total_managers", len(availableManagers)).Info("Proxy update completed")
 	
 	// Return error if any proxy setting failed
@@ -244,30 +272,704 @@ func (s *ConfigService) SetProxyForAll(ctx context.Context, proxyURL string) err
 	return nil
 }
 
-// TestRegistry tests connectivity to a registry URL
-func (s *ConfigService) TestRegistry(ctx context.Context, managerName string, registryURL string) error {
+// ListScopedRegistries returns the scoped registries of a manager, or of all
+// available managers that support them when no manager is given
+func (s *ConfigService) ListScopedRegistries(ctx context.Context, managerName string) ([]core.ScopedRegistry, error) {
+	scopedManagers := make(map[string]core.ScopedRegistryManager)
+	if managerName == "" {
+		for name, manager := range s.factory.GetAvailableManagers(ctx) {
+			if scoped, ok := manager.(core.ScopedRegistryManager); ok {
+				scopedManagers[name] = scoped
+			}
+		}
+	} else {
+		scoped, err := s.scopedManager(ctx, managerName)
+		if err != nil {
+			return nil, err
+		}
+		scopedManagers[managerName] = scoped
+	}
+
+	var registries []core.ScopedRegistry
+	for name, scoped := range scopedManagers {
+		scopes, err := scoped.GetScopedRegistries(ctx)
+		if err != nil {
+			if managerName != "" {
+				return nil, err
+			}
+			s.logger.WithError(err).WithField("manager", name).Warn("Failed to get scoped registries")
+			continue
+		}
+
+		for scope, registryURL := range scopes {
+			registries = append(registries, core.ScopedRegistry{
+				Manager:  name,
+				Scope:    scope,
+				Registry: registryURL,
+			})
+		}
+	}
+
+	sort.Slice(registries, func(i, j int) bool {
+		if registries[i].Manager != registries[j].Manager {
+			return registries[i].Manager < registries[j].Manager
+		}
+		return registries[i].Scope < registries[j].Scope
+	})
+
+	return registries, nil
+}
+
+// SetScopedRegistry routes a scope to a registry for a specific manager; an
+// empty URL removes the mapping
+func (s *ConfigService) SetScopedRegistry(ctx context.Context, managerName string, scope string, registryURL string) error {
+	if err := s.validateScopedRegistry(scope, registryURL); err != nil {
+		return err
+	}
+
+	scoped, err := s.scopedManager(ctx, managerName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.snapshotBefore(fmt.Sprintf("set %s registry for %s", scope, managerName)); err != nil {
+		return err
+	}
+
+	if err := scoped.SetScopedRegistry(ctx, scope, registryURL); err != nil {
+		return err
+	}
+
+	s.logger.WithField("manager", managerName).WithField("scope", scope).WithField("registry", registryURL).Info("Scoped registry updated")
+	return nil
+}
+
+// SetScopedRegistryForAll routes a scope to a registry for all available
+// managers that support scoped registries; an empty URL removes the mapping
+func (s *ConfigService) SetScopedRegistryForAll(ctx context.Context, scope string, registryURL string) error {
+	if err := s.validateScopedRegistry(scope, registryURL); err != nil {
+		return err
+	}
+
+	if err := s.snapshotBefore(fmt.Sprintf("set %s registry for all managers", scope)); err != nil {
+		return err
+	}
+
+	var errors []error
+	for name, manager := range s.factory.GetAvailableManagers(ctx) {
+		scoped, ok := manager.(core.ScopedRegistryManager)
+		if !ok {
+			continue
+		}
+
+		if err := scoped.SetScopedRegistry(ctx, scope, registryURL); err != nil {
+			s.logger.WithError(err).WithField("manager", name).Error("Failed to set scoped registry")
+			errors = append(errors, fmt.Errorf("failed to set scoped registry for %s: %w", name, err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to set scoped registry for some managers: %v", errors)
+	}
+
+	s.logger.WithField("scope", scope).WithField("registry", registryURL).Info("Scoped registry updated for all managers")
+	return nil
+}
+
+// scopedManager returns an available manager that supports scoped registries
+func (s *ConfigService) scopedManager(ctx context.Context, managerName string) (core.ScopedRegistryManager, error) {
+	if err := s.factory.ValidateManager(managerName); err != nil {
+		return nil, err
+	}
+
+	manager, err := s.factory.GetManager(managerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !manager.IsAvailable(ctx) {
+		return nil, core.NewManagerError(managerName, "scoped registries", core.ErrManagerNotAvailable)
+	}
+
+	scoped, ok := manager.(core.ScopedRegistryManager)
+	if !ok {
+		return nil, core.NewManagerError(managerName, "scoped registries", core.ErrNotSupported)
+	}
+	return scoped, nil
+}
+
+// validateScopedRegistry checks a scope name and, unless it is being removed,
+// its registry URL
+func (s *ConfigService) validateScopedRegistry(scope string, registryURL string) error {
+	if !strings.HasPrefix(scope, "@") || len(scope) < 2 || strings.ContainsAny(scope, "/: ") {
+		return core.NewValidationError("scope", scope, "scope must look like @name")
+	}
+	if registryURL == "" {
+		return nil
+	}
+	return s.ValidateRegistryURL(registryURL)
+}
+
+// ListCredentials returns the registries and masked auth tokens of a manager,
+// or of all available managers when no manager is given
+func (s *ConfigService) ListCredentials(ctx context.Context, managerName string) ([]core.Credential, error) {
+	credentialManagers := make(map[string]core.CredentialManager)
+	if managerName == "" {
+		for name, manager := range s.factory.GetAvailableManagers(ctx) {
+			if credentials, ok := manager.(core.CredentialManager); ok {
+				credentialManagers[name] = credentials
+			}
+		}
+	} else {
+		credentials, err := s.credentialManager(ctx, managerName)
+		if err != nil {
+			return nil, err
+		}
+		credentialManagers[managerName] = credentials
+	}
+
+	var credentials []core.Credential
+	for _, name := range sortedManagerNames(credentialManagers) {
+		found, err := credentialManagers[name].GetCredentials(ctx)
+		if err != nil {
+			if managerName != "" {
+				return nil, err
+			}
+			s.logger.WithError(err).WithField("manager", name).Warn("Failed to get credentials")
+			continue
+		}
+		credentials = append(credentials, found...)
+	}
+
+	return credentials, nil
+}
+
+// SetAuthToken stores a registry auth token for a specific manager in its
+// native config file. When envVar is set, a reference to the variable is
+// stored instead of the token; when both are empty the token is removed.
+func (s *ConfigService) SetAuthToken(ctx context.Context, managerName string, registryURL string, token string, envVar string) error {
+	if err := s.validateAuthToken(registryURL, token, envVar); err != nil {
+		return err
+	}
+
+	credentials, err := s.credentialManager(ctx, managerName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.snapshotBefore(fmt.Sprintf("set auth token for %s on %s", registryURL, managerName)); err != nil {
+		return err
+	}
+
+	if err := credentials.SetAuthToken(ctx, registryURL, token, envVar); err != nil {
+		return err
+	}
+
+	s.logger.WithField("manager", managerName).WithField("registry", registryURL).Info("Auth token updated")
+	return nil
+}
+
+// SetAuthTokenForAll stores a registry auth token for all available managers
+func (s *ConfigService) SetAuthTokenForAll(ctx context.Context, registryURL string, token string, envVar string) error {
+	if err := s.validateAuthToken(registryURL, token, envVar); err != nil {
+		return err
+	}
+
+	if err := s.snapshotBefore(fmt.Sprintf("set auth token for %s on all managers", registryURL)); err != nil {
+		return err
+	}
+
+	var errors []error
+	for name, manager := range s.factory.GetAvailableManagers(ctx) {
+		credentials, ok := manager.(core.CredentialManager)
+		if !ok {
+			continue
+		}
+
+		if err := credentials.SetAuthToken(ctx, registryURL, token, envVar); err != nil {
+			s.logger.WithError(err).WithField("manager", name).Error("Failed to set auth token")
+			errors = append(errors, fmt.Errorf("failed to set auth token for %s: %w", name, err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to set auth token for some managers: %v", errors)
+	}
+
+	s.logger.WithField("registry", registryURL).Info("Auth token updated for all managers")
+	return nil
+}
+
+// credentialManager returns an available manager whose auth tokens can be managed
+func (s *ConfigService) credentialManager(ctx context.Context, managerName string) (core.CredentialManager, error) {
+	if err := s.factory.ValidateManager(managerName); err != nil {
+		return nil, err
+	}
+
+	manager, err := s.factory.GetManager(managerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !manager.IsAvailable(ctx) {
+		return nil, core.NewManagerError(managerName, "credentials", core.ErrManagerNotAvailable)
+	}
+
+	credentials, ok := manager.(core.CredentialManager)
+	if !ok {
+		return nil, core.NewManagerError(managerName, "credentials", core.ErrNotSupported)
+	}
+	return credentials, nil
+}
+
+// envVarNamePattern matches a valid environment variable name
+var envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateAuthToken checks the registry and where the token comes from
+func (s *ConfigService) validateAuthToken(registryURL string, token string, envVar string) error {
 	if err := s.ValidateRegistryURL(registryURL); err != nil {
 		return err
 	}
+	if token != "" && envVar != "" {
+		return core.NewValidationError("token", utils.MaskSecret(token), "give either a token or an environment variable, not both")
+	}
+	if envVar != "" && !envVarNamePattern.MatchString(envVar) {
+		return core.NewValidationError("env_var", envVar, "invalid environment variable name")
+	}
+	if strings.ContainsAny(token, " \t\r\n\"") {
+		return core.NewValidationError("token", utils.MaskSecret(token), "token cannot contain whitespace or quotes")
+	}
+	return nil
+}
+
+// sortedManagerNames returns the keys of a manager map in sorted order
+func sortedManagerNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// snapshotBefore saves the managers' config files before a change so it can
+// be rolled back
+func (s *ConfigService) snapshotBefore(reason string) error {
+	snapshot, err := s.snapshots.Take(reason)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot config before change: %w", err)
+	}
+
+	s.logger.WithField("snapshot", snapshot.ID).WithField("reason", reason).Debug("Config snapshot taken")
+	return nil
+}
+
+// ConfigHistory returns the config snapshots taken before changes, newest first
+func (s *ConfigService) ConfigHistory(ctx context.Context) ([]core.ConfigSnapshot, error) {
+	return s.snapshots.List()
+}
+
+// DiffConfig compares two config snapshots; either may be CurrentSnapshot
+// for the live config files. Secret values are masked.
+func (s *ConfigService) DiffConfig(ctx context.Context, from string, to string) (*core.ConfigDiff, error) {
+	return s.snapshots.Diff(from, to)
+}
+
+// RollbackConfig restores the config files saved in a snapshot. The current
+// files are snapshotted first, so a rollback can itself be rolled back.
+func (s *ConfigService) RollbackConfig(ctx context.Context, id string) (*core.ConfigRollback, error) {
+	if id == CurrentSnapshot {
+		return nil, core.NewValidationError("snapshot", id, "cannot roll back to the current config")
+	}
+	if _, err := s.snapshots.load(id); err != nil {
+		return nil, err
+	}
+
+	backup, err := s.snapshots.Take("rollback to " + id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot config before rollback: %w", err)
+	}
+
+	restored, err := s.snapshots.Restore(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithField("snapshot", id).WithField("files", len(restored)).Info("Config rolled back")
+	return &core.ConfigRollback{Snapshot: id, Backup: backup.ID, Restored: restored}, nil
+}
+
+// ExplainConfig returns the effective value of a config key with the layer
+// it comes from and the values it shadows, for one manager or for every
+// manager when none is given. An empty key returns every key. The config
+// files are read directly, so the managers need not be installed; cli holds
+// values as they would be passed on the command line.
+func (s *ConfigService) ExplainConfig(ctx context.Context, managerName string, key string, projectPath string, cli map[string]string) ([]core.ConfigEntry, error) {
+	explainers := make(map[string]core.ConfigExplainer)
+	if managerName == "" {
+		for name, manager := range s.factory.GetAllManagers() {
+			if explainer, ok := manager.(core.ConfigExplainer); ok {
+				explainers[name] = explainer
+			}
+		}
+	} else {
+		if err := s.factory.ValidateManager(managerName); err != nil {
+			return nil, err
+		}
+		manager, err := s.factory.GetManager(managerName)
+		if err != nil {
+			return nil, err
+		}
+		explainer, ok := manager.(core.ConfigExplainer)
+		if !ok {
+			return nil, core.NewManagerError(managerName, "explain config", core.ErrNotSupported)
+		}
+		explainers[managerName] = explainer
+	}
+
+	if projectPath == "" {
+		projectPath = "."
+	}
+
+	var entries []core.ConfigEntry
+	for _, name := range sortedManagerNames(explainers) {
+		managerEntries, err := explainers[name].ExplainConfig(ctx, projectPath, cli)
+		if err != nil {
+			return nil, core.NewManagerError(name, "explain config", err)
+		}
+		for _, entry := range managerEntries {
+			if key == "" || entry.Key == key {
+				entries = append(entries, redactEntry(entry))
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// redactEntry masks credentials in an explained config entry
+func redactEntry(entry core.ConfigEntry) core.ConfigEntry {
+	entry.Value = redactValue(entry.Key, entry.Value)
+	shadowed := make([]core.ConfigSource, len(entry.Shadowed))
+	for i, source := range entry.Shadowed {
+		source.Value = redactValue(entry.Key, source.Value)
+		shadowed[i] = source
+	}
+	entry.Shadowed = shadowed
+	return entry
+}
+
+// redactValue masks the value of a secret config key and passwords in URLs.
+// References to environment variables are kept, since they reveal no secret.
+func redactValue(key string, value string) string {
+	if utils.IsSecretKey(key) && !strings.HasPrefix(value, "$") {
+		return utils.MaskSecret(value)
+	}
+	return utils.RedactURL(value)
+}
+
+// profileSnapshot records a manager's settings before a profile was applied
+type profileSnapshot struct {
+	name     string
+	manager  core.PackageManager
+	registry string
+	scopes   map[string]string
+}
+
+// ApplyRegistryProfile applies a registry profile, including its per-manager
+// overrides and scope mappings, to the named managers or to all available
+// managers when none are given. Managers are changed one at a time; if any
+// change fails, the managers already changed are restored to their previous
+// registries and scopes. Managers without scoped registry support only get the
+// registry. It returns the registry applied to each manager.
+func (s *ConfigService) ApplyRegistryProfile(ctx context.Context, profile *core.RegistryProfile, managerNames []string) (map[string]string, error) {
+	if err := s.validateProfile(profile); err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]core.PackageManager)
+	if len(managerNames) == 0 {
+		targets = s.factory.GetAvailableManagers(ctx)
+	}
+	for _, name := range managerNames {
+		manager, err := s.factory.GetManager(name)
+		if err != nil {
+			return nil, err
+		}
+		if !manager.IsAvailable(ctx) {
+			return nil, core.NewManagerError(name, "apply registry profile", core.ErrManagerNotAvailable)
+		}
+		targets[name] = manager
+	}
+
+	if len(targets) == 0 {
+		return nil, core.ErrManagerNotAvailable
+	}
+
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := s.snapshotBefore(fmt.Sprintf("apply registry profile %s", profile.Name)); err != nil {
+		return nil, err
+	}
+
+	// Record the current settings so a failed apply can be undone
+	snapshots := make([]profileSnapshot, 0, len(names))
+	for _, name := range names {
+		snapshot := profileSnapshot{name: name, manager: targets[name]}
+
+		registry, err := userRegistry(ctx, snapshot.manager)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read current config for %s: %w", name, err)
+		}
+		snapshot.registry = registry
+
+		if scoped, ok := snapshot.manager.(core.ScopedRegistryManager); ok && len(profile.Scopes) > 0 {
+			if snapshot.scopes, err = scoped.GetScopedRegistries(ctx); err != nil {
+				return nil, fmt.Errorf("failed to read scoped registries for %s: %w", name, err)
+			}
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	applied := make(map[string]string)
+	for i, snapshot := range snapshots {
+		if err := s.applyProfileTo(ctx, profile, snapshot); err != nil {
+			s.rollbackProfile(ctx, profile, snapshots[:i+1])
+			return nil, fmt.Errorf("failed to apply profile %s to %s, changes were rolled back: %w", profile.Name, snapshot.name, err)
+		}
+		applied[snapshot.name] = profile.RegistryFor(snapshot.name)
+	}
+
+	s.logger.WithField("profile", profile.Name).WithField("managers", len(applied)).Info("Registry profile applied")
+	return applied, nil
+}
+
+// validateProfile checks every URL and manager name in a profile
+func (s *ConfigService) validateProfile(profile *core.RegistryProfile) error {
+	if err := s.ValidateRegistryURL(profile.Registry); err != nil {
+		return err
+	}
+	for name, registryURL := range profile.Managers {
+		if err := s.factory.ValidateManager(name); err != nil {
+			return err
+		}
+		if registryURL != "" {
+			if err := s.ValidateRegistryURL(registryURL); err != nil {
+				return err
+			}
+		}
+	}
+	for scope, registryURL := range profile.Scopes {
+		if registryURL == "" {
+			return core.NewValidationError("scope", scope, "scope registry URL cannot be empty")
+		}
+		if err := s.validateScopedRegistry(scope, registryURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyProfileTo sets the profile's registry and scopes on one manager
+func (s *ConfigService) applyProfileTo(ctx context.Context, profile *core.RegistryProfile, snapshot profileSnapshot) error {
+	if err := snapshot.manager.SetRegistry(ctx, profile.RegistryFor(snapshot.name)); err != nil {
+		return err
+	}
+
+	if len(profile.Scopes) == 0 {
+		return nil
+	}
+
+	scoped, ok := snapshot.manager.(core.ScopedRegistryManager)
+	if !ok {
+		s.logger.WithField("manager", snapshot.name).Warn("Scoped registries not supported, skipping profile scopes")
+		return nil
+	}
+
+	for scope, registryURL := range profile.Scopes {
+		if err := scoped.SetScopedRegistry(ctx, scope, registryURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollbackProfile restores the recorded settings of the given managers
+func (s *ConfigService) rollbackProfile(ctx context.Context, profile *core.RegistryProfile, snapshots []profileSnapshot) {
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		snapshot := snapshots[i]
+		log := s.logger.WithField("manager", snapshot.name)
+
+		// An empty registry was not set in the user config and is removed again
+		if err := snapshot.manager.SetRegistry(ctx, snapshot.registry); err != nil {
+			log.WithError(err).Error("Failed to restore registry")
+		}
+
+		scoped, ok := snapshot.manager.(core.ScopedRegistryManager)
+		if !ok {
+			continue
+		}
+		for scope := range profile.Scopes {
+			if err := scoped.SetScopedRegistry(ctx, scope, snapshot.scopes[scope]); err != nil {
+				log.WithError(err).WithField("scope", scope).Error("Failed to restore scoped registry")
+			}
+		}
+	}
+}
+
+// userRegistry returns the registry set in a manager's user config file, the
+// one SetRegistry writes, or "" when it is not set there. GetConfig cannot tell
+// an unset registry from the built-in default.
+func userRegistry(ctx context.Context, manager core.PackageManager) (string, error) {
+	explainer, ok := manager.(core.ConfigExplainer)
+	if !ok {
+		config, err := manager.GetConfig(ctx)
+		if err != nil {
+			return "", err
+		}
+		return config.Registry, nil
+	}
+
+	entries, err := explainer.ExplainConfig(ctx, "", nil)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.Key != "registry" {
+			continue
+		}
+		if entry.Layer == core.LayerUser {
+			return entry.Value, nil
+		}
+		for _, shadowed := range entry.Shadowed {
+			if shadowed.Layer == core.LayerUser {
+				return shadowed.Value, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// connectivityTimeout bounds each registry and proxy probe
+const connectivityTimeout = 15 * time.Second
+
+// TestRegistry probes a registry with the manager's proxy and the credentials
+// it has for the registry's host: it pings the registry, fetches a known
+// packument and reports status, latency and TLS details. Connectivity failures
+// are reported in the result; the error is only set for invalid input.
+func (s *ConfigService) TestRegistry(ctx context.Context, managerName string, registryURL string) (*core.RegistryTestResult, error) {
+	if err := s.ValidateRegistryURL(registryURL); err != nil {
+		return nil, err
+	}
 	
-	// For now, just validate the URL format
-	// In the future, this could make an actual HTTP request to test connectivity
-	s.logger.WithField("manager", managerName).WithField("registry", registryURL).Info("Registry test passed (URL validation)")
+	opts := registryOptions(ctx, s.factory, managerName, "").WithRegistry(registryURL)
+	opts.Timeout = connectivityTimeout
+	opts.CacheDir = ""
 	
-	return nil
+	result := registry.NewClientWithOptions(opts).Probe(ctx, registry.ProbePackage)
+	result.Manager = managerName
+	result.Proxy = utils.RedactURL(opts.ProxyFor(registryURL))
+	
+	log := s.logger.WithField("manager", managerName).WithField("registry", registryURL)
+	if result.Success {
+		log.WithField("latency_ms", result.LatencyMs).Info("Registry test passed")
+	} else {
+		log.WithField("error", result.Error).Warn("Registry test failed")
+	}
+	
+	return result, nil
 }
 
-// TestProxy tests proxy connectivity
-func (s *ConfigService) TestProxy(ctx context.Context, managerName string, proxyURL string) error {
+// TestProxy checks that the manager's registry can be reached through a proxy
+func (s *ConfigService) TestProxy(ctx context.Context, managerName string, proxyURL string) (*core.ProxyTestResult, error) {
+	opts := registryOptions(ctx, s.factory, managerName, "")
+	
+	// Test the proxy the manager uses for its registry when none is given
+	if proxyURL == "" {
+		target := opts.Registry
+		if target == "" {
+			target = registry.DefaultRegistry
+		}
+		proxyURL = opts.ProxyFor(target)
+		if proxyURL == "" {
+			return nil, core.NewValidationError("proxy", proxyURL, "no proxy is used for "+utils.RedactURL(target))
+		}
+	}
 	if err := s.ValidateProxyURL(proxyURL); err != nil {
-		return err
+		return nil, err
 	}
+	opts.Proxy = proxyURL
+	opts.HTTPSProxy = proxyURL
+	opts.NoProxy = nil
+	opts.Timeout = connectivityTimeout
+	opts.CacheDir = ""
 	
-	// For now, just validate the URL format
-	// In the future, this could make an actual HTTP request through the proxy
-	s.logger.WithField("manager", managerName).WithField("proxy", proxyURL).Info("Proxy test passed (URL validation)")
+	probe := registry.NewClientWithOptions(opts).Probe(ctx, registry.ProbePackage)
+	result := &core.ProxyTestResult{
+		Manager:    managerName,
+		Proxy:      utils.RedactURL(proxyURL),
+		Target:     probe.Registry,
+		Success:    probe.Success,
+		StatusCode: probe.StatusCode,
+		LatencyMs:  probe.LatencyMs,
+		AuthFailed: probe.AuthFailed,
+		TLS:        probe.TLS,
+		Error:      probe.Error,
+	}
 	
-	return nil
+	log := s.logger.WithField("manager", managerName).WithField("proxy", result.Proxy)
+	if result.Success {
+		log.WithField("latency_ms", result.LatencyMs).Info("Proxy test passed")
+	} else {
+		log.WithField("error", result.Error).Warn("Proxy test failed")
+	}
+	
+	return result, nil
+}
+
+// RegistryPresets returns the built-in registry mirrors
+func (s *ConfigService) RegistryPresets() []core.RegistryMirror {
+	return append([]core.RegistryMirror(nil), registry.PresetMirrors...)
+}
+
+// BenchmarkRegistries probes the candidate registries concurrently with the
+// manager's credentials and proxy, and returns them fastest first. Candidates
+// sharing a URL are only probed once.
+func (s *ConfigService) BenchmarkRegistries(ctx context.Context, managerName string, mirrors []core.RegistryMirror, rounds int) ([]core.RegistryBenchResult, error) {
+	seen := make(map[string]bool)
+	var candidates []core.RegistryMirror
+	for _, mirror := range mirrors {
+		if err := s.ValidateRegistryURL(mirror.URL); err != nil {
+			return nil, err
+		}
+		key := strings.TrimSuffix(mirror.URL, "/")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if mirror.Name == "" {
+			mirror.Name = mirror.URL
+		}
+		candidates = append(candidates, mirror)
+	}
+	
+	if len(candidates) == 0 {
+		return nil, core.NewValidationError("mirrors", "", "no registries to benchmark")
+	}
+	
+	opts := registryOptions(ctx, s.factory, managerName, "")
+	opts.Timeout = connectivityTimeout
+	
+	results := registry.Benchmark(ctx, candidates, rounds, opts)
+	
+	s.logger.WithField("candidates", len(candidates)).WithField("rounds", rounds).Info("Registry benchmark completed")
+	
+	return results, nil
 }
 
 // GetConfigSummary returns a summary of configuration across all managers
@@ -329,6 +1031,25 @@ func (s *ConfigService) ValidateRegistryURL(registryURL string) error {
 	return nil
 }
 
+// ValidateProxySettings checks the proxy URLs and bypass list of proxy settings
+func (s *ConfigService) ValidateProxySettings(proxy core.ProxySettings) error {
+	for _, proxyURL := range []string{proxy.HTTP, proxy.HTTPS} {
+		if proxyURL == "" {
+			continue
+		}
+		if err := s.ValidateProxyURL(proxyURL); err != nil {
+			return err
+		}
+	}
+
+	for _, host := range proxy.NoProxy {
+		if host == "" || strings.ContainsAny(host, "/ ,") {
+			return core.NewValidationError("noproxy", host, "bypass entries must be host names, domains or IP addresses")
+		}
+	}
+	return nil
+}
+
 // ValidateProxyURL validates a proxy URL
 func (s *ConfigService) ValidateProxyURL(proxyURL string) error {
 	if proxyURL == "" {
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"npm-console/internal/core"
+	"npm-console/internal/managers"
+	"npm-console/pkg/config"
+	"npm-console/pkg/utils"
+)
+
+// plannedChange is a change of a plan together with the manager it applies to
+type plannedChange struct {
+	core.ConfigPlanChange
+	manager core.PackageManager
+}
+
+// PlanConfig compares the desired config of every manager with its current
+// config and returns the changes ApplyConfig would make. Values are masked.
+func (s *ConfigService) PlanConfig(ctx context.Context, desired *config.ManagersConfig) (*core.ConfigPlan, error) {
+	changes, skipped, err := s.planConfig(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+	return newConfigPlan(changes, skipped), nil
+}
+
+// ApplyConfig converges every available manager to the desired config. The
+// config files are snapshotted first and restored if a change fails.
+func (s *ConfigService) ApplyConfig(ctx context.Context, desired *config.ManagersConfig) (*core.ConfigPlan, error) {
+	return s.applyConfig(ctx, desired, "apply team config")
+}
+
+// CheckDrift compares the registry, proxy, scopes and settings npm-console
+// stores for each enabled manager with what the manager actually uses
+func (s *ConfigService) CheckDrift(ctx context.Context, stored *config.ManagersConfig) (*core.ConfigDrift, error) {
+	changes, skipped, err := s.planConfig(ctx, enabledManagers(stored))
+	if err != nil {
+		return nil, err
+	}
+
+	plan := newConfigPlan(changes, skipped)
+	return &core.ConfigDrift{InSync: len(plan.Changes) == 0, Drift: plan.Changes, Skipped: plan.Skipped}, nil
+}
+
+// FixDrift sets every drifted key back to the value npm-console stores
+func (s *ConfigService) FixDrift(ctx context.Context, stored *config.ManagersConfig) (*core.ConfigPlan, error) {
+	return s.applyConfig(ctx, enabledManagers(stored), "fix config drift")
+}
+
+// applyConfig makes the changes planned for a desired config
+func (s *ConfigService) applyConfig(ctx context.Context, desired *config.ManagersConfig, reason string) (*core.ConfigPlan, error) {
+	changes, skipped, err := s.planConfig(ctx, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := newConfigPlan(changes, skipped)
+	if len(changes) == 0 {
+		return plan, nil
+	}
+
+	snapshot, err := s.snapshots.Take(reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot config before change: %w", err)
+	}
+	plan.Snapshot = snapshot.ID
+
+	for _, change := range changes {
+		if err := applyConfigChange(ctx, change); err != nil {
+			if _, restoreErr := s.snapshots.Restore(snapshot.ID); restoreErr != nil {
+				s.logger.WithError(restoreErr).Warn("Failed to restore config after a failed apply")
+				return nil, fmt.Errorf("failed to set %s for %s, restore it with config rollback %s: %w", change.Key, change.Manager, snapshot.ID, err)
+			}
+			return nil, fmt.Errorf("failed to set %s for %s, changes were rolled back: %w", change.Key, change.Manager, err)
+		}
+	}
+
+	s.logger.WithField("changes", len(changes)).WithField("snapshot", snapshot.ID).WithField("reason", reason).Info("Config applied")
+	return plan, nil
+}
+
+// planConfig returns the unmasked changes needed for each available manager
+// and the managers that were skipped because they are not available
+func (s *ConfigService) planConfig(ctx context.Context, desired *config.ManagersConfig) ([]plannedChange, []string, error) {
+	if desired == nil {
+		return nil, nil, core.NewValidationError("managers", "", "no desired config given")
+	}
+
+	var changes []plannedChange
+	var skipped []string
+	for _, name := range sortedManagerNames(s.factory.GetAllManagers()) {
+		want := desired.Get(name)
+		if want == nil || isEmptyManagerConfig(want) {
+			continue
+		}
+		if err := s.validateManagerConfig(want); err != nil {
+			return nil, nil, err
+		}
+
+		manager, err := s.factory.GetManager(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !manager.IsAvailable(ctx) {
+			skipped = append(skipped, name)
+			continue
+		}
+
+		explainer, ok := manager.(core.ConfigExplainer)
+		if !ok {
+			return nil, nil, core.NewManagerError(name, "plan config", core.ErrNotSupported)
+		}
+		entries, err := explainer.ExplainConfig(ctx, ".", nil)
+		if err != nil {
+			return nil, nil, core.NewManagerError(name, "plan config", err)
+		}
+		current := make(map[string]string, len(entries))
+		for _, entry := range entries {
+			current[entry.Key] = entry.Value
+		}
+		if noProxy, ok := current["noproxy"]; ok {
+			current["noproxy"] = strings.Join(core.ParseNoProxy(noProxy), ",")
+		}
+
+		wanted := desiredValues(want)
+		for _, key := range sortedKeys(wanted) {
+			if sameConfigValue(current[key], wanted[key]) {
+				continue
+			}
+			changes = append(changes, plannedChange{
+				ConfigPlanChange: core.ConfigPlanChange{
+					Manager: name,
+					Key:     key,
+					Current: current[key],
+					Desired: wanted[key],
+				},
+				manager: manager,
+			})
+		}
+	}
+
+	return changes, skipped, nil
+}
+
+// validateManagerConfig checks the URLs of a desired manager config and
+// rejects auth tokens, which must not be shared through a team file
+func (s *ConfigService) validateManagerConfig(want *config.ManagerConfig) error {
+	if want.Registry != "" {
+		if err := s.ValidateRegistryURL(want.Registry); err != nil {
+			return err
+		}
+	}
+	if err := s.ValidateProxySettings(core.ProxySettings{HTTP: want.Proxy, HTTPS: want.HTTPSProxy, NoProxy: want.NoProxy}); err != nil {
+		return err
+	}
+	for scope, registryURL := range want.Scopes {
+		if err := s.validateScopedRegistry(scope, registryURL); err != nil {
+			return err
+		}
+	}
+	for key := range want.Settings {
+		if utils.IsSecretKey(key) {
+			return core.NewValidationError("settings", key, "auth tokens cannot be set from a shared config, use registry auth set")
+		}
+	}
+	return nil
+}
+
+// desiredValues returns the config keys a desired manager config sets, named
+// the way the managers report them
+func desiredValues(want *config.ManagerConfig) map[string]string {
+	values := make(map[string]string)
+	for key, value := range want.Settings {
+		values[key] = value
+	}
+	if want.Registry != "" {
+		values["registry"] = want.Registry
+	}
+	if want.Proxy != "" {
+		values["proxy"] = want.Proxy
+		values["https-proxy"] = want.Proxy
+	}
+	if want.HTTPSProxy != "" {
+		values["https-proxy"] = want.HTTPSProxy
+	}
+	if len(want.NoProxy) > 0 {
+		values["noproxy"] = strings.Join(want.NoProxy, ",")
+	}
+	for scope, registryURL := range want.Scopes {
+		values[managers.NormalizeScope(scope)+":registry"] = registryURL
+	}
+	return values
+}
+
+// applyConfigChange makes one planned change through the manager
+func applyConfigChange(ctx context.Context, change plannedChange) error {
+	switch {
+	case change.Key == "registry":
+		return change.manager.SetRegistry(ctx, change.Desired)
+	case strings.HasPrefix(change.Key, "@") && strings.HasSuffix(change.Key, ":registry"):
+		scoped, ok := change.manager.(core.ScopedRegistryManager)
+		if !ok {
+			return core.NewManagerError(change.Manager, "set scoped registry", core.ErrNotSupported)
+		}
+		return scoped.SetScopedRegistry(ctx, strings.TrimSuffix(change.Key, ":registry"), change.Desired)
+	default:
+		setter, ok := change.manager.(core.ConfigSetter)
+		if !ok {
+			return core.NewManagerError(change.Manager, "set "+change.Key, core.ErrNotSupported)
+		}
+		return setter.SetConfigValue(ctx, change.Key, change.Desired)
+	}
+}
+
+// newConfigPlan returns the plan of a list of changes with masked values
+func newConfigPlan(changes []plannedChange, skipped []string) *core.ConfigPlan {
+	plan := &core.ConfigPlan{Changes: make([]core.ConfigPlanChange, 0, len(changes)), Skipped: skipped}
+	for _, change := range changes {
+		masked := change.ConfigPlanChange
+		masked.Current = redactValue(masked.Key, masked.Current)
+		masked.Desired = redactValue(masked.Key, masked.Desired)
+		plan.Changes = append(plan.Changes, masked)
+	}
+	return plan
+}
+
+// enabledManagers returns the stored config of the enabled managers only
+func enabledManagers(stored *config.ManagersConfig) *config.ManagersConfig {
+	if stored == nil {
+		return nil
+	}
+
+	enabled := &config.ManagersConfig{}
+	for _, name := range []string{"npm", "pnpm", "yarn", "bun"} {
+		if want := stored.Get(name); want.Enabled {
+			*enabled.Get(name) = *want
+		}
+	}
+	return enabled
+}
+
+// isEmptyManagerConfig reports whether a desired manager config changes nothing
+func isEmptyManagerConfig(want *config.ManagerConfig) bool {
+	return want.Registry == "" && want.Proxy == "" && want.HTTPSProxy == "" && len(want.NoProxy) == 0 &&
+		len(want.Scopes) == 0 && len(want.Settings) == 0
+}
+
+// sameConfigValue compares config values, ignoring a trailing slash on URLs
+func sameConfigValue(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"npm-console/internal/core"
+	"npm-console/pkg/config"
+)
+
+func TestDesiredValues(t *testing.T) {
+	want := &config.ManagerConfig{
+		Registry: "https://npm.corp.local/",
+		Scopes:   map[string]string{"corp": "https://npm.corp.local/corp/"},
+		Settings: map[string]string{"strict-ssl": "true", "save-exact": ""},
+	}
+
+	values := desiredValues(want)
+	expected := map[string]string{
+		"registry":       "https://npm.corp.local/",
+		"@corp:registry": "https://npm.corp.local/corp/",
+		"strict-ssl":     "true",
+		"save-exact":     "",
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("desiredValues() = %v, want %v", values, expected)
+	}
+	for key, value := range expected {
+		if got, ok := values[key]; !ok || got != value {
+			t.Errorf("desiredValues()[%s] = %q, want %q", key, got, value)
+		}
+	}
+
+	if !sameConfigValue("https://npm.corp.local", "https://npm.corp.local/") {
+		t.Error("a trailing slash should not be a change")
+	}
+}
+
+func TestValidateManagerConfig(t *testing.T) {
+	s := NewConfigService()
+
+	valid := &config.ManagerConfig{Registry: "https://npm.corp.local/", Scopes: map[string]string{"@corp": "https://npm.corp.local/"}}
+	if err := s.validateManagerConfig(valid); err != nil {
+		t.Errorf("validateManagerConfig() error = %v", err)
+	}
+
+	for name, want := range map[string]*config.ManagerConfig{
+		"registry": {Registry: "not a url"},
+		"scope":    {Scopes: map[string]string{"@corp/pkg": "https://npm.corp.local/"}},
+		"token":    {Settings: map[string]string{"//npm.corp.local/:_authToken": "secret"}},
+	} {
+		if err := s.validateManagerConfig(want); !core.IsValidationError(err) {
+			t.Errorf("%s: validateManagerConfig() error = %v, want a validation error", name, err)
+		}
+	}
+}
+
+func TestEnabledManagers(t *testing.T) {
+	stored := &config.ManagersConfig{
+		NPM:  config.ManagerConfig{Enabled: true, Registry: "https://npm.corp.local/"},
+		Yarn: config.ManagerConfig{Enabled: false, Registry: "https://yarn.corp.local/"},
+	}
+
+	enabled := enabledManagers(stored)
+	if enabled.NPM.Registry != "https://npm.corp.local/" {
+		t.Errorf("npm registry = %q, want the stored registry", enabled.NPM.Registry)
+	}
+	if !isEmptyManagerConfig(&enabled.Yarn) {
+		t.Errorf("disabled yarn should not be checked, got %+v", enabled.Yarn)
+	}
+}
+
+// explainingManager reports fixed config entries
+type explainingManager struct {
+	core.PackageManager
+	entries []core.ConfigEntry
+}
+
+func (m explainingManager) ExplainConfig(ctx context.Context, projectPath string, cli map[string]string) ([]core.ConfigEntry, error) {
+	return m.entries, nil
+}
+
+func TestUserRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	builtin := explainingManager{entries: []core.ConfigEntry{
+		{Key: "registry", Value: "https://registry.npmjs.org/", Layer: core.LayerBuiltin},
+	}}
+	if got, err := userRegistry(ctx, builtin); err != nil || got != "" {
+		t.Errorf("userRegistry() with only the default = %q, %v; want unset", got, err)
+	}
+
+	shadowed := explainingManager{entries: []core.ConfigEntry{
+		{Key: "registry", Value: "https://env.local/", Layer: core.LayerEnv, Shadowed: []core.ConfigSource{
+			{Layer: core.LayerUser, Value: "https://npm.corp.local/"},
+			{Layer: core.LayerBuiltin, Value: "https://registry.npmjs.org/"},
+		}},
+	}}
+	if got, err := userRegistry(ctx, shadowed); err != nil || got != "https://npm.corp.local/" {
+		t.Errorf("userRegistry() shadowed by env = %q, %v; want the user value", got, err)
+	}
+}
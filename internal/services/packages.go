@@ -9,7 +9,9 @@ import (
 
 	"npm-console/internal/core"
 	"npm-console/internal/managers"
+	"npm-console/internal/registry"
 	"npm-console/pkg/logger"
+	"npm-console/pkg/semver"
 	"npm-console/pkg/utils"
 )
 
@@ -35,6 +37,15 @@ func (s *PackageService) GetAllPackages(ctx context.Context, projectPath string)
 
 	availableManagers := s.factory.GetAvailableManagers(ctx)
 	
+	// The lockfile's manager can read installed packages without the tool itself
+	if _, lockManager := managers.FindLockfile(projectPath); lockManager != "" {
+		if _, ok := availableManagers[lockManager]; !ok {
+			if manager, err := s.factory.GetManager(lockManager); err == nil {
+				availableManagers[lockManager] = manager
+			}
+		}
+	}
+	
 	var allPackages []core.Package
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -161,14 +172,13 @@ func (s *PackageService) GetGlobalPackagesByManager(ctx context.Context, manager
 	return manager.GetGlobalPackages(ctx)
 }
 
-// SearchPackages searches for packages by name across all packages
+// SearchPackages searches globally installed packages by name or description.
+// Use SearchRegistry to search a registry.
 func (s *PackageService) SearchPackages(ctx context.Context, query string) ([]core.Package, error) {
 	if query == "" {
 		return nil, core.NewValidationError("query", query, "search query cannot be empty")
 	}
 	
-	// For now, we'll search through installed packages
-	// In the future, this could be extended to search online registries
 	globalPackages, err := s.GetGlobalPackages(ctx)
 	if err != nil {
 		return nil, err
@@ -187,30 +197,185 @@ func (s *PackageService) SearchPackages(ctx context.Context, query string) ([]co
 	return matchingPackages, nil
 }
 
-// GetPackageInfo returns detailed information about a specific package
-func (s *PackageService) GetPackageInfo(ctx context.Context, packageName string) (*core.PackageDetail, error) {
-	if packageName == "" {
-		return nil, core.NewValidationError("packageName", packageName, "package name cannot be empty")
+// SearchRegistry searches the registry configured for a manager through its
+// /-/v1/search endpoint
+func (s *PackageService) SearchRegistry(ctx context.Context, query string, opts SearchOptions) (*core.SearchResults, error) {
+	if query == "" {
+		return nil, core.NewValidationError("query", query, "search query cannot be empty")
 	}
 	
-	// Try to find the package in global packages first
-	globalPackages, err := s.GetGlobalPackages(ctx)
+	if opts.Manager == "" {
+		opts.Manager = "npm"
+	}
+	if opts.Size <= 0 {
+		opts.Size = 20
+	}
+	
+	client := newRegistryClient(ctx, s.factory, opts.Manager, "")
+	response, err := client.Search(ctx, query, registry.SearchOptions{
+		Size:        opts.Size,
+		From:        opts.From,
+		Quality:     opts.Quality,
+		Popularity:  opts.Popularity,
+		Maintenance: opts.Maintenance,
+	})
 	if err != nil {
 		return nil, err
 	}
 	
-	for _, pkg := range globalPackages {
-		if pkg.Name == packageName {
-			// Convert Package to PackageDetail
-			detail := &core.PackageDetail{
-				Package: pkg,
-				// Additional fields would be populated from package.json or registry
+	results := &core.SearchResults{
+		Query:    query,
+		Registry: client.BaseURL(),
+		Total:    response.Total,
+		From:     opts.From,
+		Size:     opts.Size,
+		Results:  make([]core.SearchResult, 0, len(response.Objects)),
+	}
+	
+	for _, object := range response.Objects {
+		pkg := object.Package
+		results.Results = append(results.Results, core.SearchResult{
+			Name:        pkg.Name,
+			Version:     pkg.Version,
+			Description: pkg.Description,
+			Keywords:    pkg.Keywords,
+			Publisher:   pkg.Publisher.Username,
+			Date:        pkg.Date,
+			Homepage:    pkg.Links["homepage"],
+			Repository:  pkg.Links["repository"],
+			Score: core.SearchScore{
+				Final:       object.Score.Final,
+				Quality:     object.Score.Detail.Quality,
+				Popularity:  object.Score.Detail.Popularity,
+				Maintenance: object.Score.Detail.Maintenance,
+			},
+		})
+	}
+	
+	return results, nil
+}
+
+// GetPackageInfo returns detailed information about a specific package
+func (s *PackageService) GetPackageInfo(ctx context.Context, packageName string) (*core.PackageDetail, error) {
+	return s.GetPackageInfoWithManager(ctx, packageName, "")
+}
+
+// GetPackageInfoWithManager returns detailed information about a package from
+// the registry configured for a manager. packageSpec may carry a version, tag
+// or range ("react@18", "@types/node@latest"); without one the globally
+// installed version is described, or the latest version if it isn't installed.
+func (s *PackageService) GetPackageInfoWithManager(ctx context.Context, packageSpec, managerName string) (*core.PackageDetail, error) {
+	if packageSpec == "" {
+		return nil, core.NewValidationError("packageName", packageSpec, "package name cannot be empty")
+	}
+	
+	packageName, version := splitPackageSpec(packageSpec)
+	
+	// A global installation tells us which version and manager to describe
+	var installed *core.Package
+	if globalPackages, err := s.GetGlobalPackages(ctx); err == nil {
+		for i, pkg := range globalPackages {
+			if pkg.Name == packageName && (managerName == "" || pkg.Manager == managerName) {
+				installed = &globalPackages[i]
+				break
 			}
-			return detail, nil
 		}
 	}
 	
-	return nil, core.ErrPackageNotFound
+	if managerName == "" {
+		managerName = "npm"
+		if installed != nil {
+			managerName = installed.Manager
+		}
+	}
+	if version == "" && installed != nil {
+		version = installed.Version
+	}
+	
+	client := newRegistryClient(ctx, s.factory, managerName, "")
+	packument, err := client.GetFullPackument(ctx, packageName)
+	if err != nil {
+		if installed == nil {
+			return nil, err
+		}
+		// Offline or private registry: fall back to what is installed
+		s.logger.WithError(err).WithField("package", packageName).Warn("Failed to fetch package from registry")
+		return &core.PackageDetail{Package: *installed}, nil
+	}
+	
+	manifest := packument.Manifest(version)
+	if manifest == nil && version != "" {
+		manifest = packument.Manifest(semver.MaxSatisfying(packument.VersionList(), version))
+	}
+	if manifest == nil {
+		return nil, core.ErrPackageNotFound
+	}
+	
+	detail := packageDetailFromManifest(packument, manifest)
+	detail.Manager = managerName
+	if installed != nil && installed.Version == manifest.Version {
+		detail.IsGlobal = true
+		detail.Path = installed.Path
+		detail.Size = installed.Size
+	}
+	
+	return detail, nil
+}
+
+// packageDetailFromManifest builds a PackageDetail from a registry manifest,
+// using the packument's top-level fields when the manifest lacks them
+func packageDetailFromManifest(packument *registry.Packument, manifest *registry.PackumentVersion) *core.PackageDetail {
+	detail := &core.PackageDetail{
+		Package: core.Package{
+			Name:            manifest.Name,
+			Version:         manifest.Version,
+			Description:     manifest.Description,
+			Size:            manifest.Dist.UnpackedSize,
+			Dependencies:    manifest.Dependencies,
+			DevDependencies: manifest.DevDependencies,
+		},
+		Author:           registry.PersonName(manifest.Author),
+		License:          registry.LicenseName(manifest.License),
+		Homepage:         manifest.Homepage,
+		Repository:       registry.RepositoryURL(manifest.Repository),
+		Keywords:         registry.Keywords(manifest.Keywords),
+		Scripts:          manifest.Scripts,
+		Engines:          registry.Engines(manifest.Engines),
+		PeerDependencies: manifest.PeerDependencies,
+	}
+	
+	if detail.Name == "" {
+		detail.Name = packument.Name
+	}
+	if detail.Description == "" {
+		detail.Description = packument.Description
+	}
+	if detail.Author == "" {
+		detail.Author = registry.PersonName(packument.Author)
+	}
+	if detail.License == "" {
+		detail.License = registry.LicenseName(packument.License)
+	}
+	if detail.Homepage == "" {
+		detail.Homepage = packument.Homepage
+	}
+	if detail.Repository == "" {
+		detail.Repository = registry.RepositoryURL(packument.Repository)
+	}
+	if len(detail.Keywords) == 0 {
+		detail.Keywords = packument.Keywords
+	}
+	
+	return detail
+}
+
+// splitPackageSpec splits "name@version" into its parts, keeping the leading
+// "@" of scoped package names
+func splitPackageSpec(spec string) (string, string) {
+	if idx := strings.LastIndex(spec, "@"); idx > 0 {
+		return spec[:idx], spec[idx+1:]
+	}
+	return spec, ""
 }
 
 // GetPackageStats returns statistics about packages
@@ -295,6 +460,16 @@ type PackageStats struct {
 	ByManager      map[string]int `json:"by_manager"`
 }
 
+// SearchOptions controls a registry search
+type SearchOptions struct {
+	Manager     string  `json:"manager"`     // manager whose registry is searched
+	From        int     `json:"from"`        // offset of the first result
+	Size        int     `json:"size"`        // number of results per page
+	Quality     float64 `json:"quality"`     // ranking weights, 0 to 1
+	Popularity  float64 `json:"popularity"`
+	Maintenance float64 `json:"maintenance"`
+}
+
 // InstallPackage installs a package using the specified manager
 func (s *PackageService) InstallPackage(ctx context.Context, packageName, managerName string, global bool) error {
 	if packageName == "" {
@@ -3,22 +3,29 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 
+	"npm-console/internal/audit"
 	"npm-console/internal/core"
 	"npm-console/internal/managers"
+	"npm-console/internal/registry"
 	"npm-console/pkg/logger"
+	"npm-console/pkg/semver"
 	"npm-console/pkg/utils"
 )
 
 // ProjectService implements project management functionality
 type ProjectService struct {
-	factory *managers.ManagerFactory
-	logger  *logger.Logger
+	factory    *managers.ManagerFactory
+	logger     *logger.Logger
+	advisoryDB string
+	offline    bool
 }
 
 // NewProjectService creates a new project service
@@ -29,6 +36,18 @@ func NewProjectService() *ProjectService {
 	}
 }
 
+// SetAdvisoryDatabase sets the advisory database (file, directory or mirror URL)
+// used to fill in vulnerabilities when analyzing projects
+func (s *ProjectService) SetAdvisoryDatabase(source string) {
+	s.advisoryDB = source
+}
+
+// SetOffline stops project analysis from querying the registry for outdated
+// packages
+func (s *ProjectService) SetOffline(offline bool) {
+	s.offline = offline
+}
+
 // ScanProjects scans for projects using any package manager in the given root path
 func (s *ProjectService) ScanProjects(ctx context.Context, rootPath string) ([]core.Project, error) {
 	if rootPath == "" {
@@ -170,11 +189,33 @@ func (s *ProjectService) AnalyzeProject(ctx context.Context, projectPath string)
 		PackageCount:     len(packages),
 		DevPackageCount:  devPackageCount,
 		TotalSize:        totalSize,
-		OutdatedPackages: []core.Package{}, // TODO: Implement outdated package detection
-		Vulnerabilities:  []core.Vulnerability{}, // TODO: Implement vulnerability scanning
+		OutdatedPackages: []core.OutdatedPackage{},
+		Vulnerabilities:  []core.Vulnerability{},
 		Scripts:          packageJson.Scripts,
 	}
 	
+	// Outdated detection needs the registry, so a failure only leaves the list empty
+	if !s.offline {
+		outdated, err := s.GetOutdatedPackages(ctx, expandedPath)
+		if err != nil {
+			s.logger.WithError(err).Warn("Failed to check for outdated packages")
+		} else {
+			analysis.OutdatedPackages = outdated
+		}
+	}
+	
+	// Vulnerabilities are only reported when an advisory database is available
+	if s.advisoryDB != "" && (utils.PathExists(s.advisoryDB) || strings.HasPrefix(s.advisoryDB, "http")) {
+		db, err := audit.Load(ctx, s.advisoryDB)
+		if err != nil {
+			s.logger.WithError(err).Warn("Failed to load advisory database")
+		} else if vulnerabilities, err := s.AuditProject(ctx, expandedPath, db); err != nil {
+			s.logger.WithError(err).Warn("Failed to audit project")
+		} else {
+			analysis.Vulnerabilities = vulnerabilities
+		}
+	}
+	
 	// Set lock file based on detected managers
 	for _, manager := range managers {
 		switch manager {
@@ -197,10 +238,12 @@ func (s *ProjectService) AnalyzeProject(ctx context.Context, projectPath string)
 				break
 			}
 		case "bun":
-			lockFile := filepath.Join(expandedPath, "bun.lockb")
-			if utils.IsFile(lockFile) {
-				analysis.LockFile = lockFile
-				break
+			for _, name := range []string{"bun.lock", "bun.lockb"} {
+				lockFile := filepath.Join(expandedPath, name)
+				if utils.IsFile(lockFile) {
+					analysis.LockFile = lockFile
+					break
+				}
 			}
 		}
 	}
@@ -208,14 +251,19 @@ func (s *ProjectService) AnalyzeProject(ctx context.Context, projectPath string)
 	return analysis, nil
 }
 
-// GetProjectDependencies returns the dependency tree for a project
+// GetProjectDependencies returns the full dependency tree for a project
 func (s *ProjectService) GetProjectDependencies(ctx context.Context, projectPath string) (*core.DependencyTree, error) {
+	return s.GetProjectDependenciesWithDepth(ctx, projectPath, 0)
+}
+
+// GetProjectDependenciesWithDepth returns the dependency tree for a project, expanded
+// at most maxDepth levels below the project (0 = unlimited). Versions are resolved
+// from the project's lockfile; without one only the package.json ranges are known.
+func (s *ProjectService) GetProjectDependenciesWithDepth(ctx context.Context, projectPath string, maxDepth int) (*core.DependencyTree, error) {
 	if projectPath == "" {
 		return nil, core.NewValidationError("projectPath", projectPath, "project path cannot be empty")
 	}
 	
-	// For now, return a basic dependency tree based on package.json
-	// In the future, this could be enhanced to build a full dependency tree
 	packageJsonPath := filepath.Join(projectPath, "package.json")
 	if !utils.IsFile(packageJsonPath) {
 		return nil, core.ErrProjectNotFound
@@ -233,11 +281,67 @@ func (s *ProjectService) GetProjectDependencies(ctx context.Context, projectPath
 		Depth:   0,
 	}
 	
+	lockfile, err := managers.LoadLockfile(projectPath)
+	if err != nil {
+		if !errors.Is(err, core.ErrLockfileNotFound) {
+			s.logger.WithError(err).WithField("project", projectPath).Warn("Failed to parse lockfile, using package.json ranges")
+		}
+		s.addManifestDependencies(root, packageJson)
+		return root, nil
+	}
+	
+	// In a workspace the lockfile lives at the root and lists every member project
+	importer := lockfile.Importer(projectPath)
+	if importer == nil {
+		s.addManifestDependencies(root, packageJson)
+		return root, nil
+	}
+	
+	builder := &dependencyTreeBuilder{
+		lockfile: lockfile,
+		maxDepth: maxDepth,
+		expanded: make(map[string]int),
+	}
+	
+	sections := []struct {
+		declared map[string]string
+		resolved map[string]string
+		dev      bool
+	}{
+		{packageJson.Dependencies, importer.Dependencies, false},
+		{packageJson.OptionalDependencies, importer.OptionalDependencies, false},
+		{packageJson.DevDependencies, importer.DevDependencies, true},
+	}
+	
+	for _, section := range sections {
+		for _, name := range sortedKeys(section.declared) {
+			id, ok := section.resolved[name]
+			if !ok || lockfile.Package(id) == nil {
+				root.Dependencies = append(root.Dependencies, &core.DependencyTree{
+					Name:          name,
+					Version:       section.declared[name],
+					DevDependency: section.dev,
+					Depth:         1,
+					Missing:       true,
+				})
+				continue
+			}
+			root.Dependencies = append(root.Dependencies, builder.build(name, id, 1, section.dev))
+		}
+	}
+	
+	s.logger.WithField("lockfile", lockfile.Path).WithField("packages", len(lockfile.Packages)).Debug("Built dependency tree from lockfile")
+	
+	return root, nil
+}
+
+// addManifestDependencies adds the direct dependencies declared in package.json
+func (s *ProjectService) addManifestDependencies(root *core.DependencyTree, packageJson *PackageJsonInfo) {
 	// Add direct dependencies
-	for name, version := range packageJson.Dependencies {
+	for _, name := range sortedKeys(packageJson.Dependencies) {
 		dep := &core.DependencyTree{
 			Name:          name,
-			Version:       version,
+			Version:       packageJson.Dependencies[name],
 			DevDependency: false,
 			Depth:         1,
 		}
@@ -245,17 +349,15 @@ func (s *ProjectService) GetProjectDependencies(ctx context.Context, projectPath
 	}
 	
 	// Add dev dependencies
-	for name, version := range packageJson.DevDependencies {
+	for _, name := range sortedKeys(packageJson.DevDependencies) {
 		dep := &core.DependencyTree{
 			Name:          name,
-			Version:       version,
+			Version:       packageJson.DevDependencies[name],
 			DevDependency: true,
 			Depth:         1,
 		}
 		root.Dependencies = append(root.Dependencies, dep)
 	}
-	
-	return root, nil
 }
 
 // GetProjectStats returns statistics about scanned projects
@@ -324,6 +426,322 @@ func (s *ProjectService) mergeProjects(projects []core.Project) []core.Project {
 	return merged
 }
 
+// outdatedConcurrency limits parallel packument requests during outdated checks
+const outdatedConcurrency = 8
+
+// GetOutdatedPackages compares the project's direct dependencies with the registry of
+// each package manager the project uses. Current versions come from that manager's
+// lockfile (or node_modules), wanted is the highest version satisfying the
+// package.json range and latest is the "latest" dist-tag.
+func (s *ProjectService) GetOutdatedPackages(ctx context.Context, projectPath string) ([]core.OutdatedPackage, error) {
+	if projectPath == "" {
+		return nil, core.NewValidationError("projectPath", projectPath, "project path cannot be empty")
+	}
+
+	packageJsonPath := filepath.Join(projectPath, "package.json")
+	if !utils.IsFile(packageJsonPath) {
+		return nil, core.ErrProjectNotFound
+	}
+
+	packageJson, err := s.readPackageJson(packageJsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+
+	var outdated []core.OutdatedPackage
+	var failures []error
+	var checked int
+	for _, source := range s.lockSources(projectPath) {
+		found, errs, n := s.checkOutdated(ctx, projectPath, packageJson, source)
+		outdated = append(outdated, found...)
+		failures = append(failures, errs...)
+		checked += n
+	}
+
+	if len(failures) > 0 {
+		s.logger.WithError(failures[0]).WithField("errors", len(failures)).Warn("Some packages could not be checked")
+		if len(outdated) == 0 && len(failures) == checked {
+			return nil, fmt.Errorf("failed to check outdated packages: %w", failures[0])
+		}
+	}
+
+	sort.Slice(outdated, func(i, j int) bool {
+		if outdated[i].Name != outdated[j].Name {
+			return outdated[i].Name < outdated[j].Name
+		}
+		return outdated[i].Manager < outdated[j].Manager
+	})
+
+	return outdated, nil
+}
+
+// lockSource is where one package manager recorded the versions installed in a
+// project; without a lockfile importer they are read from node_modules
+type lockSource struct {
+	manager  string
+	lockfile *managers.Lockfile
+	importer *managers.LockImporter
+}
+
+// lockSources returns one source per package manager the project uses: each
+// lockfile in the project, else the workspace lockfile above it, else the
+// detected manager with the versions installed in node_modules
+func (s *ProjectService) lockSources(projectPath string) []lockSource {
+	var sources []lockSource
+	found := managers.FindLockfiles(projectPath)
+	for _, manager := range sortedKeys(found) {
+		source := lockSource{manager: manager}
+		if lockfile, err := managers.ParseLockfile(found[manager], manager); err != nil {
+			s.logger.WithError(err).WithField("lockfile", found[manager]).Warn("Failed to parse lockfile, reading versions from node_modules")
+		} else {
+			source.lockfile = lockfile
+			source.importer = lockfile.Importer(projectPath)
+		}
+		sources = append(sources, source)
+	}
+	if len(sources) > 0 {
+		return sources
+	}
+
+	lockfile, err := managers.LoadLockfile(projectPath)
+	if err == nil {
+		return []lockSource{{manager: lockfile.Manager, lockfile: lockfile, importer: lockfile.Importer(projectPath)}}
+	}
+	if !errors.Is(err, core.ErrLockfileNotFound) {
+		s.logger.WithError(err).WithField("project", projectPath).Warn("Failed to parse lockfile, reading versions from node_modules")
+	}
+
+	manager := "npm"
+	if detected := s.detectProjectManagers(projectPath); len(detected) > 0 {
+		manager = detected[0]
+	}
+	return []lockSource{{manager: manager}}
+}
+
+// checkOutdated checks the direct dependencies of a project against the registry
+// of one package manager. It returns the outdated packages, the lookups that
+// failed and how many packages were looked up.
+func (s *ProjectService) checkOutdated(ctx context.Context, projectPath string, packageJson *PackageJsonInfo, source lockSource) ([]core.OutdatedPackage, []error, int) {
+	client := newRegistryClient(ctx, s.factory, source.manager, projectPath)
+
+	type dependency struct {
+		name    string
+		spec    string
+		depType string
+		current string
+	}
+
+	var deps []dependency
+	sections := []struct {
+		declared map[string]string
+		depType  string
+	}{
+		{packageJson.Dependencies, "dependencies"},
+		{packageJson.OptionalDependencies, "optionalDependencies"},
+		{packageJson.DevDependencies, "devDependencies"},
+	}
+	for _, section := range sections {
+		for _, name := range sortedKeys(section.declared) {
+			dep := dependency{name: name, spec: section.declared[name], depType: section.depType}
+			if source.importer != nil {
+				dep.current = lockedVersion(source.lockfile, source.importer, name)
+			} else {
+				dep.current = installedVersion(projectPath, name)
+			}
+			deps = append(deps, dep)
+		}
+	}
+
+	var outdated []core.OutdatedPackage
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs []error
+	var checked int
+	semaphore := make(chan struct{}, outdatedConcurrency)
+
+	for _, dep := range deps {
+		// Aliases ("npm:other@^1.0.0") are looked up under the real package name
+		fetchName, spec := dep.name, dep.spec
+		if strings.HasPrefix(spec, "npm:") {
+			fetchName, spec = splitAliasSpec(strings.TrimPrefix(spec, "npm:"))
+		}
+		if !isRegistrySpec(spec) {
+			continue
+		}
+
+		checked++
+		wg.Add(1)
+		go func(dep dependency, fetchName, spec string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			packument, err := client.GetPackument(ctx, fetchName)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", fetchName, err))
+				mu.Unlock()
+				return
+			}
+
+			latest := packument.DistTags["latest"]
+			wanted := wantedVersion(packument, spec)
+			if dep.current != "" && dep.current == wanted && dep.current == latest {
+				return
+			}
+
+			mu.Lock()
+			outdated = append(outdated, core.OutdatedPackage{
+				Name:           dep.name,
+				Current:        dep.current,
+				Wanted:         wanted,
+				Latest:         latest,
+				Range:          dep.spec,
+				DependencyType: dep.depType,
+				Manager:        source.manager,
+				Project:        projectPath,
+				DistTags:       packument.DistTags,
+			})
+			mu.Unlock()
+		}(dep, fetchName, spec)
+	}
+
+	wg.Wait()
+
+	return outdated, errs, checked
+}
+
+// AuditProject matches every package resolved in the project's lockfile against
+// the advisory database. Only packages reachable from the project are checked,
+// so in a workspace each member is audited on its own.
+func (s *ProjectService) AuditProject(ctx context.Context, projectPath string, db *audit.Database) ([]core.Vulnerability, error) {
+	if projectPath == "" {
+		return nil, core.NewValidationError("projectPath", projectPath, "project path cannot be empty")
+	}
+
+	if !utils.IsFile(filepath.Join(projectPath, "package.json")) {
+		return nil, core.ErrProjectNotFound
+	}
+
+	lockfile, err := managers.LoadLockfile(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lockfile: %w", err)
+	}
+
+	importer := lockfile.Importer(projectPath)
+	if importer == nil {
+		return nil, fmt.Errorf("project %s is not part of %s", projectPath, lockfile.Path)
+	}
+
+	// Walk the resolved graph from the project's direct dependencies
+	visited := make(map[string]bool)
+	var queue []string
+	for _, deps := range []map[string]string{importer.Dependencies, importer.OptionalDependencies, importer.DevDependencies} {
+		for _, id := range deps {
+			queue = append(queue, id)
+		}
+	}
+
+	vulnerabilities := []core.Vulnerability{}
+	reported := make(map[string]bool)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		pkg := lockfile.Package(id)
+		if pkg == nil {
+			continue
+		}
+		for _, depID := range pkg.Dependencies {
+			queue = append(queue, depID)
+		}
+
+		for _, vuln := range db.Match(pkg.Name, pkg.Version) {
+			// The same version may be installed at several places in the tree
+			key := vuln.ID + "|" + vuln.Package + "@" + vuln.Version
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			vulnerabilities = append(vulnerabilities, vuln)
+		}
+	}
+
+	audit.SortVulnerabilities(vulnerabilities)
+
+	s.logger.WithField("packages", len(visited)).WithField("vulnerabilities", len(vulnerabilities)).Debug("Audited project")
+
+	return vulnerabilities, nil
+}
+
+// lockedVersion returns the locked version of a direct dependency of an importer
+func lockedVersion(lockfile *managers.Lockfile, importer *managers.LockImporter, name string) string {
+	for _, deps := range []map[string]string{importer.Dependencies, importer.OptionalDependencies, importer.DevDependencies} {
+		if id, ok := deps[name]; ok {
+			if pkg := lockfile.Package(id); pkg != nil {
+				return pkg.Version
+			}
+		}
+	}
+	return ""
+}
+
+// installedVersion reads the version of a package from the project's node_modules
+func installedVersion(projectPath, name string) string {
+	data, err := os.ReadFile(filepath.Join(projectPath, "node_modules", filepath.FromSlash(name), "package.json"))
+	if err != nil {
+		return ""
+	}
+
+	var manifest struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return ""
+	}
+	return manifest.Version
+}
+
+// wantedVersion returns the version a package.json spec resolves to. Like npm, the
+// "latest" dist-tag is preferred when it satisfies the range.
+func wantedVersion(packument *registry.Packument, spec string) string {
+	rng, err := semver.ParseRange(spec)
+	if err != nil {
+		// Not a range, so it may name a dist-tag such as "next"
+		return packument.DistTags[spec]
+	}
+
+	if latest, err := semver.Parse(packument.DistTags["latest"]); err == nil && rng.Contains(latest) {
+		return packument.DistTags["latest"]
+	}
+	return semver.MaxSatisfying(packument.VersionList(), spec)
+}
+
+// splitAliasSpec splits the "name@range" part of an npm: alias
+func splitAliasSpec(spec string) (string, string) {
+	idx := strings.LastIndex(spec, "@")
+	if idx <= 0 {
+		return spec, "latest"
+	}
+	return spec[:idx], spec[idx+1:]
+}
+
+// isRegistrySpec reports whether a package.json spec is resolved from the registry
+// rather than from git, a URL, a local path or the workspace
+func isRegistrySpec(spec string) bool {
+	for _, prefix := range []string{"workspace:", "file:", "link:", "portal:", "patch:", "git", "http:", "https:", "github:"} {
+		if strings.HasPrefix(spec, prefix) {
+			return false
+		}
+	}
+	// GitHub shorthand ("user/repo") and relative paths
+	return !strings.Contains(spec, "/")
+}
+
 // detectProjectManagers detects which package managers are used in a project
 func (s *ProjectService) detectProjectManagers(projectPath string) []string {
 	var managers []string
@@ -343,8 +761,8 @@ func (s *ProjectService) detectProjectManagers(projectPath string) []string {
 		managers = append(managers, "yarn")
 	}
 	
-	// Check for bun (bun.lockb)
-	if utils.IsFile(filepath.Join(projectPath, "bun.lockb")) {
+	// Check for bun (bun.lock, or the older binary bun.lockb)
+	if utils.IsFile(filepath.Join(projectPath, "bun.lock")) || utils.IsFile(filepath.Join(projectPath, "bun.lockb")) {
 		managers = append(managers, "bun")
 	}
 	
@@ -371,6 +789,58 @@ func (s *ProjectService) readPackageJson(packageJsonPath string) (*PackageJsonIn
 	return &packageJson, nil
 }
 
+// dependencyTreeBuilder expands lockfile packages into dependency tree nodes
+type dependencyTreeBuilder struct {
+	lockfile *managers.Lockfile
+	maxDepth int
+	expanded map[string]int // depth each package id was expanded at
+}
+
+// build creates the node for the locked package id installed under name. Like
+// `npm ls`, a package whose children were already expanded elsewhere in the tree
+// is marked as deduped instead of being expanded again, which also breaks cycles.
+// With a depth limit, an expansion cut short deeper in the tree does not count,
+// so a shallower occurrence is expanded again.
+func (b *dependencyTreeBuilder) build(name, id string, depth int, dev bool) *core.DependencyTree {
+	pkg := b.lockfile.Package(id)
+	node := &core.DependencyTree{
+		Name:          name,
+		Version:       pkg.Version,
+		DevDependency: dev,
+		Depth:         depth,
+	}
+	
+	if len(pkg.Dependencies) == 0 || (b.maxDepth > 0 && depth >= b.maxDepth) {
+		return node
+	}
+	
+	if at, ok := b.expanded[id]; ok && (b.maxDepth == 0 || at <= depth) {
+		node.Deduped = true
+		return node
+	}
+	b.expanded[id] = depth
+	
+	for _, depName := range sortedKeys(pkg.Dependencies) {
+		depID := pkg.Dependencies[depName]
+		if b.lockfile.Package(depID) == nil {
+			continue
+		}
+		node.Dependencies = append(node.Dependencies, b.build(depName, depID, depth+1, dev))
+	}
+	
+	return node
+}
+
+// sortedKeys returns the keys of a string map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // PackageJsonInfo represents relevant information from package.json
 type PackageJsonInfo struct {
 	Name            string            `json:"name"`
@@ -378,6 +848,7 @@ type PackageJsonInfo struct {
 	Description     string            `json:"description"`
 	Dependencies    map[string]string `json:"dependencies"`
 	DevDependencies map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
 	Scripts         map[string]string `json:"scripts"`
 }
 
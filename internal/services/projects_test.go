@@ -0,0 +1,71 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"npm-console/internal/managers"
+)
+
+func TestDependencyTreeBuilderMaxDepth(t *testing.T) {
+	// a -> b -> c -> d, and the root also depends on b directly. With a limit
+	// of 3, b is first expanded at depth 2 through a, where c is cut off, then
+	// reached at depth 1 where c and d must still appear.
+	lockfile := &managers.Lockfile{Packages: map[string]*managers.LockedPackage{
+		"a": {Name: "a", Version: "1.0.0", Dependencies: map[string]string{"b": "b"}},
+		"b": {Name: "b", Version: "1.0.0", Dependencies: map[string]string{"c": "c"}},
+		"c": {Name: "c", Version: "1.0.0", Dependencies: map[string]string{"d": "d"}},
+		"d": {Name: "d", Version: "1.0.0", Dependencies: map[string]string{"e": "e"}},
+		"e": {Name: "e", Version: "1.0.0"},
+	}}
+	builder := &dependencyTreeBuilder{lockfile: lockfile, maxDepth: 3, expanded: make(map[string]int)}
+
+	a := builder.build("a", "a", 1, false)
+	if c := a.Dependencies[0].Dependencies[0]; c.Name != "c" || len(c.Dependencies) != 0 {
+		t.Fatalf("c under a, b = %+v, want it cut off at the depth limit", c)
+	}
+
+	b := builder.build("b", "b", 1, false)
+	if b.Deduped || len(b.Dependencies) != 1 {
+		t.Fatalf("b at depth 1 = %+v, want it expanded again", b)
+	}
+	if c := b.Dependencies[0]; len(c.Dependencies) != 1 || c.Dependencies[0].Name != "d" {
+		t.Errorf("c under b = %+v, want it expanded to d", c)
+	}
+
+	// Once expanded at depth 1, deeper occurrences are deduped
+	if again := builder.build("b", "b", 2, false); !again.Deduped {
+		t.Errorf("b at depth 2 = %+v, want it deduped", again)
+	}
+
+	// Without a limit every package is expanded once
+	builder = &dependencyTreeBuilder{lockfile: lockfile, expanded: make(map[string]int)}
+	builder.build("a", "a", 1, false)
+	if b := builder.build("b", "b", 1, false); !b.Deduped {
+		t.Errorf("b without a depth limit = %+v, want it deduped", b)
+	}
+}
+
+func TestLockSourcesEveryManager(t *testing.T) {
+	project := t.TempDir()
+	os.WriteFile(filepath.Join(project, "package.json"), []byte(`{"name":"app","dependencies":{"lodash":"^4.17.0"}}`), 0644)
+	os.WriteFile(filepath.Join(project, "package-lock.json"), []byte(`{"lockfileVersion":3,"packages":{"":{"name":"app","dependencies":{"lodash":"^4.17.0"}},"node_modules/lodash":{"version":"4.17.20"}}}`), 0644)
+	os.WriteFile(filepath.Join(project, "yarn.lock"), []byte("# yarn lockfile v1\n\nlodash@^4.17.0:\n  version \"4.17.21\"\n"), 0644)
+
+	sources := NewProjectService().lockSources(project)
+	if len(sources) != 2 || sources[0].manager != "npm" || sources[1].manager != "yarn" {
+		t.Fatalf("lockSources() = %+v, want npm and yarn", sources)
+	}
+	for _, source := range sources {
+		if source.importer == nil {
+			t.Errorf("%s source has no importer", source.manager)
+		}
+	}
+	if got := lockedVersion(sources[0].lockfile, sources[0].importer, "lodash"); got != "4.17.20" {
+		t.Errorf("npm lodash = %q, want 4.17.20", got)
+	}
+	if got := lockedVersion(sources[1].lockfile, sources[1].importer, "lodash"); got != "4.17.21" {
+		t.Errorf("yarn lodash = %q, want 4.17.21", got)
+	}
+}
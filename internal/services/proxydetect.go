@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"npm-console/internal/core"
+	"npm-console/internal/pac"
+	"npm-console/internal/registry"
+	"npm-console/pkg/utils"
+)
+
+// SourceEnvironment is the ProxyDetection source when the proxies come from
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+const SourceEnvironment = "environment"
+
+// DetectProxy works out the proxy settings each available manager, or only
+// the given one, should use. With a PAC file they follow the proxies the
+// file picks for the manager's registries; without one they come from the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables. Nothing is
+// changed; proxy URLs are masked.
+func (s *ConfigService) DetectProxy(ctx context.Context, managerName string, pacFile string) (*core.ProxyDetection, error) {
+	detection, err := s.detectProxy(ctx, managerName, pacFile)
+	if err != nil {
+		return nil, err
+	}
+	return redactDetection(detection), nil
+}
+
+// ApplyDetectedProxy detects proxy settings like DetectProxy and sets them,
+// through SetProxy, for every manager whose settings differ
+func (s *ConfigService) ApplyDetectedProxy(ctx context.Context, managerName string, pacFile string) (*core.ProxyDetection, error) {
+	detection, err := s.detectProxy(ctx, managerName, pacFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range detection.Proposals {
+		proposal := &detection.Proposals[i]
+		if !proposal.Changed {
+			continue
+		}
+		if err := s.SetProxy(ctx, proposal.Manager, proposal.Proposed); err != nil {
+			return nil, fmt.Errorf("failed to set detected proxy for %s: %w", proposal.Manager, err)
+		}
+		proposal.Applied = true
+	}
+
+	return redactDetection(detection), nil
+}
+
+// detectProxy builds the unmasked proxy proposals
+func (s *ConfigService) detectProxy(ctx context.Context, managerName string, pacFile string) (*core.ProxyDetection, error) {
+	detection := &core.ProxyDetection{Source: SourceEnvironment, Environment: environmentProxy()}
+
+	var script *pac.Script
+	if pacFile != "" {
+		var err error
+		if script, err = pac.ParseFile(pacFile); err != nil {
+			return nil, core.NewValidationError("pac", pacFile, err.Error())
+		}
+		detection.Source = pacFile
+	} else if detection.Environment.HTTP == "" && detection.Environment.HTTPS == "" {
+		return nil, core.NewValidationError("proxy", "", "no proxy found: HTTP_PROXY and HTTPS_PROXY are not set and no PAC file was given")
+	}
+
+	names := []string{managerName}
+	if managerName == "" {
+		names = sortedManagerNames(s.factory.GetAvailableManagers(ctx))
+	}
+
+	for _, name := range names {
+		manager, err := s.factory.GetManager(name)
+		if err != nil {
+			return nil, err
+		}
+		if !manager.IsAvailable(ctx) {
+			return nil, core.NewManagerError(name, "detect proxy", core.ErrManagerNotAvailable)
+		}
+
+		config, err := manager.GetConfig(ctx)
+		if err != nil {
+			return nil, core.NewManagerError(name, "detect proxy", err)
+		}
+
+		proposal := core.ProxyProposal{
+			Manager:  name,
+			Current:  core.ProxySettings{HTTP: config.Proxy, HTTPS: config.HTTPSProxy, NoProxy: config.NoProxy},
+			Proposed: detection.Environment,
+		}
+		if script != nil {
+			if err := proposePACProxy(script, pacRegistries(ctx, manager, config.Registry), &proposal); err != nil {
+				return nil, core.NewValidationError("pac", pacFile, err.Error())
+			}
+		}
+		proposal.Changed = !sameProxySettings(proposal.Current, proposal.Proposed)
+
+		detection.Proposals = append(detection.Proposals, proposal)
+	}
+
+	return detection, nil
+}
+
+// environmentProxy reads the proxy environment variables, upper case first.
+// HTTPS_PROXY defaults to HTTP_PROXY as npm's https-proxy does.
+func environmentProxy() core.ProxySettings {
+	getenv := func(names ...string) string {
+		for _, name := range names {
+			if value := os.Getenv(name); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	proxy := core.ProxySettings{
+		HTTP:    getenv("HTTP_PROXY", "http_proxy"),
+		HTTPS:   getenv("HTTPS_PROXY", "https_proxy"),
+		NoProxy: core.ParseNoProxy(getenv("NO_PROXY", "no_proxy")),
+	}
+	if proxy.HTTPS == "" {
+		proxy.HTTPS = proxy.HTTP
+	}
+	return proxy
+}
+
+// pacRegistries returns the registries a PAC file is evaluated for: the
+// manager's default registry, then its scoped registries
+func pacRegistries(ctx context.Context, manager core.PackageManager, registryURL string) []string {
+	if !strings.HasPrefix(registryURL, "http") {
+		registryURL = registry.DefaultRegistry
+	}
+	registries := []string{registryURL}
+	if scoped, ok := manager.(core.ScopedRegistryManager); ok {
+		if scopes, err := scoped.GetScopedRegistries(ctx); err == nil {
+			for _, scope := range sortedKeys(scopes) {
+				registries = append(registries, scopes[scope])
+			}
+		}
+	}
+	return registries
+}
+
+// proposePACProxy evaluates the PAC file for each registry. Package managers
+// take a single proxy and a bypass list, so the first proxy picked is used
+// for every registry and the registries the file sends DIRECT are added to
+// the bypass list.
+func proposePACProxy(script *pac.Script, registries []string, proposal *core.ProxyProposal) error {
+	proposal.Proposed = core.ProxySettings{}
+	seen := make(map[string]bool)
+	for _, registryURL := range registries {
+		if seen[registryURL] {
+			continue
+		}
+		seen[registryURL] = true
+
+		result, err := script.FindProxyForURL(registryURL)
+		if err != nil {
+			return fmt.Errorf("FindProxyForURL(%s): %w", utils.RedactURL(registryURL), err)
+		}
+		route := core.ProxyRoute{Registry: registryURL, Result: result}
+		route.Proxy, err = pacProxy(result)
+		if err != nil {
+			proposal.Warnings = append(proposal.Warnings, fmt.Sprintf("%s: %v", utils.RedactURL(registryURL), err))
+			continue
+		}
+		proposal.Routes = append(proposal.Routes, route)
+
+		if route.Proxy != "" && proposal.Proposed.HTTP == "" {
+			proposal.Proposed.HTTP = route.Proxy
+			proposal.Proposed.HTTPS = route.Proxy
+		}
+	}
+
+	for _, route := range proposal.Routes {
+		switch {
+		case route.Proxy == "":
+			if proposal.Proposed.HTTP == "" {
+				continue
+			}
+			if u, err := url.Parse(route.Registry); err == nil && !containsString(proposal.Proposed.NoProxy, u.Hostname()) {
+				proposal.Proposed.NoProxy = append(proposal.Proposed.NoProxy, u.Hostname())
+			}
+		case route.Proxy != proposal.Proposed.HTTP:
+			proposal.Warnings = append(proposal.Warnings, fmt.Sprintf("%s should use %s, but %s can only use one proxy and will use %s",
+				utils.RedactURL(route.Registry), utils.RedactURL(route.Proxy), proposal.Manager, utils.RedactURL(proposal.Proposed.HTTP)))
+		}
+	}
+
+	return nil
+}
+
+// pacProxy returns the proxy URL for the first directive of a PAC result a
+// package manager can follow, or "" when the first such directive is DIRECT
+func pacProxy(result string) (string, error) {
+	for _, directive := range pac.ParseResult(result) {
+		if directive.Type == "DIRECT" {
+			return "", nil
+		}
+		if proxyURL := directive.ProxyURL(); proxyURL != "" {
+			return proxyURL, nil
+		}
+	}
+	return "", fmt.Errorf("no usable proxy in %q, package managers do not support SOCKS proxies", result)
+}
+
+// sameProxySettings compares proxy settings, ignoring a trailing slash on the
+// proxy URLs and the order of the bypass list
+func sameProxySettings(a, b core.ProxySettings) bool {
+	if !sameConfigValue(a.HTTP, b.HTTP) || !sameConfigValue(a.HTTPS, b.HTTPS) || len(a.NoProxy) != len(b.NoProxy) {
+		return false
+	}
+	x := append([]string(nil), a.NoProxy...)
+	y := append([]string(nil), b.NoProxy...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if !strings.EqualFold(x[i], y[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// redactDetection masks credentials in the proxy URLs of a detection
+func redactDetection(detection *core.ProxyDetection) *core.ProxyDetection {
+	detection.Environment = redactProxySettings(detection.Environment)
+	for i := range detection.Proposals {
+		proposal := &detection.Proposals[i]
+		proposal.Current = redactProxySettings(proposal.Current)
+		proposal.Proposed = redactProxySettings(proposal.Proposed)
+		for j := range proposal.Routes {
+			proposal.Routes[j].Registry = utils.RedactURL(proposal.Routes[j].Registry)
+			proposal.Routes[j].Proxy = utils.RedactURL(proposal.Routes[j].Proxy)
+		}
+	}
+	return detection
+}
+
+func redactProxySettings(proxy core.ProxySettings) core.ProxySettings {
+	proxy.HTTP = utils.RedactURL(proxy.HTTP)
+	proxy.HTTPS = utils.RedactURL(proxy.HTTPS)
+	return proxy
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"npm-console/internal/core"
+	"npm-console/internal/pac"
+)
+
+func TestProposePACProxy(t *testing.T) {
+	script, err := pac.Parse(`function FindProxyForURL(url, host) {
+	if (dnsDomainIs(host, ".corp.local")) return "DIRECT";
+	if (host == "npm.pkg.github.com") return "PROXY gh-proxy.corp.local:3128";
+	if (host == "socks.example.com") return "SOCKS5 socks.corp.local:1080";
+	return "PROXY proxy.corp.local:8080; DIRECT";
+}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	proposal := core.ProxyProposal{Manager: "npm"}
+	registries := []string{
+		"https://registry.npmjs.org/",
+		"https://npm.corp.local/",
+		"https://npm.pkg.github.com/",
+		"https://socks.example.com/",
+	}
+	if err := proposePACProxy(script, registries, &proposal); err != nil {
+		t.Fatalf("proposePACProxy() error = %v", err)
+	}
+
+	want := core.ProxySettings{HTTP: "http://proxy.corp.local:8080", HTTPS: "http://proxy.corp.local:8080", NoProxy: []string{"npm.corp.local"}}
+	if !sameProxySettings(proposal.Proposed, want) {
+		t.Errorf("Proposed = %+v, want %+v", proposal.Proposed, want)
+	}
+	if len(proposal.Routes) != 3 {
+		t.Errorf("Routes = %+v, want the three registries with a usable result", proposal.Routes)
+	}
+	if len(proposal.Warnings) != 2 || !strings.Contains(proposal.Warnings[0], "SOCKS") || !strings.Contains(proposal.Warnings[1], "gh-proxy") {
+		t.Errorf("Warnings = %q, want the SOCKS and second proxy warnings", proposal.Warnings)
+	}
+
+	// A PAC file sending everything DIRECT proposes no proxy at all
+	direct, _ := pac.Parse(`function FindProxyForURL(url, host) { return "DIRECT"; }`)
+	proposal = core.ProxyProposal{Manager: "npm", Proposed: core.ProxySettings{HTTP: "http://env.proxy:80"}}
+	if err := proposePACProxy(direct, registries[:2], &proposal); err != nil {
+		t.Fatalf("proposePACProxy() error = %v", err)
+	}
+	if !sameProxySettings(proposal.Proposed, core.ProxySettings{}) {
+		t.Errorf("Proposed = %+v, want no proxy", proposal.Proposed)
+	}
+}
+
+func TestEnvironmentProxy(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("http_proxy", "http://proxy.corp.local:8080")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("https_proxy", "")
+	t.Setenv("NO_PROXY", "localhost, .corp.local")
+
+	want := core.ProxySettings{
+		HTTP:    "http://proxy.corp.local:8080",
+		HTTPS:   "http://proxy.corp.local:8080",
+		NoProxy: []string{"localhost", ".corp.local"},
+	}
+	if got := environmentProxy(); !sameProxySettings(got, want) {
+		t.Errorf("environmentProxy() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"npm-console/internal/managers"
+	"npm-console/internal/registry"
+	"npm-console/pkg/utils"
+)
+
+// newRegistryClient creates a registry client configured the way a manager
+// would reach its registries from projectPath: default and scoped registries,
+// auth tokens and proxy. projectPath may be empty to use user settings only.
+func newRegistryClient(ctx context.Context, factory *managers.ManagerFactory, manager, projectPath string) *registry.Client {
+	return registry.NewClientWithOptions(registryOptions(ctx, factory, manager, projectPath))
+}
+
+// registryOptions collects the registry client options for a manager
+func registryOptions(ctx context.Context, factory *managers.ManagerFactory, manager, projectPath string) registry.Options {
+	settings := managers.LoadRegistrySettings(manager, projectPath)
+
+	// Fall back to what the manager itself reports (global config, environment)
+	if settings.Registry == "" || settings.Proxy == "" || settings.HTTPSProxy == "" || settings.NoProxy == nil {
+		if pm, err := factory.GetManager(manager); err == nil {
+			if cfg, err := pm.GetConfig(ctx); err == nil {
+				if settings.Registry == "" && strings.HasPrefix(cfg.Registry, "http") {
+					settings.Registry = cfg.Registry
+				}
+				if settings.Proxy == "" && strings.HasPrefix(cfg.Proxy, "http") {
+					settings.Proxy = cfg.Proxy
+				}
+				if settings.HTTPSProxy == "" && strings.HasPrefix(cfg.HTTPSProxy, "http") {
+					settings.HTTPSProxy = cfg.HTTPSProxy
+				}
+				if settings.NoProxy == nil {
+					settings.NoProxy = cfg.NoProxy
+				}
+			}
+		}
+	}
+
+	// npm applies an unscoped _authToken to the configured registry only
+	tokens := make(map[string]string, len(settings.Tokens))
+	for prefix, token := range settings.Tokens {
+		if prefix != "" {
+			tokens[prefix] = token
+		}
+	}
+	if token := settings.Tokens[""]; token != "" {
+		defaultRegistry := settings.Registry
+		if defaultRegistry == "" {
+			defaultRegistry = registry.DefaultRegistry
+		}
+		if nerfed := managers.NerfDart(defaultRegistry); tokens[nerfed] == "" {
+			tokens[nerfed] = token
+		}
+	}
+
+	opts := registry.Options{
+		Registry:   settings.Registry,
+		Scopes:     settings.Scopes,
+		Tokens:     tokens,
+		Proxy:      settings.Proxy,
+		HTTPSProxy: settings.HTTPSProxy,
+		NoProxy:    settings.NoProxy,
+	}
+	if cacheDir, err := utils.GetCacheDir(); err == nil {
+		opts.CacheDir = filepath.Join(cacheDir, "npm-console", "registry")
+	}
+
+	return opts
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTestRegistryCredentials(t *testing.T) {
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if header := r.Header.Get("Authorization"); header != "" {
+			auth = append(auth, header)
+		}
+		w.Write([]byte(`{"name": "npm", "versions": {}}`))
+	}))
+	defer server.Close()
+
+	npmrc := filepath.Join(t.TempDir(), ".npmrc")
+	os.WriteFile(npmrc, []byte("registry=https://npm.corp.local/\n_authToken=legacy\n//npm.corp.local/:_authToken=secret\nnoproxy=127.0.0.1\n"), 0600)
+	t.Setenv("NPM_CONFIG_USERCONFIG", npmrc)
+
+	s := NewConfigService()
+	opts := registryOptions(context.Background(), s.factory, "npm", "")
+	if opts.Tokens["//npm.corp.local/"] != "secret" || len(opts.Tokens) != 1 {
+		t.Errorf("registryOptions() tokens = %v, want only the corp registry's", opts.Tokens)
+	}
+
+	result, err := s.TestRegistry(context.Background(), "npm", server.URL)
+	if err != nil {
+		t.Fatalf("TestRegistry() error = %v", err)
+	}
+	if !result.Success {
+		t.Errorf("TestRegistry() = %+v, want success", result)
+	}
+	if len(auth) > 0 {
+		t.Errorf("registry under test received credentials: %v", auth)
+	}
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"npm-console/internal/core"
+	"npm-console/internal/managers"
+	"npm-console/pkg/utils"
+)
+
+// maxSnapshots is the number of config snapshots kept; older ones are pruned
+const maxSnapshots = 50
+
+// CurrentSnapshot names the live config files when diffing snapshots
+const CurrentSnapshot = "current"
+
+// SnapshotStore keeps copies of the package managers' user config files in
+// a directory, one JSON file per snapshot, so that config changes can be
+// compared and rolled back
+type SnapshotStore struct {
+	dir string
+}
+
+// snapshotFile is the saved state of one config file
+type snapshotFile struct {
+	Path    string                `json:"path"`
+	Format  managers.ConfigFormat `json:"format"`
+	Exists  bool                  `json:"exists"`
+	Content string                `json:"content,omitempty"`
+}
+
+// storedSnapshot is the on-disk form of a snapshot
+type storedSnapshot struct {
+	ID        string         `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	Reason    string         `json:"reason"`
+	Files     []snapshotFile `json:"files"`
+}
+
+// NewSnapshotStore creates a snapshot store in dir
+func NewSnapshotStore(dir string) *SnapshotStore {
+	return &SnapshotStore{dir: dir}
+}
+
+// Take saves the current user config files of every manager
+func (s *SnapshotStore) Take(reason string) (*core.ConfigSnapshot, error) {
+	files, err := captureConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := storedSnapshot{CreatedAt: time.Now(), Reason: reason, Files: files}
+
+	if err := utils.MakeDir(s.dir); err != nil {
+		return nil, err
+	}
+
+	// IDs are timestamps; add a counter when several are taken in a second
+	base := snapshot.CreatedAt.UTC().Format("20060102-150405")
+	snapshot.ID = base
+	for i := 2; utils.IsFile(s.path(snapshot.ID)); i++ {
+		snapshot.ID = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	// Snapshots may hold auth tokens, so keep them private
+	if err := os.WriteFile(s.path(snapshot.ID), data, 0600); err != nil {
+		return nil, err
+	}
+
+	s.prune()
+	return snapshot.summary(), nil
+}
+
+// List returns the saved snapshots, newest first
+func (s *SnapshotStore) List() ([]core.ConfigSnapshot, error) {
+	snapshots, err := s.loadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]core.ConfigSnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		summaries = append(summaries, *snapshot.summary())
+	}
+	return summaries, nil
+}
+
+// Diff compares the config keys of two snapshots; either may be
+// CurrentSnapshot for the live files
+func (s *SnapshotStore) Diff(from, to string) (*core.ConfigDiff, error) {
+	before, err := s.load(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.load(to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &core.ConfigDiff{From: before.ID, To: after.ID, Changes: []core.ConfigChange{}}
+
+	files := make(map[string][2]*snapshotFile)
+	var paths []string
+	for i, snapshot := range []*storedSnapshot{before, after} {
+		for j := range snapshot.Files {
+			file := &snapshot.Files[j]
+			pair, ok := files[file.Path]
+			if !ok {
+				paths = append(paths, file.Path)
+			}
+			pair[i] = file
+			files[file.Path] = pair
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pair := files[path]
+		beforeValues, afterValues := pair[0].values(), pair[1].values()
+
+		keys := make(map[string]bool)
+		for key := range beforeValues {
+			keys[key] = true
+		}
+		for key := range afterValues {
+			keys[key] = true
+		}
+
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		for _, key := range sorted {
+			if beforeValues[key] == afterValues[key] {
+				continue
+			}
+			diff.Changes = append(diff.Changes, core.ConfigChange{
+				Path:   path,
+				Key:    key,
+				Before: redactValue(key, beforeValues[key]),
+				After:  redactValue(key, afterValues[key]),
+			})
+		}
+	}
+
+	return diff, nil
+}
+
+// Restore writes the config files of a snapshot back, removing files that
+// did not exist when it was taken, and returns the paths it changed
+func (s *SnapshotStore) Restore(id string) ([]string, error) {
+	snapshot, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var restored []string
+	for _, file := range snapshot.Files {
+		current, err := os.ReadFile(file.Path)
+		exists := err == nil
+		if exists == file.Exists && string(current) == file.Content {
+			continue
+		}
+
+		if !file.Exists {
+			if err := os.Remove(file.Path); err != nil && !os.IsNotExist(err) {
+				return restored, fmt.Errorf("failed to remove %s: %w", file.Path, err)
+			}
+		} else {
+			if err := utils.MakeDir(filepath.Dir(file.Path)); err != nil {
+				return restored, err
+			}
+			if err := os.WriteFile(file.Path, []byte(file.Content), 0600); err != nil {
+				return restored, fmt.Errorf("failed to restore %s: %w", file.Path, err)
+			}
+		}
+		restored = append(restored, file.Path)
+	}
+
+	return restored, nil
+}
+
+// path returns the file a snapshot is stored in
+func (s *SnapshotStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// load reads a snapshot, or the live config files for CurrentSnapshot
+func (s *SnapshotStore) load(id string) (*storedSnapshot, error) {
+	if id == CurrentSnapshot {
+		files, err := captureConfigFiles()
+		if err != nil {
+			return nil, err
+		}
+		return &storedSnapshot{ID: CurrentSnapshot, CreatedAt: time.Now(), Files: files}, nil
+	}
+
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return nil, core.NewValidationError("snapshot", id, "invalid snapshot id")
+	}
+
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", core.ErrSnapshotNotFound, id)
+		}
+		return nil, err
+	}
+
+	var snapshot storedSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", id, err)
+	}
+	return &snapshot, nil
+}
+
+// loadAll reads every stored snapshot, newest first
+func (s *SnapshotStore) loadAll() ([]*storedSnapshot, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snapshots []*storedSnapshot
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		snapshot, err := s.load(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// prune removes the oldest snapshots beyond maxSnapshots
+func (s *SnapshotStore) prune() {
+	snapshots, err := s.loadAll()
+	if err != nil {
+		return
+	}
+	for _, snapshot := range snapshots[min(len(snapshots), maxSnapshots):] {
+		os.Remove(s.path(snapshot.ID))
+	}
+}
+
+// captureConfigFiles reads the current user config files of every manager
+func captureConfigFiles() ([]snapshotFile, error) {
+	var files []snapshotFile
+	for _, file := range managers.UserConfigFiles() {
+		saved := snapshotFile{Path: file.Path, Format: file.Format}
+		data, err := os.ReadFile(file.Path)
+		if err == nil {
+			saved.Exists = true
+			saved.Content = string(data)
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+		files = append(files, saved)
+	}
+	return files, nil
+}
+
+// summary returns the snapshot without file contents
+func (s *storedSnapshot) summary() *core.ConfigSnapshot {
+	summary := &core.ConfigSnapshot{ID: s.ID, CreatedAt: s.CreatedAt, Reason: s.Reason, Files: []string{}}
+	for _, file := range s.Files {
+		if file.Exists {
+			summary.Files = append(summary.Files, file.Path)
+		}
+	}
+	return summary
+}
+
+// values parses the saved file; a missing file has no values
+func (f *snapshotFile) values() map[string]string {
+	if f == nil || !f.Exists {
+		return map[string]string{}
+	}
+
+	file := managers.ConfigFile{Format: f.Format, Path: f.Path}
+	values, err := file.Parse([]byte(f.Content))
+	if err != nil {
+		return map[string]string{}
+	}
+	return values
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"npm-console/internal/core"
+)
+
+func TestSnapshotStore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("NPM_CONFIG_USERCONFIG", "")
+
+	npmrc := filepath.Join(home, ".npmrc")
+	original := "registry=https://registry.npmjs.org/\n//registry.npmjs.org/:_authToken=npm_secret\n"
+	os.WriteFile(npmrc, []byte(original), 0600)
+
+	store := NewSnapshotStore(filepath.Join(t.TempDir(), "snapshots"))
+	first, err := store.Take("set registry")
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	if len(first.Files) != 1 || first.Files[0] != npmrc {
+		t.Errorf("Files = %v, want only %s", first.Files, npmrc)
+	}
+
+	os.WriteFile(npmrc, []byte("registry=https://mirror.local/\n"), 0600)
+	bunfig := filepath.Join(home, ".bunfig.toml")
+	os.WriteFile(bunfig, []byte("[install]\nregistry = \"https://mirror.local/\"\n"), 0600)
+
+	second, err := store.Take("set proxy")
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	if second.ID == first.ID {
+		t.Errorf("snapshots share the id %s", first.ID)
+	}
+
+	history, err := store.List()
+	if err != nil || len(history) != 2 || history[0].ID != second.ID {
+		t.Fatalf("List() = %+v, %v", history, err)
+	}
+
+	diff, err := store.Diff(first.ID, CurrentSnapshot)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	changes := make(map[string]core.ConfigChange)
+	for _, change := range diff.Changes {
+		changes[change.Path+" "+change.Key] = change
+	}
+	if change := changes[npmrc+" registry"]; change.Before != "https://registry.npmjs.org/" || change.After != "https://mirror.local/" {
+		t.Errorf("registry change = %+v", change)
+	}
+	if change := changes[npmrc+" //registry.npmjs.org/:_authToken"]; change.Before == "npm_secret" || change.After != "" {
+		t.Errorf("token change = %+v, want a masked removal", change)
+	}
+	if change := changes[bunfig+" registry"]; change.Before != "" || change.After != "https://mirror.local/" {
+		t.Errorf("bunfig change = %+v", change)
+	}
+
+	restored, err := store.Restore(first.ID)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if len(restored) != 2 {
+		t.Errorf("Restore() = %v, want .npmrc and .bunfig.toml", restored)
+	}
+	if data, _ := os.ReadFile(npmrc); string(data) != original {
+		t.Errorf(".npmrc = %q, want %q", data, original)
+	}
+	if _, err := os.Stat(bunfig); !os.IsNotExist(err) {
+		t.Errorf(".bunfig.toml should have been removed, stat error = %v", err)
+	}
+
+	if _, err := store.Diff("19700101-000000", CurrentSnapshot); !errors.Is(err, core.ErrSnapshotNotFound) {
+		t.Errorf("Diff() of a missing snapshot error = %v", err)
+	}
+	if _, err := store.Restore("../secrets"); !core.IsValidationError(err) {
+		t.Errorf("Restore() of an invalid id error = %v", err)
+	}
+}
@@ -2,7 +2,9 @@ package web
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"path/filepath"
 
 	"npm-console/internal/core"
@@ -143,12 +145,34 @@ func (s *Server) handleSearchPackages(c *fiber.Ctx) error {
 		return s.sendError(c, fiber.StatusBadRequest, "Search query is required")
 	}
 	
-	packages, err := s.packageService.SearchPackages(ctx, query)
-	if err != nil {
-		return s.sendError(c, fiber.StatusInternalServerError, err.Error())
+	// mode=local searches installed global packages, mode=registry the registry
+	switch c.Query("mode", "local") {
+	case "local":
+		packages, err := s.packageService.SearchPackages(ctx, query)
+		if err != nil {
+			return s.sendError(c, fiber.StatusInternalServerError, err.Error())
+		}
+		return s.sendSuccess(c, packages)
+		
+	case "registry":
+		opts := services.SearchOptions{
+			Manager:     c.Query("manager", "npm"),
+			From:        c.QueryInt("from", 0),
+			Size:        c.QueryInt("size", 20),
+			Quality:     c.QueryFloat("quality", 0),
+			Popularity:  c.QueryFloat("popularity", 0),
+			Maintenance: c.QueryFloat("maintenance", 0),
+		}
+		
+		results, err := s.packageService.SearchRegistry(ctx, query, opts)
+		if err != nil {
+			return s.sendError(c, fiber.StatusBadGateway, err.Error())
+		}
+		return s.sendSuccess(c, results)
+		
+	default:
+		return s.sendError(c, fiber.StatusBadRequest, "Search mode must be 'local' or 'registry'")
 	}
-	
-	return s.sendSuccess(c, packages)
 }
 
 func (s *Server) handleGetPackageStats(c *fiber.Ctx) error {
@@ -178,11 +202,20 @@ func (s *Server) handleGetPackageStats(c *fiber.Ctx) error {
 
 func (s *Server) handleGetPackageInfo(c *fiber.Ctx) error {
 	ctx := context.Background()
-	packageName := c.Params("name")
+	managerName := c.Query("manager", "")
+	
+	// Scoped names arrive encoded ("@types%2Fnode")
+	packageName, err := url.PathUnescape(c.Params("name"))
+	if err != nil {
+		return s.sendError(c, fiber.StatusBadRequest, "Invalid package name")
+	}
 	
-	packageInfo, err := s.packageService.GetPackageInfo(ctx, packageName)
+	packageInfo, err := s.packageService.GetPackageInfoWithManager(ctx, packageName, managerName)
 	if err != nil {
-		return s.sendError(c, fiber.StatusNotFound, err.Error())
+		if errors.Is(err, core.ErrPackageNotFound) {
+			return s.sendError(c, fiber.StatusNotFound, err.Error())
+		}
+		return s.sendError(c, fiber.StatusBadGateway, err.Error())
 	}
 	
 	return s.sendSuccess(c, packageInfo)
@@ -255,16 +288,23 @@ func (s *Server) handleSetProxy(c *fiber.Ctx) error {
 	manager := c.Params("manager")
 	
 	var req struct {
-		Proxy string `json:"proxy"`
+		Proxy      string   `json:"proxy"`
+		HTTPSProxy string   `json:"https_proxy"` // defaults to proxy
+		NoProxy    []string `json:"no_proxy"`
 	}
 	
 	if err := c.BodyParser(&req); err != nil {
 		return s.sendError(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 	
-	err := s.configService.SetProxy(ctx, manager, req.Proxy)
+	proxy := core.ProxySettings{HTTP: req.Proxy, HTTPS: req.HTTPSProxy, NoProxy: req.NoProxy}
+	if proxy.HTTPS == "" {
+		proxy.HTTPS = proxy.HTTP
+	}
+	
+	err := s.configService.SetProxy(ctx, manager, proxy)
 	if err != nil {
-		return s.sendError(c, fiber.StatusInternalServerError, err.Error())
+		return s.sendError(c, configErrorStatus(err), err.Error())
 	}
 	
 	return s.sendSuccess(c, fiber.Map{
@@ -276,9 +316,9 @@ func (s *Server) handleUnsetProxy(c *fiber.Ctx) error {
 	ctx := context.Background()
 	manager := c.Params("manager")
 	
-	err := s.configService.SetProxy(ctx, manager, "")
+	err := s.configService.SetProxy(ctx, manager, core.ProxySettings{})
 	if err != nil {
-		return s.sendError(c, fiber.StatusInternalServerError, err.Error())
+		return s.sendError(c, configErrorStatus(err), err.Error())
 	}
 	
 	return s.sendSuccess(c, fiber.Map{
@@ -286,7 +326,284 @@ func (s *Server) handleUnsetProxy(c *fiber.Ctx) error {
 	})
 }
 
+func (s *Server) handleTestRegistry(c *fiber.Ctx) error {
+	ctx := context.Background()
+	manager := c.Params("manager")
+	
+	var req struct {
+		Registry string `json:"registry"`
+	}
+	
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		return s.sendError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	
+	// Test the manager's current registry when none is given
+	if req.Registry == "" {
+		config, err := s.configService.GetConfig(ctx, manager)
+		if err != nil {
+			return s.sendError(c, fiber.StatusInternalServerError, err.Error())
+		}
+		req.Registry = config.Registry
+	}
+	
+	result, err := s.configService.TestRegistry(ctx, manager, req.Registry)
+	if err != nil {
+		return s.sendError(c, fiber.StatusBadRequest, err.Error())
+	}
+	
+	return s.sendSuccess(c, result)
+}
 
+func (s *Server) handleTestProxy(c *fiber.Ctx) error {
+	ctx := context.Background()
+	manager := c.Params("manager")
+	
+	var req struct {
+		Proxy string `json:"proxy"`
+	}
+	
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		return s.sendError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	
+	// An empty proxy tests the manager's current proxy
+	result, err := s.configService.TestProxy(ctx, manager, req.Proxy)
+	if err != nil {
+		return s.sendError(c, fiber.StatusBadRequest, err.Error())
+	}
+	
+	return s.sendSuccess(c, result)
+}
+
+func (s *Server) handleGetScopedRegistries(c *fiber.Ctx) error {
+	ctx := context.Background()
+	manager := c.Params("manager")
+	
+	registries, err := s.configService.ListScopedRegistries(ctx, manager)
+	if err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, registries)
+}
+
+func (s *Server) handleSetScopedRegistry(c *fiber.Ctx) error {
+	ctx := context.Background()
+	manager := c.Params("manager")
+	
+	var req struct {
+		Scope    string `json:"scope"`
+		Registry string `json:"registry"`
+	}
+	
+	if err := c.BodyParser(&req); err != nil {
+		return s.sendError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	
+	if req.Scope == "" || req.Registry == "" {
+		return s.sendError(c, fiber.StatusBadRequest, "Scope and registry URL are required")
+	}
+	
+	scope := managers.NormalizeScope(req.Scope)
+	if err := s.configService.SetScopedRegistry(ctx, manager, scope, req.Registry); err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, fiber.Map{
+		"message": "Scoped registry " + scope + " updated successfully for " + manager,
+	})
+}
+
+func (s *Server) handleRemoveScopedRegistry(c *fiber.Ctx) error {
+	ctx := context.Background()
+	manager := c.Params("manager")
+	
+	scope, err := url.PathUnescape(c.Params("scope"))
+	if err != nil {
+		return s.sendError(c, fiber.StatusBadRequest, "Invalid scope")
+	}
+	scope = managers.NormalizeScope(scope)
+	
+	if err := s.configService.SetScopedRegistry(ctx, manager, scope, ""); err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, fiber.Map{
+		"message": "Scoped registry " + scope + " removed successfully for " + manager,
+	})
+}
+
+func (s *Server) handleGetCredentials(c *fiber.Ctx) error {
+	ctx := context.Background()
+	manager := c.Params("manager")
+	
+	credentials, err := s.configService.ListCredentials(ctx, manager)
+	if err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, credentials)
+}
+
+func (s *Server) handleSetAuthToken(c *fiber.Ctx) error {
+	ctx := context.Background()
+	manager := c.Params("manager")
+	
+	var req struct {
+		Registry string `json:"registry"`
+		Token    string `json:"token"`
+		EnvVar   string `json:"env_var"`
+	}
+	
+	if err := c.BodyParser(&req); err != nil {
+		return s.sendError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	
+	if req.Registry == "" || (req.Token == "" && req.EnvVar == "") {
+		return s.sendError(c, fiber.StatusBadRequest, "Registry URL and a token or env_var are required")
+	}
+	
+	if err := s.configService.SetAuthToken(ctx, manager, req.Registry, req.Token, req.EnvVar); err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, fiber.Map{
+		"message": "Auth token updated successfully for " + manager,
+	})
+}
+
+func (s *Server) handleRemoveAuthToken(c *fiber.Ctx) error {
+	ctx := context.Background()
+	manager := c.Params("manager")
+	registryURL := c.Query("registry")
+	
+	if registryURL == "" {
+		return s.sendError(c, fiber.StatusBadRequest, "Registry URL is required")
+	}
+	
+	if err := s.configService.SetAuthToken(ctx, manager, registryURL, "", ""); err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, fiber.Map{
+		"message": "Auth token removed successfully for " + manager,
+	})
+}
+
+// handleExplainConfig reports where config values come from. Without a
+// manager every manager is explained; ?key= selects one key and ?project= the
+// project directory.
+func (s *Server) handleExplainConfig(c *fiber.Ctx) error {
+	ctx := context.Background()
+	
+	entries, err := s.configService.ExplainConfig(ctx, c.Query("manager"), c.Query("key"), c.Query("project"), nil)
+	if err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, entries)
+}
+
+func (s *Server) handleGetConfigHistory(c *fiber.Ctx) error {
+	ctx := context.Background()
+	
+	snapshots, err := s.configService.ConfigHistory(ctx)
+	if err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, snapshots)
+}
+
+func (s *Server) handleDiffConfig(c *fiber.Ctx) error {
+	ctx := context.Background()
+	from := c.Query("from")
+	to := c.Query("to", services.CurrentSnapshot)
+	
+	if from == "" {
+		return s.sendError(c, fiber.StatusBadRequest, "from snapshot is required")
+	}
+	
+	diff, err := s.configService.DiffConfig(ctx, from, to)
+	if err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, diff)
+}
+
+func (s *Server) handleRollbackConfig(c *fiber.Ctx) error {
+	ctx := context.Background()
+	id := c.Params("id")
+	
+	result, err := s.configService.RollbackConfig(ctx, id)
+	if err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, result)
+}
+
+func (s *Server) handleCheckDrift(c *fiber.Ctx) error {
+	ctx := context.Background()
+	
+	drift, err := s.configService.CheckDrift(ctx, &s.config.Managers)
+	if err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, drift)
+}
+
+func (s *Server) handleFixDrift(c *fiber.Ctx) error {
+	ctx := context.Background()
+	
+	plan, err := s.configService.FixDrift(ctx, &s.config.Managers)
+	if err != nil {
+		return s.sendError(c, configErrorStatus(err), err.Error())
+	}
+	
+	return s.sendSuccess(c, plan)
+}
+
+// configErrorStatus maps config errors to HTTP status codes
+func configErrorStatus(err error) int {
+	var validationErr *core.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return fiber.StatusBadRequest
+	case errors.Is(err, core.ErrNotSupported):
+		return fiber.StatusNotImplemented
+	case errors.Is(err, core.ErrSnapshotNotFound):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// Project handlers
+
+func (s *Server) handleGetOutdatedPackages(c *fiber.Ctx) error {
+	ctx := context.Background()
+	projectPath := c.Query("path", ".")
+	
+	// Convert to absolute path
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return s.sendError(c, fiber.StatusBadRequest, "Invalid project path")
+	}
+	
+	outdated, err := s.projectService.GetOutdatedPackages(ctx, absPath)
+	if err != nil {
+		if errors.Is(err, core.ErrProjectNotFound) {
+			return s.sendError(c, fiber.StatusNotFound, err.Error())
+		}
+		return s.sendError(c, fiber.StatusInternalServerError, err.Error())
+	}
+	
+	return s.sendSuccess(c, outdated)
+}
 
 // Manager handlers
 
@@ -26,6 +26,7 @@ type Server struct {
 	packageService *services.PackageService
 	configService *services.ConfigService
 	projectService *services.ProjectService
+	stopAutoClean context.CancelFunc
 }
 
 // NewServer creates a new web server instance
@@ -49,6 +50,9 @@ func NewServer(cfg *config.Config) *Server {
 		projectService: services.NewProjectService(),
 	}
 
+	server.projectService.SetAdvisoryDatabase(cfg.Audit.Database)
+	server.configService.SetDataDir(cfg.App.DataDir)
+
 	server.setupMiddleware()
 	server.setupRoutes()
 
@@ -129,12 +133,28 @@ func (s *Server) setupRoutes() {
 	configs := api.Group("/config")
 	configs.Get("/", s.handleGetAllConfigs)
 	configs.Get("/summary", s.handleGetConfigSummary)
+	configs.Get("/explain", s.handleExplainConfig)
+	configs.Get("/snapshots", s.handleGetConfigHistory)
+	configs.Get("/snapshots/diff", s.handleDiffConfig)
+	configs.Post("/snapshots/:id/rollback", s.handleRollbackConfig)
+	configs.Get("/drift", s.handleCheckDrift)
+	configs.Post("/drift/fix", s.handleFixDrift)
 	configs.Get("/:manager", s.handleGetConfig)
 	configs.Put("/:manager/registry", s.handleSetRegistry)
+	configs.Post("/:manager/registry/test", s.handleTestRegistry)
 	configs.Put("/:manager/proxy", s.handleSetProxy)
 	configs.Delete("/:manager/proxy", s.handleUnsetProxy)
-
-
+	configs.Post("/:manager/proxy/test", s.handleTestProxy)
+	configs.Get("/:manager/scopes", s.handleGetScopedRegistries)
+	configs.Put("/:manager/scopes", s.handleSetScopedRegistry)
+	configs.Delete("/:manager/scopes/:scope", s.handleRemoveScopedRegistry)
+	configs.Get("/:manager/credentials", s.handleGetCredentials)
+	configs.Put("/:manager/credentials", s.handleSetAuthToken)
+	configs.Delete("/:manager/credentials", s.handleRemoveAuthToken)
+
+	// Project routes
+	projects := api.Group("/projects")
+	projects.Get("/outdated", s.handleGetOutdatedPackages)
 
 	// Manager routes
 	managers := api.Group("/managers")
@@ -154,6 +174,7 @@ func (s *Server) Start() error {
 	addr := fmt.Sprintf("%s:%d", s.config.Web.Host, s.config.Web.Port)
 	
 	s.logger.Info("Starting web server", "address", addr)
+	s.startCacheAutoClean()
 	
 	if s.config.Web.TLS.Enabled {
 		return s.app.ListenTLS(addr, s.config.Web.TLS.CertFile, s.config.Web.TLS.KeyFile)
@@ -162,9 +183,34 @@ func (s *Server) Start() error {
 	return s.app.Listen(addr)
 }
 
+// startCacheAutoClean prunes the caches every scan interval when the cache
+// config enables auto_clean
+func (s *Server) startCacheAutoClean() {
+	if !s.config.Cache.AutoClean {
+		return
+	}
+	
+	policy, err := services.ParseCachePolicy(s.config.Cache)
+	if err != nil {
+		s.logger.WithError(err).Warn("Invalid cache policy, auto-clean disabled")
+		return
+	}
+	if policy.IsEmpty() {
+		s.logger.Warn("Cache auto-clean is enabled without max_size or max_age, nothing to enforce")
+		return
+	}
+	
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopAutoClean = cancel
+	go s.cacheService.RunCachePolicy(ctx, policy)
+}
+
 // Shutdown gracefully shuts down the web server
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("Shutting down web server")
+	if s.stopAutoClean != nil {
+		s.stopAutoClean()
+	}
 	return s.app.ShutdownWithContext(ctx)
 }
 
@@ -8,6 +8,7 @@ import (
 	"npm-console/pkg/logger"
 	"npm-console/pkg/utils"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
@@ -28,6 +29,15 @@ type Config struct {
 	
 	// Cache settings
 	Cache CacheConfig `yaml:"cache" json:"cache"`
+	
+	// Vulnerability audit settings
+	Audit AuditConfig `yaml:"audit" json:"audit"`
+	
+	// Named registry profiles
+	Registry RegistryConfig `yaml:"registry" json:"registry"`
+	
+	// path is the file the configuration was loaded from, if any
+	path string
 }
 
 // AppConfig represents application-level configuration
@@ -63,14 +73,26 @@ type ManagersConfig struct {
 	PNPM ManagerConfig `yaml:"pnpm" json:"pnpm"`
 	Yarn ManagerConfig `yaml:"yarn" json:"yarn"`
 	Bun  ManagerConfig `yaml:"bun" json:"bun"`
+	
+	// Additional registries considered by "registry bench"
+	Mirrors []RegistryMirror `yaml:"mirrors" json:"mirrors"`
+}
+
+// RegistryMirror represents a user-defined registry mirror
+type RegistryMirror struct {
+	Name string `yaml:"name" json:"name"`
+	URL  string `yaml:"url" json:"url"`
 }
 
 // ManagerConfig represents individual package manager configuration
 type ManagerConfig struct {
-	Enabled  bool              `yaml:"enabled" json:"enabled"`
-	Registry string            `yaml:"registry" json:"registry"`
-	Proxy    string            `yaml:"proxy" json:"proxy"`
-	Settings map[string]string `yaml:"settings" json:"settings"`
+	Enabled    bool              `yaml:"enabled" json:"enabled"`
+	Registry   string            `yaml:"registry" json:"registry"`
+	Proxy      string            `yaml:"proxy" json:"proxy"`                                 // proxy for http://, and https:// unless https_proxy is set
+	HTTPSProxy string            `yaml:"https_proxy,omitempty" json:"https_proxy,omitempty"` // proxy for https://
+	NoProxy    []string          `yaml:"no_proxy,omitempty" json:"no_proxy,omitempty"`       // hosts reached without a proxy
+	Scopes     map[string]string `yaml:"scopes,omitempty" json:"scopes,omitempty"`           // "@scope" -> registry URL
+	Settings   map[string]string `yaml:"settings" json:"settings"`
 }
 
 // CacheConfig represents cache configuration
@@ -81,6 +103,27 @@ type CacheConfig struct {
 	ScanInterval string `yaml:"scan_interval" json:"scan_interval"`
 }
 
+// AuditConfig represents vulnerability audit configuration
+type AuditConfig struct {
+	Database string `yaml:"database" json:"database"` // advisory file, directory or mirror URL
+	Level    string `yaml:"level" json:"level"`       // minimum severity that fails an audit
+}
+
+// RegistryConfig represents the named registry profiles
+type RegistryConfig struct {
+	Active   string                     `yaml:"active" json:"active"`     // profile applied last
+	Default  string                     `yaml:"default" json:"default"`   // profile used by "registry reset"
+	Profiles map[string]RegistryProfile `yaml:"profiles" json:"profiles"`
+}
+
+// RegistryProfile represents a named set of registry settings
+type RegistryProfile struct {
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Registry    string            `yaml:"registry" json:"registry"`
+	Managers    map[string]string `yaml:"managers,omitempty" json:"managers,omitempty"` // manager -> registry override
+	Scopes      map[string]string `yaml:"scopes,omitempty" json:"scopes,omitempty"`     // "@scope" -> registry URL
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	home, _ := utils.GetHomeDir()
@@ -146,6 +189,23 @@ func DefaultConfig() *Config {
 			MaxAge:       "30d",
 			ScanInterval: "1h",
 		},
+		Audit: AuditConfig{
+			Database: filepath.Join(home, ".npm-console", "advisories.json"),
+			Level:    "low",
+		},
+		Registry: RegistryConfig{
+			Default: "npmjs",
+			Profiles: map[string]RegistryProfile{
+				"npmjs": {
+					Description: "Official npm registry",
+					Registry:    "https://registry.npmjs.org/",
+				},
+				"mirror-cn": {
+					Description: "npmmirror.com mirror",
+					Registry:    "https://registry.npmmirror.com/",
+				},
+			},
+		},
 	}
 }
 
@@ -178,13 +238,24 @@ func Load(configPath string) (*Config, error) {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("failed to read config file: %w", err)
 		}
+
+		// Fall back to the file Save writes by default
+		if path, err := defaultConfigPath(); err == nil && configPath == "" && utils.IsFile(path) {
+			v.SetConfigFile(path)
+			if err := v.ReadInConfig(); err != nil {
+				return nil, fmt.Errorf("failed to read config file: %w", err)
+			}
+		}
 		// Config file not found is OK, we'll use defaults
 	}
 	
-	// Unmarshal into config struct
-	if err := v.Unmarshal(config); err != nil {
+	// Unmarshal into config struct, by the same yaml keys Save writes
+	if err := v.Unmarshal(config, viper.DecoderConfigOption(func(c *mapstructure.DecoderConfig) {
+		c.TagName = "yaml"
+	})); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	config.path = v.ConfigFileUsed()
 	
 	// Validate and set defaults
 	if err := config.validate(); err != nil {
@@ -194,14 +265,32 @@ func Load(configPath string) (*Config, error) {
 	return config, nil
 }
 
-// Save saves the configuration to a file
+// Path returns the file the configuration was loaded from, or the file Save
+// writes to when none was found
+func (c *Config) Path() string {
+	if c.path != "" {
+		return c.path
+	}
+	if path, err := defaultConfigPath(); err == nil {
+		return path
+	}
+	return filepath.Join(c.App.ConfigDir, "config.yaml")
+}
+
+// defaultConfigPath returns the file a configuration is saved to by default
+func defaultConfigPath() (string, error) {
+	configDir, err := utils.GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "npm-console", "config.yaml"), nil
+}
+
+// Save saves the configuration to a file. An empty path writes back to the
+// file the configuration was loaded from.
 func (c *Config) Save(configPath string) error {
 	if configPath == "" {
-		configDir, err := utils.GetConfigDir()
-		if err != nil {
-			return fmt.Errorf("failed to get config directory: %w", err)
-		}
-		configPath = filepath.Join(configDir, "npm-console", "config.yaml")
+		configPath = c.Path()
 	}
 	
 	// Ensure directory exists
@@ -245,15 +334,20 @@ func (c *Config) validate() error {
 
 // GetManagerConfig returns configuration for a specific manager
 func (c *Config) GetManagerConfig(manager string) *ManagerConfig {
+	return c.Managers.Get(manager)
+}
+
+// Get returns the configuration of a manager, or nil for an unknown manager
+func (m *ManagersConfig) Get(manager string) *ManagerConfig {
 	switch manager {
 	case "npm":
-		return &c.Managers.NPM
+		return &m.NPM
 	case "pnpm":
-		return &c.Managers.PNPM
+		return &m.PNPM
 	case "yarn":
-		return &c.Managers.Yarn
+		return &m.Yarn
 	case "bun":
-		return &c.Managers.Bun
+		return &m.Bun
 	default:
 		return nil
 	}
@@ -269,6 +363,15 @@ func (c *Config) SetManagerRegistry(manager, registry string) error {
 	return nil
 }
 
+// GetRegistryProfile returns a named registry profile
+func (c *Config) GetRegistryProfile(name string) (*RegistryProfile, error) {
+	profile, ok := c.Registry.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown registry profile: %s", name)
+	}
+	return &profile, nil
+}
+
 // SetManagerProxy sets the proxy for a specific manager
 func (c *Config) SetManagerProxy(manager, proxy string) error {
 	config := c.GetManagerConfig(manager)
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfig writes a YAML config file whose data and config directories
+// live in the test's temporary directory, followed by extra
+func writeConfig(t *testing.T, extra string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	dataDir := filepath.Join(dir, "data")
+	path := filepath.Join(dir, "config.yaml")
+	content := "app:\n  data_dir: " + dataDir + "\n  config_dir: " + filepath.Join(dir, "config") + "\n" + extra
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, dataDir
+}
+
+func TestLoadDataDir(t *testing.T) {
+	path, dataDir := writeConfig(t, "")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.App.DataDir != dataDir {
+		t.Errorf("App.DataDir = %q, want %q", cfg.App.DataDir, dataDir)
+	}
+	if cfg.Path() != path {
+		t.Errorf("Path() = %q, want %q", cfg.Path(), path)
+	}
+}
+
+func TestLoadManagerProxy(t *testing.T) {
+	path, _ := writeConfig(t, `managers:
+  npm:
+    enabled: true
+    proxy: http://proxy.corp.local:3128
+    https_proxy: http://secure.corp.local:3128
+    no_proxy:
+      - localhost
+      - .corp.local
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := cfg.Save(""); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() of the saved config error = %v", err)
+	}
+
+	npm := cfg.Managers.NPM
+	if npm.HTTPSProxy != "http://secure.corp.local:3128" {
+		t.Errorf("HTTPSProxy = %q", npm.HTTPSProxy)
+	}
+	if len(npm.NoProxy) != 2 || npm.NoProxy[1] != ".corp.local" {
+		t.Errorf("NoProxy = %v", npm.NoProxy)
+	}
+}
+
+func TestLoadCachePolicy(t *testing.T) {
+	path, _ := writeConfig(t, `cache:
+  auto_clean: true
+  max_size: 5GB
+  max_age: 720h
+  scan_interval: 6h
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := CacheConfig{AutoClean: true, MaxSize: "5GB", MaxAge: "720h", ScanInterval: "6h"}
+	if cfg.Cache != want {
+		t.Errorf("Cache = %+v, want %+v", cfg.Cache, want)
+	}
+}
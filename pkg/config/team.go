@@ -0,0 +1,51 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTeamFile is the team config file looked for in the working directory
+const DefaultTeamFile = "npm-console.team.yaml"
+
+// TeamConfig is a shared, version controlled description of how every
+// package manager should be configured. Empty registry and proxy fields are
+// left as they are; "enabled" is ignored.
+//
+//	managers:
+//	  npm:
+//	    registry: https://npm.corp.local/
+//	    scopes:
+//	      "@corp": https://npm.corp.local/
+//	    settings:
+//	      strict-ssl: "true"
+type TeamConfig struct {
+	Managers ManagersConfig `yaml:"managers" json:"managers"`
+}
+
+// LoadTeamConfig reads a team config file, rejecting unknown fields so that
+// typos are not silently ignored
+func LoadTeamConfig(path string) (*TeamConfig, error) {
+	if path == "" {
+		path = DefaultTeamFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read team config: %w", err)
+	}
+
+	var team TeamConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&team); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse team config %s: %w", path, err)
+	}
+
+	return &team, nil
+}
@@ -0,0 +1,362 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Range is a parsed npm version range: a union of comparator sets
+type Range struct {
+	raw  string
+	sets [][]comparator
+}
+
+// comparator is a single "<op><version>" constraint
+type comparator struct {
+	op      string
+	version *Version
+}
+
+// operatorSpacing matches operators followed by whitespace, e.g. ">= 1.2.3"
+var operatorSpacing = regexp.MustCompile(`(<=|>=|<|>|=|~>|~|\^)\s+`)
+
+// ParseRange parses an npm range such as "^1.2.0", ">=1.0.0 <2.0.0",
+// "1.x || 2.x" or "1.2.3 - 2.3.4"
+func ParseRange(s string) (*Range, error) {
+	r := &Range{raw: s}
+
+	for _, part := range strings.Split(s, "||") {
+		set, err := parseComparatorSet(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %w", s, err)
+		}
+		r.sets = append(r.sets, set)
+	}
+
+	return r, nil
+}
+
+// String returns the range as it was written
+func (r *Range) String() string {
+	return r.raw
+}
+
+// Contains reports whether v satisfies the range. As in npm, prerelease versions
+// only match comparators that carry a prerelease on the same major.minor.patch.
+func (r *Range) Contains(v *Version) bool {
+	for _, set := range r.sets {
+		if setContains(set, v, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsPrerelease is like Contains but lets prerelease versions match any
+// comparator, as npm's includePrerelease option does
+func (r *Range) ContainsPrerelease(v *Version) bool {
+	for _, set := range r.sets {
+		if setContains(set, v, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// Satisfies reports whether version satisfies rangeStr
+func Satisfies(version, rangeStr string) bool {
+	v, err := Parse(version)
+	if err != nil {
+		return false
+	}
+	r, err := ParseRange(rangeStr)
+	if err != nil {
+		return false
+	}
+	return r.Contains(v)
+}
+
+// MaxSatisfying returns the highest of versions that satisfies rangeStr, or ""
+func MaxSatisfying(versions []string, rangeStr string) string {
+	r, err := ParseRange(rangeStr)
+	if err != nil {
+		return ""
+	}
+
+	var best *Version
+	var bestRaw string
+	for _, raw := range versions {
+		v, err := Parse(raw)
+		if err != nil || !r.Contains(v) {
+			continue
+		}
+		if best == nil || v.Compare(best) > 0 {
+			best, bestRaw = v, raw
+		}
+	}
+	return bestRaw
+}
+
+// setContains tests v against every comparator of a set
+func setContains(set []comparator, v *Version, includePrerelease bool) bool {
+	for _, c := range set {
+		if !c.matches(v) {
+			return false
+		}
+	}
+
+	if includePrerelease || !v.IsPrerelease() {
+		return true
+	}
+	for _, c := range set {
+		if c.version.IsPrerelease() && c.version.sameTuple(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches tests v against a single comparator
+func (c comparator) matches(v *Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// parseComparatorSet parses one side of a "||" into comparators
+func parseComparatorSet(s string) ([]comparator, error) {
+	if s == "" {
+		return anyVersion(), nil
+	}
+
+	// Hyphen range: "1.2.3 - 2.3.4"
+	if fields := strings.Fields(s); len(fields) == 3 && fields[1] == "-" {
+		from, err := parsePartial(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parsePartial(fields[2])
+		if err != nil {
+			return nil, err
+		}
+
+		var set []comparator
+		if !from.anyMajor() {
+			set = append(set, comparator{">=", from.floor()})
+		}
+		switch {
+		case to.anyMajor():
+		case to.complete():
+			set = append(set, comparator{"<=", to.floor()})
+		default:
+			set = append(set, comparator{"<", to.ceiling()})
+		}
+		if len(set) == 0 {
+			return anyVersion(), nil
+		}
+		return set, nil
+	}
+
+	var set []comparator
+	for _, token := range strings.Fields(operatorSpacing.ReplaceAllString(s, "$1")) {
+		comparators, err := parseComparator(token)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+	return set, nil
+}
+
+// parseComparator expands a single token such as "^1.2.3" or ">=1.2" into comparators
+func parseComparator(token string) ([]comparator, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", "~>", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(token, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	p, err := parsePartial(token[len(op):])
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "^":
+		return caretRange(p), nil
+	case "~", "~>":
+		return tildeRange(p), nil
+	case "", "=":
+		if p.anyMajor() {
+			return anyVersion(), nil
+		}
+		if p.complete() {
+			return []comparator{{"", p.floor()}}, nil
+		}
+		return []comparator{{">=", p.floor()}, {"<", p.ceiling()}}, nil
+	case ">":
+		switch {
+		case p.anyMajor():
+			return []comparator{{"<", &Version{Prerelease: []string{"0"}}}}, nil
+		case p.complete():
+			return []comparator{{">", p.floor()}}, nil
+		default:
+			return []comparator{{">=", p.ceiling().release()}}, nil
+		}
+	case ">=":
+		if p.anyMajor() {
+			return anyVersion(), nil
+		}
+		return []comparator{{">=", p.floor()}}, nil
+	case "<":
+		if p.anyMajor() {
+			return []comparator{{"<", &Version{Prerelease: []string{"0"}}}}, nil
+		}
+		v := p.floor()
+		if !p.complete() {
+			v.Prerelease = []string{"0"}
+		}
+		return []comparator{{"<", v}}, nil
+	case "<=":
+		switch {
+		case p.anyMajor():
+			return anyVersion(), nil
+		case p.complete():
+			return []comparator{{"<=", p.floor()}}, nil
+		default:
+			return []comparator{{"<", p.ceiling()}}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported comparator %q", token)
+}
+
+// caretRange allows changes that do not modify the left-most non-zero part
+func caretRange(p *partial) []comparator {
+	switch {
+	case p.anyMajor():
+		return anyVersion()
+	case p.minor < 0:
+		return []comparator{{">=", p.floor()}, {"<", upper(p.major+1, 0, 0)}}
+	case p.patch < 0:
+		if p.major == 0 {
+			return []comparator{{">=", p.floor()}, {"<", upper(0, p.minor+1, 0)}}
+		}
+		return []comparator{{">=", p.floor()}, {"<", upper(p.major+1, 0, 0)}}
+	case p.major > 0:
+		return []comparator{{">=", p.floor()}, {"<", upper(p.major+1, 0, 0)}}
+	case p.minor > 0:
+		return []comparator{{">=", p.floor()}, {"<", upper(0, p.minor+1, 0)}}
+	default:
+		return []comparator{{">=", p.floor()}, {"<", upper(0, 0, p.patch+1)}}
+	}
+}
+
+// tildeRange allows patch-level changes, or minor-level changes if only a major is given
+func tildeRange(p *partial) []comparator {
+	switch {
+	case p.anyMajor():
+		return anyVersion()
+	case p.minor < 0:
+		return []comparator{{">=", p.floor()}, {"<", upper(p.major+1, 0, 0)}}
+	default:
+		return []comparator{{">=", p.floor()}, {"<", upper(p.major, p.minor+1, 0)}}
+	}
+}
+
+// anyVersion returns a comparator set matching every release
+func anyVersion() []comparator {
+	return []comparator{{">=", &Version{}}}
+}
+
+// upper returns an exclusive upper bound that also excludes prereleases of the bound
+func upper(major, minor, patch int) *Version {
+	return &Version{Major: major, Minor: minor, Patch: patch, Prerelease: []string{"0"}}
+}
+
+// partial is a possibly incomplete version; missing or wildcard parts are -1
+type partial struct {
+	major, minor, patch int
+	prerelease          []string
+}
+
+// parsePartial parses versions like "1", "1.2", "1.2.x", "*" or "1.2.3-beta"
+func parsePartial(s string) (*partial, error) {
+	s = strings.TrimPrefix(s, "v")
+	p := &partial{major: -1, minor: -1, patch: -1}
+	if s == "" {
+		return p, nil
+	}
+
+	if idx := strings.Index(s, "+"); idx >= 0 {
+		s = s[:idx]
+	}
+	if idx := strings.Index(s, "-"); idx >= 0 {
+		p.prerelease = strings.Split(s[idx+1:], ".")
+		s = s[:idx]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid version %q", s)
+	}
+	targets := []*int{&p.major, &p.minor, &p.patch}
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", s)
+		}
+		*targets[i] = n
+	}
+
+	return p, nil
+}
+
+// anyMajor reports whether the partial matches every version
+func (p *partial) anyMajor() bool {
+	return p.major < 0
+}
+
+// complete reports whether all three parts are given
+func (p *partial) complete() bool {
+	return p.major >= 0 && p.minor >= 0 && p.patch >= 0
+}
+
+// floor returns the lowest version the partial describes
+func (p *partial) floor() *Version {
+	v := &Version{Major: max(p.major, 0), Minor: max(p.minor, 0), Patch: max(p.patch, 0)}
+	if p.complete() {
+		v.Prerelease = p.prerelease
+	}
+	return v
+}
+
+// ceiling returns the exclusive upper bound of an incomplete partial
+func (p *partial) ceiling() *Version {
+	if p.minor < 0 {
+		return upper(p.major+1, 0, 0)
+	}
+	return upper(p.major, p.minor+1, 0)
+}
+
+// release returns a copy of v without its prerelease
+func (v *Version) release() *Version {
+	return &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+}
@@ -0,0 +1,154 @@
+// Package semver implements semantic versions and the npm range syntax
+// (^, ~, x-ranges, hyphen ranges and || sets) used by package.json files.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+	Build      string
+}
+
+// Parse parses a full semantic version such as "1.2.3", "v1.2.3-beta.1" or "1.2.3+build"
+func Parse(s string) (*Version, error) {
+	raw := strings.TrimSpace(s)
+	raw = strings.TrimPrefix(strings.TrimPrefix(raw, "="), "v")
+
+	v := &Version{}
+	if idx := strings.Index(raw, "+"); idx >= 0 {
+		v.Build = raw[idx+1:]
+		raw = raw[:idx]
+	}
+	if idx := strings.Index(raw, "-"); idx >= 0 {
+		if idx == len(raw)-1 {
+			return nil, fmt.Errorf("invalid version %q: empty prerelease", s)
+		}
+		v.Prerelease = strings.Split(raw[idx+1:], ".")
+		raw = raw[:idx]
+	}
+
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid version %q", s)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", s)
+		}
+		numbers[i] = n
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+
+	return v, nil
+}
+
+// Valid reports whether s is a full semantic version
+func Valid(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
+
+// String returns the canonical form of the version, without build metadata
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	return s
+}
+
+// IsPrerelease reports whether the version has a prerelease tag
+func (v *Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// Compare returns -1, 0 or 1 depending on whether v is lower than, equal to
+// or greater than o. Build metadata is ignored.
+func (v *Version) Compare(o *Version) int {
+	if c := compareInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	// A version without prerelease has higher precedence
+	switch {
+	case len(v.Prerelease) == 0 && len(o.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(o.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(o.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], o.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(v.Prerelease), len(o.Prerelease))
+}
+
+// Compare compares two version strings. Invalid versions sort before valid ones.
+func Compare(a, b string) int {
+	va, errA := Parse(a)
+	vb, errB := Parse(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	default:
+		return va.Compare(vb)
+	}
+}
+
+// sameTuple reports whether two versions share major, minor and patch
+func (v *Version) sameTuple(o *Version) bool {
+	return v.Major == o.Major && v.Minor == o.Minor && v.Patch == o.Patch
+}
+
+// compareInt compares two integers
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareIdentifier compares prerelease identifiers; numeric identifiers
+// always have lower precedence than alphanumeric ones
+func compareIdentifier(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInt(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
@@ -0,0 +1,149 @@
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"1.2.3", "1.2.3", false},
+		{"v1.2.3", "1.2.3", false},
+		{"=1.2.3", "1.2.3", false},
+		{"1.2.3-beta.1", "1.2.3-beta.1", false},
+		{"1.2.3+build.5", "1.2.3", false},
+		{"1.2", "", true},
+		{"1.2.x", "", true},
+		{"latest", "", true},
+		{"1.2.3-", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err == nil && v.String() != tt.expected {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, v.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "2.0.0-rc.1", 1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0+a", "1.0.0+b", 0},
+		{"invalid", "1.0.0", -1},
+	}
+
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.expected {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		version  string
+		rng      string
+		expected bool
+	}{
+		// Caret ranges
+		{"1.9.9", "^1.2.3", true},
+		{"2.0.0", "^1.2.3", false},
+		{"1.2.2", "^1.2.3", false},
+		{"0.2.9", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"0.0.3", "^0.0.3", true},
+		{"0.0.4", "^0.0.3", false},
+		{"0.9.0", "^0.x", true},
+		{"1.5.0", "^1.x", true},
+
+		// Tilde ranges
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"1.9.0", "~1", true},
+
+		// X-ranges and wildcards
+		{"1.2.7", "1.2.x", true},
+		{"1.3.0", "1.2", false},
+		{"5.0.0", "*", true},
+		{"5.0.0", "", true},
+		{"1.2.3", "1.2.3", true},
+		{"1.2.4", "=1.2.3", false},
+
+		// Comparators, hyphen ranges and unions
+		{"1.5.0", ">=1.2.0 <2.0.0", true},
+		{"2.0.0", ">= 1.2.0 < 2.0.0", false},
+		{"2.0.0", ">1", true},
+		{"1.9.9", ">1", false},
+		{"1.2.9", "<=1.2", true},
+		{"1.3.0", "<=1.2", false},
+		{"2.3.9", "1.2.3 - 2.3", true},
+		{"2.4.0", "1.2.3 - 2.3", false},
+		{"3.1.0", "1.x || >=3.0.0", true},
+		{"2.1.0", "1.x || >=3.0.0", false},
+
+		// Prereleases only match comparators on the same tuple
+		{"1.2.4-beta.1", "^1.2.3", false},
+		{"1.2.3-beta.2", "^1.2.3-beta.1", true},
+		{"1.3.0-beta.1", "^1.2.3-beta.1", false},
+		{"2.0.0-rc.1", "<2.0.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := Satisfies(tt.version, tt.rng); got != tt.expected {
+			t.Errorf("Satisfies(%q, %q) = %v, want %v", tt.version, tt.rng, got, tt.expected)
+		}
+	}
+}
+
+func TestMaxSatisfying(t *testing.T) {
+	versions := []string{"1.0.0", "1.2.0", "1.10.1", "2.0.0-rc.1", "2.0.0", "2.1.0", "not-a-version"}
+
+	tests := []struct {
+		rng      string
+		expected string
+	}{
+		{"^1.0.0", "1.10.1"},
+		{"~1.2.0", "1.2.0"},
+		{"*", "2.1.0"},
+		{"<2", "1.10.1"},
+		{"^3.0.0", ""},
+		{"invalid range ||| x.y.z", ""},
+	}
+
+	for _, tt := range tests {
+		if got := MaxSatisfying(versions, tt.rng); got != tt.expected {
+			t.Errorf("MaxSatisfying(%q) = %q, want %q", tt.rng, got, tt.expected)
+		}
+	}
+}
+
+func TestContainsPrerelease(t *testing.T) {
+	r, err := ParseRange(">=1.0.0 <1.4.2")
+	if err != nil {
+		t.Fatalf("ParseRange() error = %v", err)
+	}
+
+	v, _ := Parse("1.3.0-beta.1")
+	if r.Contains(v) {
+		t.Error("Contains() should not match a prerelease outside the comparator tuples")
+	}
+	if !r.ContainsPrerelease(v) {
+		t.Error("ContainsPrerelease() should match a prerelease inside the range")
+	}
+}
@@ -210,7 +210,8 @@ func TestIntegration_ProjectService(t *testing.T) {
 		t.Errorf("Expected project name 'test-project', got '%s'", project.Name)
 	}
 	
-	// Test project analysis
+	// Test project analysis without querying the registry
+	projectService.SetOffline(true)
 	analysis, err := projectService.AnalyzeProject(ctx, tempDir)
 	if err != nil {
 		t.Errorf("Failed to analyze project: %v", err)
@@ -409,13 +409,20 @@ class NPMConsoleApp {
             return;
         }
 
+        const mode = document.getElementById('searchMode').value;
+
         try {
             this.showLoading();
-            const packages = await this.apiCall(`/packages/search?q=${encodeURIComponent(query)}`);
+            const packages = await this.apiCall(`/packages/search?q=${encodeURIComponent(query)}&mode=${mode}`);
 
             const packageList = document.getElementById('packageList');
             packageList.innerHTML = '';
 
+            if (mode === 'registry') {
+                this.renderRegistryResults(packageList, packages);
+                return;
+            }
+
             if (packages.length === 0) {
                 packageList.innerHTML = '<p class="text-gray-500 text-center py-8">未找到包</p>';
                 return;
@@ -432,6 +439,47 @@ class NPMConsoleApp {
         }
     }
 
+    renderRegistryResults(packageList, results) {
+        if (results.results.length === 0) {
+            packageList.innerHTML = '<p class="text-gray-500 text-center py-8">未找到包</p>';
+            return;
+        }
+
+        results.results.forEach(result => {
+            const div = document.createElement('div');
+            div.className = 'flex items-center justify-between p-4 border border-gray-200 rounded-lg';
+            div.innerHTML = `
+                <div class="flex items-center space-x-4">
+                    <div class="flex-shrink-0">
+                        <i class="fas fa-cloud text-blue-500 text-xl"></i>
+                    </div>
+                    <div>
+                        <h4 class="text-sm font-medium text-gray-900">${result.name}</h4>
+                        <p class="text-sm text-gray-500">${result.description || '无描述'}</p>
+                    </div>
+                </div>
+                <div class="flex items-center space-x-4">
+                    <div class="text-right">
+                        <p class="text-sm font-medium text-gray-900">${result.version}</p>
+                        <p class="text-xs text-gray-500">评分 ${result.score.final.toFixed(2)}</p>
+                    </div>
+                    <button onclick="document.getElementById('packageInstall').value = '${result.name}'"
+                            class="bg-blue-600 hover:bg-blue-700 text-white px-3 py-1 rounded text-xs transition-colors">
+                        <i class="fas fa-download mr-1"></i> 选择
+                    </button>
+                </div>
+            `;
+            packageList.appendChild(div);
+        });
+
+        if (results.total > results.results.length) {
+            const moreDiv = document.createElement('div');
+            moreDiv.className = 'text-center py-4';
+            moreDiv.innerHTML = `<p class="text-gray-500">显示 ${results.total} 个结果中的 ${results.results.length} 个</p>`;
+            packageList.appendChild(moreDiv);
+        }
+    }
+
     async updateRegistry(manager) {
         const registryInput = document.getElementById(`registry-${manager}`);
         const registry = registryInput.value.trim();